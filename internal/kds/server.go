@@ -0,0 +1,412 @@
+// Package kds serves a kitchen display over HTTP: a page for a browser on
+// the local network listing the items sent to each station, updated live
+// through server-sent events, where the cook bumps items to "pronto".
+package kds
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"notinha/internal/pos"
+)
+
+//go:embed page.html
+var pageHTML []byte
+
+// Ticket kinds, as on the printed production tickets.
+const (
+	KindNew    = ""
+	KindAdd    = "ACRESCIMO"
+	KindCancel = "CANCELAMENTO"
+)
+
+// Item statuses.
+const (
+	StatusPendente = "pendente"
+	StatusPronto   = "pronto"
+)
+
+// Item is one line of a ticket on the display.
+type Item struct {
+	ID       int       `json:"id"`
+	Quantity int       `json:"quantity"`
+	Name     string    `json:"name"`
+	Details  []string  `json:"details,omitempty"` // fractions, options and notes
+	Status   string    `json:"status"`
+	ReadyAt  time.Time `json:"ready_at,omitempty"`
+
+	line pos.OrderItem
+}
+
+// Ticket is a batch of items sent to a station at once.
+type Ticket struct {
+	ID      int       `json:"id"`
+	Order   int       `json:"order"`
+	Label   string    `json:"label"` // "Mesa 5 - Ana (#12)"
+	Station string    `json:"station"`
+	Kind    string    `json:"kind,omitempty"`
+	Items   []Item    `json:"items"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+func (t *Ticket) done() bool {
+	for _, it := range t.Items {
+		if it.Status != StatusPronto {
+			return false
+		}
+	}
+	return true
+}
+
+// Ready reports items bumped to "pronto", for the POS to update the order
+// and tell the waiter.
+type Ready struct {
+	Order    int
+	Label    string
+	Station  string
+	Item     pos.OrderItem // as sent, with Quantity units ready
+	PrepTime time.Duration
+}
+
+// Snapshot is the state pushed to the display.
+type Snapshot struct {
+	Stations       []string `json:"stations"`
+	Tickets        []Ticket `json:"tickets"`
+	AvgPrepSeconds int      `json:"avg_prep_seconds"` // over the items bumped since start
+}
+
+type prepStats struct {
+	total time.Duration
+	count int
+}
+
+// Server keeps the open kitchen tickets and serves the display.
+type Server struct {
+	// OnReady is called, from an HTTP goroutine, for each item bumped to
+	// "pronto". Cancellations are only acknowledged and not reported.
+	OnReady func(Ready)
+
+	mu          sync.Mutex
+	tickets     []*Ticket
+	stations    map[string]bool
+	nextID      int
+	prep        map[string]prepStats // by station
+	subscribers map[chan struct{}]bool
+	srv         *http.Server
+	addr        string
+	now         func() time.Time
+}
+
+func NewServer() *Server {
+	return &Server{
+		stations:    map[string]bool{},
+		prep:        map[string]prepStats{},
+		subscribers: map[chan struct{}]bool{},
+		now:         time.Now,
+	}
+}
+
+// Send puts items sent to station on the display.
+func (s *Server) Send(order int, label, station, kind string, items []pos.OrderItem) {
+	if len(items) == 0 {
+		return
+	}
+	s.mu.Lock()
+	s.nextID++
+	t := &Ticket{ID: s.nextID, Order: order, Label: label, Station: station, Kind: kind, SentAt: s.now()}
+	for _, oi := range items {
+		s.nextID++
+		t.Items = append(t.Items, Item{
+			ID:       s.nextID,
+			Quantity: oi.Quantity,
+			Name:     oi.Item.Name,
+			Details:  itemDetails(oi),
+			Status:   StatusPendente,
+			line:     oi,
+		})
+	}
+	s.tickets = append(s.tickets, t)
+	s.stations[station] = true
+	s.mu.Unlock()
+	s.notify()
+}
+
+func itemDetails(oi pos.OrderItem) []string {
+	var details []string
+	for i, f := range oi.Flavors {
+		details = append(details, pos.FlavorFraction(oi.Flavors, i)+" "+f.Item.Name)
+	}
+	for _, m := range oi.Modifiers {
+		details = append(details, m.String())
+	}
+	if oi.Notes != "" {
+		details = append(details, "Obs: "+oi.Notes)
+	}
+	return details
+}
+
+// Snapshot returns the open tickets of station, or of every station when
+// it is empty, oldest first.
+func (s *Server) Snapshot(station string) Snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snap := Snapshot{Tickets: []Ticket{}}
+	for name := range s.stations {
+		snap.Stations = append(snap.Stations, name)
+	}
+	sort.Strings(snap.Stations)
+	var stats prepStats
+	for name, p := range s.prep {
+		if station == "" || name == station {
+			stats.total += p.total
+			stats.count += p.count
+		}
+	}
+	if stats.count > 0 {
+		snap.AvgPrepSeconds = int(stats.total.Seconds()) / stats.count
+	}
+	for _, t := range s.tickets {
+		if station != "" && t.Station != station {
+			continue
+		}
+		ticket := *t
+		ticket.Items = append([]Item(nil), t.Items...)
+		snap.Tickets = append(snap.Tickets, ticket)
+	}
+	return snap
+}
+
+// Bump marks item id as "pronto"; a ticket leaves the display when all
+// its items are done.
+func (s *Server) Bump(id int) error {
+	s.mu.Lock()
+	var ready *Ready
+	found := false
+	for ti, t := range s.tickets {
+		for i := range t.Items {
+			it := &t.Items[i]
+			if it.ID != id {
+				continue
+			}
+			found = true
+			if it.Status == StatusPronto {
+				break
+			}
+			it.Status, it.ReadyAt = StatusPronto, s.now()
+			if t.Kind != KindCancel {
+				prep := it.ReadyAt.Sub(t.SentAt)
+				stats := s.prep[t.Station]
+				stats.total += prep
+				stats.count++
+				s.prep[t.Station] = stats
+				ready = &Ready{Order: t.Order, Label: t.Label, Station: t.Station, Item: it.line, PrepTime: prep}
+			}
+			if t.done() {
+				s.tickets = append(s.tickets[:ti], s.tickets[ti+1:]...)
+			}
+			break
+		}
+		if found {
+			break
+		}
+	}
+	s.mu.Unlock()
+	if !found {
+		return fmt.Errorf("item %d nao esta na tela", id)
+	}
+	s.notify()
+	if ready != nil && s.OnReady != nil {
+		s.OnReady(*ready)
+	}
+	return nil
+}
+
+// BumpTicket marks every item of ticket id as "pronto".
+func (s *Server) BumpTicket(id int) error {
+	var items []int
+	s.mu.Lock()
+	for _, t := range s.tickets {
+		if t.ID == id {
+			for _, it := range t.Items {
+				if it.Status != StatusPronto {
+					items = append(items, it.ID)
+				}
+			}
+		}
+	}
+	s.mu.Unlock()
+	if len(items) == 0 {
+		return fmt.Errorf("comanda %d nao esta na tela", id)
+	}
+	for _, item := range items {
+		if err := s.Bump(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notify wakes every display to send a fresh snapshot.
+func (s *Server) notify() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default: // already has an update pending
+		}
+	}
+}
+
+func (s *Server) subscribe() chan struct{} {
+	ch := make(chan struct{}, 1)
+	s.mu.Lock()
+	s.subscribers[ch] = true
+	s.mu.Unlock()
+	return ch
+}
+
+func (s *Server) unsubscribe(ch chan struct{}) {
+	s.mu.Lock()
+	delete(s.subscribers, ch)
+	s.mu.Unlock()
+}
+
+// Handler routes the display page, its event stream and the bump API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(pageHTML)
+	})
+	mux.HandleFunc("GET /api/tickets", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, s.Snapshot(r.URL.Query().Get("station")))
+	})
+	mux.HandleFunc("GET /events", s.serveEvents)
+	mux.HandleFunc("POST /api/items/{id}/ready", func(w http.ResponseWriter, r *http.Request) {
+		s.serveBump(w, r, s.Bump)
+	})
+	mux.HandleFunc("POST /api/tickets/{id}/ready", func(w http.ResponseWriter, r *http.Request) {
+		s.serveBump(w, r, s.BumpTicket)
+	})
+	return mux
+}
+
+func (s *Server) serveBump(w http.ResponseWriter, r *http.Request, bump func(int) error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "id invalido", http.StatusBadRequest)
+		return
+	}
+	if err := bump(id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serveEvents streams a snapshot of the station's tickets on every change.
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming nao suportado", http.StatusInternalServerError)
+		return
+	}
+	station := r.URL.Query().Get("station")
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	ch := s.subscribe()
+	defer s.unsubscribe(ch)
+	for {
+		data, err := json.Marshal(s.Snapshot(station))
+		if err != nil {
+			return
+		}
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+			return
+		}
+		flusher.Flush()
+		select {
+		case <-ch:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("KDS: erro ao responder: %v", err)
+	}
+}
+
+// Start listens on addr (":8090") and serves the display in the
+// background.
+func (s *Server) Start(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("tela da cozinha em %s: %w", addr, err)
+	}
+	s.mu.Lock()
+	s.srv = &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	s.addr = ln.Addr().String()
+	srv := s.srv
+	s.mu.Unlock()
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("KDS: servidor parou: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Addr is the address the server listens on, once started.
+func (s *Server) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addr
+}
+
+// URLs lists the addresses a browser on the local network can open.
+func (s *Server) URLs() []string {
+	_, port, err := net.SplitHostPort(s.Addr())
+	if err != nil {
+		return nil
+	}
+	var urls []string
+	addrs, _ := net.InterfaceAddrs()
+	for _, a := range addrs {
+		ipnet, ok := a.(*net.IPNet)
+		if !ok || ipnet.IP.IsLoopback() || ipnet.IP.To4() == nil {
+			continue
+		}
+		urls = append(urls, "http://"+net.JoinHostPort(ipnet.IP.String(), port))
+	}
+	if len(urls) == 0 {
+		urls = append(urls, "http://"+net.JoinHostPort("localhost", port))
+	}
+	return urls
+}
+
+// Close stops the server; open displays are disconnected.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	srv := s.srv
+	s.srv = nil
+	s.mu.Unlock()
+	if srv == nil {
+		return nil
+	}
+	return srv.Close()
+}
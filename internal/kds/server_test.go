@@ -0,0 +1,162 @@
+package kds
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"notinha/internal/pos"
+)
+
+func testServer(t *testing.T) (*Server, *httptest.Server, *time.Time) {
+	t.Helper()
+	clock := time.Date(2024, 3, 8, 20, 0, 0, 0, time.Local)
+	s := NewServer()
+	s.now = func() time.Time { return clock }
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(ts.Close)
+	return s, ts, &clock
+}
+
+func getSnapshot(t *testing.T, url string) Snapshot {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+	var snap Snapshot
+	if err := json.NewDecoder(resp.Body).Decode(&snap); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return snap
+}
+
+func TestBumpReportsReadyItems(t *testing.T) {
+	s, ts, clock := testServer(t)
+	var got []Ready
+	s.OnReady = func(r Ready) { got = append(got, r) }
+
+	burger := pos.OrderItem{Item: pos.MenuItem{ID: 1, Name: "X-Burguer"}, Quantity: 2, Notes: "sem cebola"}
+	fries := pos.OrderItem{Item: pos.MenuItem{ID: 2, Name: "Batata Frita"}, Quantity: 1}
+	s.Send(12, "Mesa 5 (#12)", "cozinha", KindNew, []pos.OrderItem{burger, fries})
+	s.Send(13, "Balcao (#13)", "bar", KindNew, []pos.OrderItem{{Item: pos.MenuItem{ID: 3, Name: "Chopp"}, Quantity: 1}})
+
+	snap := getSnapshot(t, ts.URL+"/api/tickets?station=cozinha")
+	if len(snap.Tickets) != 1 || len(snap.Tickets[0].Items) != 2 {
+		t.Fatalf("cozinha tickets = %+v", snap.Tickets)
+	}
+	if len(snap.Stations) != 2 {
+		t.Errorf("stations = %v", snap.Stations)
+	}
+	item := snap.Tickets[0].Items[0]
+	if len(item.Details) != 1 || item.Details[0] != "Obs: sem cebola" {
+		t.Errorf("details = %v", item.Details)
+	}
+
+	*clock = clock.Add(8 * time.Minute)
+	resp, err := http.Post(ts.URL+"/api/items/"+strconv.Itoa(item.ID)+"/ready", "", nil)
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	if len(got) != 1 || got[0].Order != 12 || got[0].Item.Quantity != 2 || got[0].PrepTime != 8*time.Minute {
+		t.Fatalf("ready = %+v", got)
+	}
+	if got[0].Item.Notes != "sem cebola" {
+		t.Errorf("ready item = %+v", got[0].Item)
+	}
+
+	// The ticket leaves the display once every item is ready.
+	if err := s.BumpTicket(snap.Tickets[0].ID); err != nil {
+		t.Fatalf("bump ticket: %v", err)
+	}
+	snap = getSnapshot(t, ts.URL+"/api/tickets?station=cozinha")
+	if len(snap.Tickets) != 0 {
+		t.Errorf("tickets after bump = %+v", snap.Tickets)
+	}
+	if snap.AvgPrepSeconds != 8*60 {
+		t.Errorf("avg prep = %d", snap.AvgPrepSeconds)
+	}
+	if err := s.Bump(item.ID); err == nil {
+		t.Error("bumping a removed item should fail")
+	}
+}
+
+func TestCancellationIsNotReported(t *testing.T) {
+	s, _, _ := testServer(t)
+	s.OnReady = func(r Ready) { t.Errorf("unexpected ready %+v", r) }
+	s.Send(12, "Mesa 5 (#12)", "cozinha", KindCancel, []pos.OrderItem{{Item: pos.MenuItem{ID: 1, Name: "X-Burguer"}, Quantity: 1}})
+	if err := s.BumpTicket(s.Snapshot("").Tickets[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(s.Snapshot("").Tickets); n != 0 {
+		t.Errorf("tickets = %d", n)
+	}
+}
+
+func TestEventsStreamSnapshots(t *testing.T) {
+	s, ts, _ := testServer(t)
+	resp, err := http.Get(ts.URL + "/events?station=bar")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("content type = %q", ct)
+	}
+	events := make(chan Snapshot)
+	go func() {
+		sc := bufio.NewScanner(resp.Body)
+		for sc.Scan() {
+			data, ok := strings.CutPrefix(sc.Text(), "data: ")
+			if !ok {
+				continue
+			}
+			var snap Snapshot
+			json.Unmarshal([]byte(data), &snap)
+			events <- snap
+		}
+		close(events)
+	}()
+	next := func() Snapshot {
+		select {
+		case snap := <-events:
+			return snap
+		case <-time.After(5 * time.Second):
+			t.Fatal("no event")
+		}
+		return Snapshot{}
+	}
+
+	if snap := next(); len(snap.Tickets) != 0 {
+		t.Fatalf("initial tickets = %+v", snap.Tickets)
+	}
+	s.Send(13, "Balcao (#13)", "bar", KindAdd, []pos.OrderItem{{Item: pos.MenuItem{ID: 3, Name: "Chopp"}, Quantity: 2}})
+	snap := next()
+	if len(snap.Tickets) != 1 || snap.Tickets[0].Kind != KindAdd || snap.Tickets[0].Items[0].Name != "Chopp" {
+		t.Errorf("tickets = %+v", snap.Tickets)
+	}
+}
+
+func TestPageIsServed(t *testing.T) {
+	_, ts, _ := testServer(t)
+	resp, err := http.Get(ts.URL + "/")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+	var sb strings.Builder
+	bufio.NewReader(resp.Body).WriteTo(&sb)
+	if !strings.Contains(sb.String(), "EventSource") {
+		t.Error("page does not subscribe to events")
+	}
+}
@@ -0,0 +1,123 @@
+// Package pix builds static PIX BR Code payloads (EMV QR Code Merchant
+// Presented Mode, as specified by the Banco Central do Brasil) for
+// printing as a QR code on receipts.
+package pix
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Field length limits from the BR Code manual.
+const (
+	maxNameLen = 25
+	maxCityLen = 15
+	maxTxIDLen = 25
+)
+
+// Payload describes a static PIX charge.
+type Payload struct {
+	Key    string // PIX key: CPF/CNPJ, phone, e-mail or random key
+	Name   string // merchant name
+	City   string // merchant city
+	Amount int64  // centavos; zero lets the payer type the amount
+	TxID   string // reference shown in the payer's bank statement; "***" when empty
+}
+
+// Encode returns the BR Code string, including the trailing CRC16.
+func (p Payload) Encode() (string, error) {
+	key := strings.TrimSpace(p.Key)
+	if key == "" {
+		return "", fmt.Errorf("chave PIX nao configurada")
+	}
+	name := sanitize(p.Name, maxNameLen)
+	if name == "" {
+		return "", fmt.Errorf("nome do recebedor PIX vazio")
+	}
+	city := sanitize(p.City, maxCityLen)
+	if city == "" {
+		return "", fmt.Errorf("cidade do recebedor PIX vazia")
+	}
+
+	var b strings.Builder
+	b.WriteString(field("00", "01"))
+	b.WriteString(field("26", field("00", "br.gov.bcb.pix")+field("01", key)))
+	b.WriteString(field("52", "0000"))
+	b.WriteString(field("53", "986"))
+	if p.Amount > 0 {
+		b.WriteString(field("54", fmt.Sprintf("%d.%02d", p.Amount/100, p.Amount%100)))
+	}
+	b.WriteString(field("58", "BR"))
+	b.WriteString(field("59", name))
+	b.WriteString(field("60", city))
+	b.WriteString(field("62", field("05", txID(p.TxID))))
+
+	b.WriteString("6304")
+	payload := b.String()
+	return payload + fmt.Sprintf("%04X", CRC16(payload)), nil
+}
+
+// field encodes one EMV TLV field: two-digit ID, two-digit length, value.
+func field(id, value string) string {
+	return fmt.Sprintf("%s%02d%s", id, len(value), value)
+}
+
+// txID keeps only the characters allowed in the transaction ID.
+func txID(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if b.Len() == maxTxIDLen {
+			break
+		}
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() == 0 {
+		return "***"
+	}
+	return b.String()
+}
+
+// sanitize strips accents and non-printable ASCII, since many banking apps
+// reject names and cities outside plain ASCII, and truncates to max.
+func sanitize(s string, max int) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	plain, _, err := transform.String(t, s)
+	if err != nil {
+		plain = s
+	}
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(plain) {
+		if r >= 0x20 && r < 0x7F {
+			b.WriteRune(r)
+		}
+	}
+	out := b.String()
+	if len(out) > max {
+		out = strings.TrimSpace(out[:max])
+	}
+	return out
+}
+
+// CRC16 computes the CRC-16/CCITT-FALSE checksum (polynomial 0x1021,
+// initial value 0xFFFF) required in field 63.
+func CRC16(data string) uint16 {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(data); i++ {
+		crc ^= uint16(data[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,68 @@
+package pix
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCRC16(t *testing.T) {
+	if got := CRC16("123456789"); got != 0x29B1 {
+		t.Errorf("CRC16(123456789) = %04X, want 29B1", got)
+	}
+}
+
+func TestEncodeManualExample(t *testing.T) {
+	// Example from the BR Code manual (static QR, no amount).
+	p := Payload{
+		Key:  "123e4567-e12b-12d1-a456-426655440000",
+		Name: "Fulano de Tal",
+		City: "BRASILIA",
+	}
+	want := "00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-426655440000" +
+		"5204000053039865802BR5913Fulano de Tal6008BRASILIA62070503***63041D3D"
+
+	got, err := p.Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if got != want {
+		t.Errorf("Encode =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestEncodeWithAmountAndTxID(t *testing.T) {
+	p := Payload{
+		Key:    "pizzaria@exemplo.com",
+		Name:   "Pizzaria São João da Esquina Ltda",
+		City:   "São José dos Campos",
+		Amount: 12345,
+		TxID:   "PEDIDO-42",
+	}
+	got, err := p.Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	for _, part := range []string{
+		"5406123.45",
+		"5925Pizzaria Sao Joao da Esq",
+		"6015Sao Jose dos Ca",
+		"62120508PEDIDO42",
+	} {
+		if !strings.Contains(got, part) {
+			t.Errorf("payload %q missing %q", got, part)
+		}
+	}
+
+	body, crc := got[:len(got)-4], got[len(got)-4:]
+	if want := fmt.Sprintf("%04X", CRC16(body)); crc != want {
+		t.Errorf("CRC = %s, want %s", crc, want)
+	}
+}
+
+func TestEncodeRequiresKey(t *testing.T) {
+	if _, err := (Payload{Name: "X", City: "Y"}).Encode(); err == nil {
+		t.Error("Encode without key should fail")
+	}
+}
@@ -0,0 +1,205 @@
+package pos
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type CashMovementKind string
+
+const (
+	MovementSangria    CashMovementKind = "Sangria"    // cash taken out of the drawer
+	MovementSuprimento CashMovementKind = "Suprimento" // cash put into the drawer
+)
+
+// CashMovement is a sangria or suprimento recorded during a cash session.
+type CashMovement struct {
+	Kind       CashMovementKind `json:"kind"`
+	Amount     int64            `json:"amount"` // centavos, always positive
+	Reason     string           `json:"reason"`
+	Operator   string           `json:"operator"`
+	OperatorID int              `json:"operator_id,omitempty"`
+	Time       time.Time        `json:"time"`
+}
+
+// DrawerOpening records the drawer being opened outside of a sale.
+type DrawerOpening struct {
+	Operator   string    `json:"operator,omitempty"`
+	OperatorID int       `json:"operator_id,omitempty"`
+	Time       time.Time `json:"time"`
+}
+
+type CashSessionStatus string
+
+const (
+	SessionAberta  CashSessionStatus = "Aberto"
+	SessionFechada CashSessionStatus = "Fechado"
+)
+
+// CashSession is one opening-to-closing period of the cash register
+// ("caixa"). Orders finalized while it is open carry its ID.
+type CashSession struct {
+	ID             int                     `json:"id"`
+	Status         CashSessionStatus       `json:"status"`
+	OpenedAt       time.Time               `json:"opened_at"`
+	OpenedBy       string                  `json:"opened_by"`
+	OpenedByID     int                     `json:"opened_by_id,omitempty"`
+	OpeningFloat   int64                   `json:"opening_float"` // centavos (fundo de troco)
+	Movements      []CashMovement          `json:"movements,omitempty"`
+	DrawerOpenings []DrawerOpening         `json:"drawer_openings,omitempty"`
+	ClosedAt       time.Time               `json:"closed_at,omitempty"`
+	ClosedBy       string                  `json:"closed_by,omitempty"`
+	ClosedByID     int                     `json:"closed_by_id,omitempty"`
+	Expected       map[PaymentMethod]int64 `json:"expected,omitempty"` // computed at closing
+	Counted        map[PaymentMethod]int64 `json:"counted,omitempty"`  // informed at closing
+	ZNumber        int                     `json:"z_number,omitempty"` // reducao Z issued at closing
+}
+
+func NewCashSession(id int, openingFloat int64, operator string) *CashSession {
+	return &CashSession{
+		ID:           id,
+		Status:       SessionAberta,
+		OpenedAt:     time.Now(),
+		OpenedBy:     operator,
+		OpeningFloat: openingFloat,
+	}
+}
+
+func (s *CashSession) IsOpen() bool {
+	return s.Status == SessionAberta
+}
+
+// AddMovement records a sangria or suprimento made by operator. A sangria
+// cannot take out more cash than the drawer should hold given the orders
+// so far.
+func (s *CashSession) AddMovement(kind CashMovementKind, amount int64, reason string, operatorID int, operator string, orders []Order) error {
+	if !s.IsOpen() {
+		return fmt.Errorf("caixa %d esta fechado", s.ID)
+	}
+	if amount <= 0 {
+		return fmt.Errorf("valor da %s deve ser positivo", strings.ToLower(string(kind)))
+	}
+	if strings.TrimSpace(reason) == "" {
+		return fmt.Errorf("informe o motivo da %s", strings.ToLower(string(kind)))
+	}
+	switch kind {
+	case MovementSangria:
+		if cash := s.ExpectedCash(orders); amount > cash {
+			return fmt.Errorf("sangria de %s maior que o dinheiro em caixa (%s)", FormatBRL(amount), FormatBRL(cash))
+		}
+	case MovementSuprimento:
+	default:
+		return fmt.Errorf("movimento de caixa desconhecido: %s", kind)
+	}
+
+	s.Movements = append(s.Movements, CashMovement{
+		Kind:       kind,
+		Amount:     amount,
+		Reason:     strings.TrimSpace(reason),
+		Operator:   operator,
+		OperatorID: operatorID,
+		Time:       time.Now(),
+	})
+	return nil
+}
+
+// RecordDrawerOpening notes that operator opened the drawer.
+func (s *CashSession) RecordDrawerOpening(operatorID int, operator string) {
+	s.DrawerOpenings = append(s.DrawerOpenings, DrawerOpening{
+		Operator:   operator,
+		OperatorID: operatorID,
+		Time:       time.Now(),
+	})
+}
+
+// MovementTotal sums the movements of one kind.
+func (s *CashSession) MovementTotal(kind CashMovementKind) int64 {
+	var total int64
+	for _, m := range s.Movements {
+		if m.Kind == kind {
+			total += m.Amount
+		}
+	}
+	return total
+}
+
+// Sales sums the payments of the session's finalized orders per method,
+// less the refunds the session paid out for them. Orders from other
+// sessions are ignored.
+func (s *CashSession) Sales(orders []Order) map[PaymentMethod]int64 {
+	sales := make(map[PaymentMethod]int64)
+	for _, o := range orders {
+		if o.SessionID != s.ID || !o.WasPaid() {
+			continue
+		}
+		for _, p := range o.EffectivePayments() {
+			sales[p.Method] += p.Amount
+		}
+		for _, r := range o.Refunds {
+			if r.SessionID == s.ID {
+				sales[r.Method] -= r.Amount
+			}
+		}
+	}
+	return sales
+}
+
+// ExpectedCash is the cash the drawer should hold: opening float plus cash
+// sales and suprimentos, minus sangrias. Change handed back is already
+// netted out, since only the cash portion of each order is counted.
+func (s *CashSession) ExpectedCash(orders []Order) int64 {
+	return s.OpeningFloat + s.Sales(orders)[PaymentDinheiro] +
+		s.MovementTotal(MovementSuprimento) - s.MovementTotal(MovementSangria)
+}
+
+// PaysOutRefund reports whether refund r of o leaves the drawer of s as a
+// sangria: only cash does, and sales of s already net out their own
+// refunds. s is nil while the register is closed.
+func (s *CashSession) PaysOutRefund(o *Order, r Refund) bool {
+	return s != nil && s.IsOpen() && r.Method == PaymentDinheiro && r.Amount > 0 && o.SessionID != s.ID
+}
+
+// ExpectedAmounts is what should be counted per payment method at closing.
+func (s *CashSession) ExpectedAmounts(orders []Order) map[PaymentMethod]int64 {
+	expected := s.Sales(orders)
+	expected[PaymentDinheiro] = s.ExpectedCash(orders)
+	return expected
+}
+
+// Close ends the session with the amounts counted per payment method and
+// freezes the expected amounts computed from orders.
+func (s *CashSession) Close(counted map[PaymentMethod]int64, operator string, orders []Order) error {
+	if !s.IsOpen() {
+		return fmt.Errorf("caixa %d ja foi fechado", s.ID)
+	}
+	s.Expected = s.ExpectedAmounts(orders)
+	s.Counted = make(map[PaymentMethod]int64, len(counted))
+	for m, v := range counted {
+		s.Counted[m] = v
+	}
+	s.Status = SessionFechada
+	s.ClosedBy = operator
+	s.ClosedAt = time.Now()
+	return nil
+}
+
+// Difference is counted minus expected for one method: positive means
+// money over (sobra), negative money short (falta).
+func (s *CashSession) Difference(method PaymentMethod) int64 {
+	return s.Counted[method] - s.Expected[method]
+}
+
+// TotalDifference sums the differences over all methods.
+func (s *CashSession) TotalDifference() int64 {
+	var total int64
+	for _, m := range PaymentMethods() {
+		total += s.Difference(m)
+	}
+	return total
+}
+
+// PaymentMethods lists the payment methods in display order.
+func PaymentMethods() []PaymentMethod {
+	return []PaymentMethod{PaymentDinheiro, PaymentCartao, PaymentPix}
+}
@@ -0,0 +1,125 @@
+package pos
+
+import "testing"
+
+func cashTestOrders(sessionID int) []Order {
+	cash := NewOrder(1)
+	cash.AddItem(MenuItem{ID: 1, Name: "Pizza", Price: 5000}, 1, "")
+	cash.CashReceived = 10000
+	cash.Finalize(PaymentDinheiro)
+	cash.SessionID = sessionID
+
+	split := NewOrder(2)
+	split.AddItem(MenuItem{ID: 2, Name: "Refri", Price: 3000}, 1, "")
+	split.FinalizeSplit([]PaymentSplit{
+		{Method: PaymentDinheiro, Amount: 1000},
+		{Method: PaymentCartao, Amount: 2000},
+	})
+	split.SessionID = sessionID
+
+	other := NewOrder(3)
+	other.AddItem(MenuItem{ID: 1, Name: "Pizza", Price: 5000}, 1, "")
+	other.Finalize(PaymentPix)
+	other.SessionID = sessionID + 1
+
+	cancelled := NewOrder(4)
+	cancelled.AddItem(MenuItem{ID: 1, Name: "Pizza", Price: 5000}, 1, "")
+	cancelled.Cancel()
+	cancelled.SessionID = sessionID
+
+	return []Order{*cash, *split, *other, *cancelled}
+}
+
+func TestCashSessionExpectedAmounts(t *testing.T) {
+	s := NewCashSession(1, 20000, "Ana")
+	orders := cashTestOrders(1)
+
+	if err := s.AddMovement(MovementSuprimento, 5000, "troco extra", 1, "Ana", orders); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddMovement(MovementSangria, 30000, "deposito", 1, "Ana", orders); err != nil {
+		t.Fatal(err)
+	}
+	if m := s.Movements[1]; m.OperatorID != 1 || m.Operator != "Ana" {
+		t.Errorf("movement = %+v, want it credited to Ana", m)
+	}
+
+	// 200 float + 50 + 10 cash sales + 50 suprimento - 300 sangria
+	if got := s.ExpectedCash(orders); got != 1000 {
+		t.Errorf("ExpectedCash = %d, want 1000", got)
+	}
+	expected := s.ExpectedAmounts(orders)
+	if expected[PaymentCartao] != 2000 {
+		t.Errorf("expected card = %d, want 2000", expected[PaymentCartao])
+	}
+	if expected[PaymentPix] != 0 {
+		t.Errorf("orders from another session should be ignored, got pix %d", expected[PaymentPix])
+	}
+}
+
+func TestCashSessionRejectsInvalidMovements(t *testing.T) {
+	s := NewCashSession(1, 10000, "Ana")
+	orders := cashTestOrders(1)
+
+	if err := s.AddMovement(MovementSangria, 100000, "deposito", 1, "Ana", orders); err == nil {
+		t.Error("sangria above the drawer cash should fail")
+	}
+	if err := s.AddMovement(MovementSuprimento, 1000, "  ", 1, "Ana", orders); err == nil {
+		t.Error("movement without reason should fail")
+	}
+	if err := s.AddMovement(MovementSuprimento, 0, "troco", 1, "Ana", orders); err == nil {
+		t.Error("movement with zero amount should fail")
+	}
+	if len(s.Movements) != 0 {
+		t.Errorf("rejected movements should not be recorded, got %d", len(s.Movements))
+	}
+}
+
+func TestCashSessionClose(t *testing.T) {
+	s := NewCashSession(1, 10000, "Ana")
+	orders := cashTestOrders(1)
+
+	counted := map[PaymentMethod]int64{
+		PaymentDinheiro: 15500, // 500 short of 100 float + 60 cash sales
+		PaymentCartao:   2000,
+	}
+	if err := s.Close(counted, "Bruno", orders); err != nil {
+		t.Fatal(err)
+	}
+	if s.IsOpen() || s.ClosedAt.IsZero() || s.ClosedBy != "Bruno" {
+		t.Errorf("session not closed properly: %+v", s)
+	}
+	if d := s.Difference(PaymentDinheiro); d != -500 {
+		t.Errorf("cash difference = %d, want -500", d)
+	}
+	if d := s.TotalDifference(); d != -500 {
+		t.Errorf("total difference = %d, want -500", d)
+	}
+	if err := s.Close(counted, "Bruno", orders); err == nil {
+		t.Error("closing twice should fail")
+	}
+	if err := s.AddMovement(MovementSuprimento, 100, "troco", 1, "Ana", orders); err == nil {
+		t.Error("movement on a closed session should fail")
+	}
+}
+
+func TestPaysOutRefund(t *testing.T) {
+	old := &Order{Number: 1, SessionID: 1}
+	cash := Refund{Method: PaymentDinheiro, Amount: 1000}
+	pix := Refund{Method: PaymentPix, Amount: 1000}
+
+	var closed *CashSession
+	if closed.PaysOutRefund(old, pix) || closed.PaysOutRefund(old, cash) {
+		t.Error("nothing is paid out while the register is closed")
+	}
+	s := NewCashSession(2, 0, "Ana")
+	if !s.PaysOutRefund(old, cash) {
+		t.Error("cash for a sale of an earlier session should be a sangria")
+	}
+	if s.PaysOutRefund(old, pix) {
+		t.Error("a pix refund takes nothing from the drawer")
+	}
+	if s.PaysOutRefund(&Order{Number: 2, SessionID: 2}, cash) {
+		t.Error("a sale of the open session already nets out its refunds")
+	}
+}
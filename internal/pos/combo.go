@@ -0,0 +1,307 @@
+package pos
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ComboSlot is one part of a combo: Quantity units of any of the listed
+// items, or of any item whose category matches one of Categories
+// (path.Match patterns, as in printer routes).
+type ComboSlot struct {
+	ItemIDs    []int    `json:"item_ids,omitempty"`
+	Categories []string `json:"categories,omitempty"`
+	Quantity   int      `json:"quantity"`
+}
+
+// Matches reports whether item can fill the slot.
+func (s ComboSlot) Matches(item MenuItem) bool {
+	for _, id := range s.ItemIDs {
+		if id == item.ID && id != 0 {
+			return true
+		}
+	}
+	for _, pattern := range s.Categories {
+		if ok, _ := path.Match(pattern, item.Category); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Combo is a promotional bundle sold for a fixed price, like "Pizza +
+// Refri 2L" or "Balde com 5 long necks".
+type Combo struct {
+	Name  string      `json:"name"`
+	Price int64       `json:"price"` // centavos
+	Slots []ComboSlot `json:"slots"`
+}
+
+// ComboCategory is the category of combo lines in an order.
+const ComboCategory = "Combos"
+
+// IsCombo reports whether the line is a combo made of Components.
+func (oi OrderItem) IsCombo() bool {
+	return len(oi.Components) > 0
+}
+
+// ListPrice is what the line would cost without combos: the components
+// at their own prices for a combo, the line total otherwise.
+func (oi OrderItem) ListPrice() int64 {
+	if !oi.IsCombo() {
+		return oi.Total()
+	}
+	var sum int64
+	for _, c := range oi.Components {
+		sum += c.Total()
+	}
+	return sum * int64(oi.Quantity)
+}
+
+// ExpandCombos replaces each combo line by its components, multiplied by
+// the number of combos, for production tickets.
+func ExpandCombos(items []OrderItem) []OrderItem {
+	var expanded []OrderItem
+	for _, oi := range items {
+		if !oi.IsCombo() {
+			expanded = append(expanded, oi)
+			continue
+		}
+		for _, c := range oi.Components {
+			c.Quantity *= oi.Quantity
+			expanded = append(expanded, c)
+		}
+	}
+	return expanded
+}
+
+// ComboSuggestion is a combo the order's items could be bundled into.
+type ComboSuggestion struct {
+	Combo   Combo
+	Savings int64 // centavos saved over the items' own prices
+}
+
+// SuggestCombos lists the combos that can be formed from the order's
+// loose items and are cheaper than them, best savings first.
+func (o *Order) SuggestCombos(combos []Combo) []ComboSuggestion {
+	var suggestions []ComboSuggestion
+	for _, c := range combos {
+		line, _, ok := o.matchCombo(c)
+		if !ok {
+			continue
+		}
+		if savings := line.ListPrice() - line.Total(); savings > 0 {
+			suggestions = append(suggestions, ComboSuggestion{Combo: c, Savings: savings})
+		}
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Savings > suggestions[j].Savings
+	})
+	return suggestions
+}
+
+// matchCombo fills the combo's slots from the loose lines, in order. It
+// returns the combo line and how many units it takes from each line.
+func (o *Order) matchCombo(c Combo) (OrderItem, []int, bool) {
+	if len(c.Slots) == 0 {
+		return OrderItem{}, nil, false
+	}
+	taken := make([]int, len(o.Items))
+	line := OrderItem{
+		Item:     MenuItem{Name: c.Name, Price: c.Price, Category: ComboCategory, Active: true},
+		Quantity: 1,
+	}
+	for _, slot := range c.Slots {
+		need := slot.Quantity
+		for i, oi := range o.Items {
+			if need == 0 {
+				break
+			}
+			free := oi.Quantity - taken[i]
+			if oi.IsCombo() || free <= 0 || !slot.Matches(oi.Item) {
+				continue
+			}
+			// Ready and sent units go into the combo first.
+			n := min(need, free)
+			part := oi
+			part.Quantity = n
+			part.Sent = min(n, max(oi.Sent-taken[i], 0))
+			part.Ready = min(n, max(oi.Ready-taken[i], 0))
+			taken[i] += n
+			need -= n
+			line.addComponent(part)
+		}
+		if need > 0 {
+			return OrderItem{}, nil, false
+		}
+	}
+	return line, taken, true
+}
+
+func (oi *OrderItem) addComponent(part OrderItem) {
+	for i, c := range oi.Components {
+		if c.sameLine(part) {
+			oi.Components[i].Quantity += part.Quantity
+			oi.Components[i].Sent += part.Sent
+			oi.Components[i].Ready += part.Ready
+			return
+		}
+	}
+	oi.Components = append(oi.Components, part)
+}
+
+// ApplyCombo bundles the order's loose items into the combo, taking them
+// out of their lines.
+func (o *Order) ApplyCombo(c Combo) error {
+	line, taken, ok := o.matchCombo(c)
+	if !ok {
+		return fmt.Errorf("pedido #%d nao tem os itens do combo %s", o.Number, c.Name)
+	}
+	items := o.Items[:0]
+	for i, oi := range o.Items {
+		oi.Quantity -= taken[i]
+		oi.Sent = max(oi.Sent-taken[i], 0)
+		oi.Ready = max(oi.Ready-taken[i], 0)
+		if oi.Quantity > 0 {
+			items = append(items, oi)
+		}
+	}
+	o.Items = items
+	o.addLine(line)
+	return nil
+}
+
+// BreakCombo undoes combo line index, returning its components to the
+// order as loose items at their own prices.
+func (o *Order) BreakCombo(index int) error {
+	if !o.isValidItemIndex(index) || !o.Items[index].IsCombo() {
+		return fmt.Errorf("item %d nao e um combo", index+1)
+	}
+	// The components stay in the kitchen: no cancellation.
+	combo := o.Items[index]
+	o.Items = append(o.Items[:index], o.Items[index+1:]...)
+	for _, c := range combo.Components {
+		c.Quantity *= combo.Quantity
+		o.addLine(c)
+	}
+	return nil
+}
+
+func sameComponents(a, b []OrderItem) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Quantity != b[i].Quantity || !a[i].sameLine(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseCombo reads the one-line form used by the menu editor:
+//
+//	Pizza + Refri = 55,00: Pizzas *; Coca-Cola 2L | Guarana 2L
+//	Balde Long Neck = 45,00: 5x Cervejas - Long Neck
+//
+// Each slot lists alternatives separated by "|": a menu item name or a
+// category pattern. "5x" asks for several units.
+func (m *Menu) ParseCombo(line string) (Combo, error) {
+	head, body, ok := strings.Cut(line, ":")
+	if !ok {
+		return Combo{}, fmt.Errorf("combo sem itens: %q", line)
+	}
+	name, priceText, ok := strings.Cut(head, "=")
+	if !ok {
+		return Combo{}, fmt.Errorf("combo sem preco: %q", line)
+	}
+	c := Combo{Name: strings.TrimSpace(name)}
+	if c.Name == "" {
+		return Combo{}, fmt.Errorf("combo sem nome: %q", line)
+	}
+	price, err := parseSignedPrice(strings.TrimSpace(priceText))
+	if err != nil || price <= 0 {
+		return Combo{}, fmt.Errorf("combo %s: preco invalido %q", c.Name, strings.TrimSpace(priceText))
+	}
+	c.Price = price
+
+	for _, part := range strings.Split(body, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		slot := ComboSlot{Quantity: 1}
+		if qty, rest, ok := strings.Cut(part, "x "); ok {
+			if n, err := strconv.Atoi(strings.TrimSpace(qty)); err == nil {
+				if n <= 0 {
+					return Combo{}, fmt.Errorf("combo %s: quantidade invalida em %q", c.Name, part)
+				}
+				slot.Quantity, part = n, strings.TrimSpace(rest)
+			}
+		}
+		for _, alt := range strings.Split(part, "|") {
+			alt = strings.TrimSpace(alt)
+			if item, ok := m.itemByName(alt); ok {
+				slot.ItemIDs = append(slot.ItemIDs, item.ID)
+			} else if m.hasCategory(alt) {
+				slot.Categories = append(slot.Categories, alt)
+			} else {
+				return Combo{}, fmt.Errorf("combo %s: item ou categoria desconhecido %q", c.Name, alt)
+			}
+		}
+		c.Slots = append(c.Slots, slot)
+	}
+	if len(c.Slots) == 0 {
+		return Combo{}, fmt.Errorf("combo %s sem itens", c.Name)
+	}
+	return c, nil
+}
+
+// FormatCombo is the inverse of ParseCombo.
+func (m *Menu) FormatCombo(c Combo) string {
+	parts := make([]string, len(c.Slots))
+	for i, slot := range c.Slots {
+		var alts []string
+		for _, id := range slot.ItemIDs {
+			if item, ok := m.itemByID(id); ok {
+				alts = append(alts, item.Name)
+			}
+		}
+		alts = append(alts, slot.Categories...)
+		parts[i] = strings.Join(alts, " | ")
+		if slot.Quantity > 1 {
+			parts[i] = fmt.Sprintf("%dx %s", slot.Quantity, parts[i])
+		}
+	}
+	return fmt.Sprintf("%s = %d,%02d: %s", c.Name, c.Price/100, c.Price%100, strings.Join(parts, "; "))
+}
+
+func (m *Menu) itemByName(name string) (MenuItem, bool) {
+	for _, item := range m.Items {
+		if item.Active && strings.EqualFold(item.Name, name) {
+			return item, true
+		}
+	}
+	return MenuItem{}, false
+}
+
+func (m *Menu) itemByID(id int) (MenuItem, bool) {
+	for _, item := range m.Items {
+		if item.ID == id {
+			return item, true
+		}
+	}
+	return MenuItem{}, false
+}
+
+func (m *Menu) hasCategory(pattern string) bool {
+	for _, category := range m.Categories() {
+		if ok, _ := path.Match(pattern, category); ok {
+			return true
+		}
+	}
+	return false
+}
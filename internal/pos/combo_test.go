@@ -0,0 +1,132 @@
+package pos
+
+import "testing"
+
+func comboMenu() *Menu {
+	m := NewMenu()
+	m.AddItem(MenuItem{Name: "Calabresa", Price: 4500, Category: "Pizzas Tradicionais"})
+	m.AddItem(MenuItem{Name: "Coca-Cola 2L", Price: 1400, Category: "Refrigerantes"})
+	m.AddItem(MenuItem{Name: "Guarana 2L", Price: 1200, Category: "Refrigerantes"})
+	m.AddItem(MenuItem{Name: "Heineken LN", Price: 1100, Category: "Cervejas - Long Neck"})
+	m.AddItem(MenuItem{Name: "Stella LN", Price: 1000, Category: "Cervejas - Long Neck"})
+	return m
+}
+
+func TestParseCombo(t *testing.T) {
+	m := comboMenu()
+	c, err := m.ParseCombo("Pizza + Refri = 52,00: Pizzas *; Coca-Cola 2L | Guarana 2L")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Name != "Pizza + Refri" || c.Price != 5200 || len(c.Slots) != 2 {
+		t.Fatalf("combo = %+v", c)
+	}
+	if len(c.Slots[0].Categories) != 1 || len(c.Slots[1].ItemIDs) != 2 {
+		t.Errorf("slots = %+v", c.Slots)
+	}
+	if got := m.FormatCombo(c); got != "Pizza + Refri = 52,00: Pizzas *; Coca-Cola 2L | Guarana 2L" {
+		t.Errorf("FormatCombo = %q", got)
+	}
+
+	balde, err := m.ParseCombo("Balde = 45,00: 5x Cervejas - Long Neck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balde.Slots[0].Quantity != 5 {
+		t.Errorf("quantity = %d, want 5", balde.Slots[0].Quantity)
+	}
+
+	for _, line := range []string{
+		"Sem preco: Calabresa",
+		"Preco ruim = abc: Calabresa",
+		"Desconhecido = 10,00: Feijoada",
+		"Vazio = 10,00:",
+	} {
+		if _, err := m.ParseCombo(line); err == nil {
+			t.Errorf("ParseCombo(%q) should fail", line)
+		}
+	}
+}
+
+func TestApplyAndBreakCombo(t *testing.T) {
+	m := comboMenu()
+	combo, _ := m.ParseCombo("Pizza + Refri = 52,00: Pizzas *; Coca-Cola 2L | Guarana 2L")
+	balde, _ := m.ParseCombo("Balde = 45,00: 5x Cervejas - Long Neck")
+
+	o := NewOrder(1)
+	o.AddItemWithModifiers(m.Items[0], 1, "", []Modifier{{Group: "Borda", Option: "Catupiry", Price: 800}})
+	if got := o.SuggestCombos([]Combo{combo, balde}); len(got) != 0 {
+		t.Fatalf("suggestions = %+v, want none without a drink", got)
+	}
+	o.AddItem(m.Items[1], 2, "")
+	o.AddItem(m.Items[3], 3, "")
+	o.AddItem(m.Items[4], 2, "")
+
+	got := o.SuggestCombos([]Combo{combo, balde})
+	if len(got) != 2 || got[0].Combo.Name != "Balde" || got[0].Savings != 5300-4500 || got[1].Savings != 5900-5200 {
+		t.Fatalf("suggestions = %+v", got)
+	}
+
+	before := o.Subtotal()
+	if err := o.ApplyCombo(combo); err != nil {
+		t.Fatal(err)
+	}
+	if len(o.Items) != 4 || !o.Items[3].IsCombo() {
+		t.Fatalf("items = %+v, want pizza taken and one cola left", o.Items)
+	}
+	if o.Items[0].Item.Name != "Coca-Cola 2L" || o.Items[0].Quantity != 1 {
+		t.Errorf("left over = %+v", o.Items[0])
+	}
+	line := o.Items[3]
+	if line.Total() != 5200+800 {
+		t.Errorf("combo total = %d, want the combo price plus the border", line.Total())
+	}
+	if o.Subtotal() != before-700 {
+		t.Errorf("Subtotal = %d, want %d", o.Subtotal(), before-700)
+	}
+
+	if err := o.ApplyCombo(combo); err == nil {
+		t.Error("a second combo has no pizza left")
+	}
+	if err := o.BreakCombo(0); err == nil {
+		t.Error("BreakCombo should reject a plain line")
+	}
+	if err := o.BreakCombo(3); err != nil {
+		t.Fatal(err)
+	}
+	if o.Subtotal() != before || len(o.Items) != 4 || o.Items[0].Quantity != 2 {
+		t.Errorf("after break: subtotal %d, items %+v", o.Subtotal(), o.Items)
+	}
+}
+
+func TestItemSalesSplitsCombos(t *testing.T) {
+	m := comboMenu()
+	combo, _ := m.ParseCombo("Pizza + Refri = 52,00: Pizzas *; Coca-Cola 2L")
+
+	o := NewOrder(1)
+	o.AddItem(m.Items[0], 2, "")
+	o.AddItem(m.Items[1], 3, "")
+	if err := o.ApplyCombo(combo); err != nil {
+		t.Fatal(err)
+	}
+	o.Finalize(PaymentPix)
+
+	s := ComputeDaySummary("2026-03-14", []Order{*o})
+	want := map[string]ItemSales{
+		"Calabresa":    {Name: "Calabresa", Quantity: 2, Revenue: 4500 + 3967},
+		"Coca-Cola 2L": {Name: "Coca-Cola 2L", Quantity: 3, Revenue: 2*1400 + 1233},
+	}
+	if len(s.Items) != 2 {
+		t.Fatalf("items = %+v", s.Items)
+	}
+	var revenue int64
+	for _, is := range s.Items {
+		if is != want[is.Name] {
+			t.Errorf("%s = %+v, want %+v", is.Name, is, want[is.Name])
+		}
+		revenue += is.Revenue
+	}
+	if revenue != o.Subtotal() {
+		t.Errorf("item revenue = %d, want the subtotal %d", revenue, o.Subtotal())
+	}
+}
@@ -0,0 +1,150 @@
+package pos
+
+// Kitchen state: each line records how many units were already sent to
+// the production printers (OrderItem.Sent), so items added later go out
+// as an addition and sent items taken off go out as a cancellation.
+
+// PendingKitchenItems lists what the kitchen has not received: new lines
+// and units added to sent lines. Combos are split into their components.
+func (o *Order) PendingKitchenItems() []OrderItem {
+	var pending []OrderItem
+	for _, oi := range o.Items {
+		if !oi.IsCombo() {
+			if n := oi.Quantity - oi.Sent; n > 0 {
+				pending = append(pending, kitchenPart(oi, n))
+			}
+			continue
+		}
+		for _, c := range oi.Components {
+			if n := c.Quantity*oi.Quantity - c.Sent; n > 0 {
+				pending = append(pending, kitchenPart(c, n))
+			}
+		}
+	}
+	return pending
+}
+
+// MarkSentToKitchen records every unit of the order as sent.
+func (o *Order) MarkSentToKitchen() {
+	for i := range o.Items {
+		oi := &o.Items[i]
+		oi.Sent = oi.Quantity
+		for j := range oi.Components {
+			oi.Components[j].Sent = oi.Components[j].Quantity * oi.Quantity
+		}
+	}
+}
+
+// SentToKitchen reports whether any item already went to the kitchen.
+func (o *Order) SentToKitchen() bool {
+	for _, oi := range o.Items {
+		if oi.Sent > 0 {
+			return true
+		}
+		for _, c := range oi.Components {
+			if c.Sent > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TakeKitchenCancellations returns the sent units taken off the order
+// since the last call, for a cancellation ticket, and forgets them.
+func (o *Order) TakeKitchenCancellations() []OrderItem {
+	cancels := o.KitchenCancels
+	o.KitchenCancels = nil
+	return cancels
+}
+
+// CancelKitchen cancels every sent unit, as when the order is discarded.
+func (o *Order) CancelKitchen() {
+	for i := range o.Items {
+		o.Items[i] = o.cancelSent(o.Items[i], 0)
+	}
+}
+
+// cancelSent records as cancelled the sent units of line beyond keep
+// units (combos: keep combos) and returns the line with Sent reduced.
+func (o *Order) cancelSent(line OrderItem, keep int) OrderItem {
+	if !line.IsCombo() {
+		if line.Sent > keep {
+			o.KitchenCancels = append(o.KitchenCancels, kitchenPart(line, line.Sent-keep))
+			line.Sent = keep
+			line.Ready = min(line.Ready, keep)
+		}
+		return line
+	}
+	components := make([]OrderItem, len(line.Components))
+	for i, c := range line.Components {
+		if limit := c.Quantity * keep; c.Sent > limit {
+			o.KitchenCancels = append(o.KitchenCancels, kitchenPart(c, c.Sent-limit))
+			c.Sent = limit
+			c.Ready = min(c.Ready, limit)
+		}
+		components[i] = c
+	}
+	line.Components = components
+	return line
+}
+
+// kitchenPart is n units of line, as printed on a production ticket.
+func kitchenPart(line OrderItem, n int) OrderItem {
+	line.Quantity = n
+	line.Sent = 0
+	line.Ready = 0
+	return line
+}
+
+// AwaitingKitchen lists the sent units the kitchen has not reported
+// ready. Combos are split into their components.
+func (o *Order) AwaitingKitchen() []OrderItem {
+	var waiting []OrderItem
+	for _, oi := range o.Items {
+		if !oi.IsCombo() {
+			if n := oi.Sent - oi.Ready; n > 0 {
+				waiting = append(waiting, kitchenPart(oi, n))
+			}
+			continue
+		}
+		for _, c := range oi.Components {
+			if n := c.Sent - c.Ready; n > 0 {
+				waiting = append(waiting, kitchenPart(c, n))
+			}
+		}
+	}
+	return waiting
+}
+
+// MarkReady records up to n units of item, as sent to the kitchen, as
+// ready. It returns how many units it found waiting.
+func (o *Order) MarkReady(item OrderItem, n int) int {
+	marked := 0
+	mark := func(line *OrderItem) {
+		if marked < n && sameKitchenItem(*line, item) {
+			k := min(n-marked, line.Sent-line.Ready)
+			if k > 0 {
+				line.Ready += k
+				marked += k
+			}
+		}
+	}
+	for i := range o.Items {
+		oi := &o.Items[i]
+		if !oi.IsCombo() {
+			mark(oi)
+			continue
+		}
+		for j := range oi.Components {
+			mark(&oi.Components[j])
+		}
+	}
+	return marked
+}
+
+// sameKitchenItem compares what the kitchen makes, ignoring the price.
+func sameKitchenItem(a, b OrderItem) bool {
+	return a.Item.ID == b.Item.ID && a.Item.Name == b.Item.Name && a.Notes == b.Notes &&
+		sameModifiers(a.Modifiers, b.Modifiers) && sameFlavors(a.Flavors, b.Flavors)
+}
@@ -0,0 +1,142 @@
+package pos
+
+import "testing"
+
+func TestPendingKitchenItems(t *testing.T) {
+	pizza := MenuItem{ID: 1, Name: "Calabresa", Price: 4500, Category: "Pizzas Tradicionais"}
+	cola := MenuItem{ID: 2, Name: "Coca-Cola 2L", Price: 1400, Category: "Refrigerantes"}
+
+	o := NewOrder(1)
+	o.AddItem(pizza, 1, "")
+	if o.SentToKitchen() || len(o.PendingKitchenItems()) != 1 {
+		t.Fatal("a new order should have everything pending")
+	}
+	o.MarkSentToKitchen()
+	if !o.SentToKitchen() || len(o.PendingKitchenItems()) != 0 {
+		t.Fatal("nothing should be pending after sending")
+	}
+
+	o.AddItem(pizza, 1, "")
+	o.AddItem(cola, 2, "")
+	pending := o.PendingKitchenItems()
+	if len(pending) != 2 || pending[0].Quantity != 1 || pending[1].Quantity != 2 {
+		t.Fatalf("pending = %+v, want 1 pizza and 2 colas", pending)
+	}
+	o.MarkSentToKitchen()
+
+	o.UpdateQuantity(0, 1)
+	o.UpdateQuantity(1, 3)
+	cancels := o.TakeKitchenCancellations()
+	if len(cancels) != 1 || cancels[0].Item.ID != 1 || cancels[0].Quantity != 1 {
+		t.Fatalf("cancels = %+v, want 1 pizza", cancels)
+	}
+	if pending := o.PendingKitchenItems(); len(pending) != 1 || pending[0].Quantity != 1 {
+		t.Errorf("pending = %+v, want the extra cola", pending)
+	}
+
+	o.RemoveItem(1)
+	cancels = o.TakeKitchenCancellations()
+	if len(cancels) != 1 || cancels[0].Quantity != 2 {
+		t.Errorf("cancels = %+v, want the 2 sent colas", cancels)
+	}
+	if len(o.TakeKitchenCancellations()) != 0 {
+		t.Error("cancellations should be taken only once")
+	}
+}
+
+func TestUpdateNotesResendsToKitchen(t *testing.T) {
+	pizza := MenuItem{ID: 1, Name: "Calabresa", Price: 4500, Category: "Pizzas Tradicionais"}
+	o := NewOrder(1)
+	o.AddItem(pizza, 2, "")
+	o.MarkSentToKitchen()
+	o.MarkReady(o.Items[0], 1)
+
+	o.UpdateNotes(0, "sem cebola")
+	cancels := o.TakeKitchenCancellations()
+	if len(cancels) != 1 || cancels[0].Quantity != 2 || cancels[0].Notes != "" {
+		t.Fatalf("cancels = %+v, want the 2 pizzas sent without notes", cancels)
+	}
+	pending := o.PendingKitchenItems()
+	if len(pending) != 1 || pending[0].Quantity != 2 || pending[0].Notes != "sem cebola" {
+		t.Fatalf("pending = %+v, want the 2 pizzas with the new notes", pending)
+	}
+	o.MarkSentToKitchen()
+	if n := o.MarkReady(pending[0], 2); n != 2 {
+		t.Errorf("MarkReady with the new notes = %d, want 2", n)
+	}
+
+	o.UpdateNotes(0, "sem cebola")
+	if len(o.TakeKitchenCancellations()) != 0 || len(o.PendingKitchenItems()) != 0 {
+		t.Error("unchanged notes should not go to the kitchen again")
+	}
+}
+
+func TestKitchenStateThroughCombos(t *testing.T) {
+	m := comboMenu()
+	combo, _ := m.ParseCombo("Pizza + Refri = 52,00: Pizzas *; Coca-Cola 2L")
+
+	o := NewOrder(1)
+	o.AddItem(m.Items[0], 1, "")
+	o.AddItem(m.Items[1], 1, "")
+	o.MarkSentToKitchen()
+	o.AddItem(m.Items[1], 1, "")
+
+	if err := o.ApplyCombo(combo); err != nil {
+		t.Fatal(err)
+	}
+	pending := o.PendingKitchenItems()
+	if len(pending) != 1 || pending[0].Item.ID != 2 || pending[0].Quantity != 1 {
+		t.Fatalf("pending = %+v, want only the unsent cola", pending)
+	}
+
+	idx := len(o.Items) - 1
+	if err := o.BreakCombo(idx); err != nil {
+		t.Fatal(err)
+	}
+	if len(o.TakeKitchenCancellations()) != 0 {
+		t.Error("breaking a combo should not cancel its items")
+	}
+	if pending := o.PendingKitchenItems(); len(pending) != 1 || pending[0].Quantity != 1 {
+		t.Errorf("pending = %+v, want only the unsent cola", pending)
+	}
+
+	if err := o.ApplyCombo(combo); err != nil {
+		t.Fatal(err)
+	}
+	o.CancelKitchen()
+	if cancels := o.TakeKitchenCancellations(); len(cancels) != 2 {
+		t.Errorf("cancels = %+v, want the sent pizza and cola", cancels)
+	}
+}
+
+func TestMarkReady(t *testing.T) {
+	pizza := MenuItem{ID: 1, Name: "Calabresa", Price: 4500, Category: "Pizzas Tradicionais"}
+
+	o := NewOrder(1)
+	o.AddItem(pizza, 2, "")
+	o.AddItem(pizza, 1, "sem cebola")
+	if len(o.AwaitingKitchen()) != 0 {
+		t.Fatal("nothing awaits the kitchen before sending")
+	}
+	o.MarkSentToKitchen()
+	if n := len(o.AwaitingKitchen()); n != 2 {
+		t.Fatalf("awaiting %d lines, want 2", n)
+	}
+
+	if n := o.MarkReady(OrderItem{Item: pizza, Quantity: 1}, 1); n != 1 {
+		t.Fatalf("marked %d, want 1", n)
+	}
+	// More than was sent only marks what was waiting.
+	if n := o.MarkReady(OrderItem{Item: pizza, Quantity: 5}, 5); n != 1 {
+		t.Fatalf("marked %d, want 1", n)
+	}
+	awaiting := o.AwaitingKitchen()
+	if len(awaiting) != 1 || awaiting[0].Notes != "sem cebola" {
+		t.Fatalf("awaiting = %+v, want the pizza without onion", awaiting)
+	}
+
+	o.UpdateQuantity(0, 1)
+	if o.Items[0].Ready != 1 {
+		t.Errorf("ready = %d after removing a unit, want 1", o.Items[0].Ready)
+	}
+}
@@ -0,0 +1,214 @@
+package pos
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Option is one choice of an option group, such as "Catupiry" in "Borda".
+type Option struct {
+	Name  string `json:"name"`
+	Price int64  `json:"price"` // centavos added to the item; may be negative
+}
+
+// OptionGroup is a set of choices offered when an item is added: sizes,
+// borders, extras. A group with Min > 0 is required.
+type OptionGroup struct {
+	Name    string   `json:"name"`
+	Min     int      `json:"min"`
+	Max     int      `json:"max"` // 0 = no limit
+	Options []Option `json:"options"`
+}
+
+func (g OptionGroup) Required() bool {
+	return g.Min > 0
+}
+
+func (g OptionGroup) option(name string) (Option, bool) {
+	for _, opt := range g.Options {
+		if opt.Name == name {
+			return opt, true
+		}
+	}
+	return Option{}, false
+}
+
+// Modifier is an option chosen for an order item. It keeps the price
+// charged at the time, so later menu changes do not alter past orders.
+type Modifier struct {
+	Group  string `json:"group"`
+	Option string `json:"option"`
+	Price  int64  `json:"price"` // centavos
+}
+
+func (m Modifier) String() string {
+	return m.Group + ": " + m.Option
+}
+
+// Choose turns the options picked in each group (by group name) into
+// modifiers, checking the group limits.
+func Choose(groups []OptionGroup, picked map[string][]string) ([]Modifier, error) {
+	var mods []Modifier
+	for _, g := range groups {
+		names := picked[g.Name]
+		if len(names) < g.Min {
+			if g.Min == 1 {
+				return nil, fmt.Errorf("escolha uma opcao de %s", g.Name)
+			}
+			return nil, fmt.Errorf("escolha pelo menos %d opcoes de %s", g.Min, g.Name)
+		}
+		if g.Max > 0 && len(names) > g.Max {
+			return nil, fmt.Errorf("escolha no maximo %d opcoes de %s", g.Max, g.Name)
+		}
+		for _, name := range names {
+			opt, ok := g.option(name)
+			if !ok {
+				return nil, fmt.Errorf("opcao %s inexistente em %s", name, g.Name)
+			}
+			mods = append(mods, Modifier{Group: g.Name, Option: opt.Name, Price: opt.Price})
+		}
+	}
+	return mods, nil
+}
+
+func sameModifiers(a, b []Modifier) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// OptionGroupsFor returns the option groups offered with item, in the
+// order the item lists them. Unknown names are skipped.
+func (m *Menu) OptionGroupsFor(item MenuItem) []OptionGroup {
+	var groups []OptionGroup
+	for _, name := range item.OptionGroups {
+		for _, g := range m.OptionGroups {
+			if g.Name == name {
+				groups = append(groups, g)
+				break
+			}
+		}
+	}
+	return groups
+}
+
+// ParseOptionGroup reads the one-line form used by the menu editor:
+//
+//	Borda (0-1): Catupiry +8,00; Cheddar +8,00; Sem borda
+//
+// The range is the minimum and maximum number of choices, with "1" short
+// for "1-1" and an empty maximum ("0-") meaning no limit.
+func ParseOptionGroup(line string) (OptionGroup, error) {
+	head, body, ok := strings.Cut(line, ":")
+	if !ok {
+		return OptionGroup{}, fmt.Errorf("grupo sem opcoes: %q", line)
+	}
+	var g OptionGroup
+	name, limits, hasLimits := strings.Cut(head, "(")
+	g.Name = strings.TrimSpace(name)
+	if g.Name == "" {
+		return OptionGroup{}, fmt.Errorf("grupo sem nome: %q", line)
+	}
+	if hasLimits {
+		limits = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(limits), ")"))
+		minText, maxText, isRange := strings.Cut(limits, "-")
+		min, err := strconv.Atoi(strings.TrimSpace(minText))
+		if err != nil || min < 0 {
+			return OptionGroup{}, fmt.Errorf("grupo %s: limite invalido %q", g.Name, limits)
+		}
+		g.Min, g.Max = min, min
+		if isRange {
+			g.Max = 0
+			if maxText = strings.TrimSpace(maxText); maxText != "" {
+				g.Max, err = strconv.Atoi(maxText)
+				if err != nil || g.Max < min || g.Max == 0 {
+					return OptionGroup{}, fmt.Errorf("grupo %s: limite invalido %q", g.Name, limits)
+				}
+			}
+		}
+	} else {
+		g.Max = 1
+	}
+
+	for _, part := range strings.Split(body, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		opt := Option{Name: part}
+		if i := strings.LastIndexAny(part, "+-"); i > 0 && part[i-1] == ' ' {
+			price, err := parseSignedPrice(part[i:])
+			if err != nil {
+				return OptionGroup{}, fmt.Errorf("grupo %s: preco invalido em %q", g.Name, part)
+			}
+			opt = Option{Name: strings.TrimSpace(part[:i]), Price: price}
+		}
+		g.Options = append(g.Options, opt)
+	}
+	if len(g.Options) == 0 {
+		return OptionGroup{}, fmt.Errorf("grupo %s sem opcoes", g.Name)
+	}
+	if g.Max > 0 && g.Min > len(g.Options) {
+		return OptionGroup{}, fmt.Errorf("grupo %s exige %d opcoes mas tem %d", g.Name, g.Min, len(g.Options))
+	}
+	return g, nil
+}
+
+// String is the inverse of ParseOptionGroup.
+func (g OptionGroup) String() string {
+	limits := strconv.Itoa(g.Min) + "-"
+	if g.Max > 0 {
+		limits += strconv.Itoa(g.Max)
+	}
+	if g.Max == g.Min {
+		limits = strconv.Itoa(g.Min)
+	}
+	parts := make([]string, len(g.Options))
+	for i, opt := range g.Options {
+		parts[i] = opt.Name
+		if opt.Price != 0 {
+			sign := "+"
+			price := opt.Price
+			if price < 0 {
+				sign, price = "-", -price
+			}
+			parts[i] += fmt.Sprintf(" %s%d,%02d", sign, price/100, price%100)
+		}
+	}
+	return fmt.Sprintf("%s (%s): %s", g.Name, limits, strings.Join(parts, "; "))
+}
+
+// parseSignedPrice reads "+8,00", "-2.50" or "+3" as centavos.
+func parseSignedPrice(s string) (int64, error) {
+	sign := int64(1)
+	if strings.HasPrefix(s, "-") {
+		sign = -1
+	}
+	s = strings.TrimLeft(s, "+-")
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", ".")
+	reais, cents, hasCents := strings.Cut(s, ".")
+	r, err := strconv.ParseInt(reais, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	var c int64
+	if hasCents {
+		if len(cents) == 1 {
+			cents += "0"
+		}
+		if len(cents) != 2 {
+			return 0, fmt.Errorf("centavos invalidos: %q", cents)
+		}
+		if c, err = strconv.ParseInt(cents, 10, 64); err != nil {
+			return 0, err
+		}
+	}
+	return sign * (r*100 + c), nil
+}
@@ -0,0 +1,88 @@
+package pos
+
+import (
+	"reflect"
+	"testing"
+)
+
+func pizzaGroups() []OptionGroup {
+	return []OptionGroup{
+		{Name: "Tamanho", Min: 1, Max: 1, Options: []Option{{"Media", 0}, {"Grande", 1500}}},
+		{Name: "Borda", Min: 0, Max: 1, Options: []Option{{"Catupiry", 800}, {"Cheddar", 800}}},
+		{Name: "Extras", Min: 0, Max: 0, Options: []Option{{"Bacon", 500}, {"Sem cebola", 0}}},
+	}
+}
+
+func TestChooseModifiers(t *testing.T) {
+	groups := pizzaGroups()
+	mods, err := Choose(groups, map[string][]string{
+		"Tamanho": {"Grande"},
+		"Borda":   {"Catupiry"},
+		"Extras":  {"Bacon", "Sem cebola"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mods) != 4 || mods[0].String() != "Tamanho: Grande" {
+		t.Errorf("modifiers = %+v", mods)
+	}
+
+	if _, err := Choose(groups, map[string][]string{}); err == nil {
+		t.Error("missing a required group should fail")
+	}
+	if _, err := Choose(groups, map[string][]string{"Tamanho": {"Media"}, "Borda": {"Catupiry", "Cheddar"}}); err == nil {
+		t.Error("choosing over the maximum should fail")
+	}
+	if _, err := Choose(groups, map[string][]string{"Tamanho": {"Gigante"}}); err == nil {
+		t.Error("an unknown option should fail")
+	}
+}
+
+func TestModifiersInTotal(t *testing.T) {
+	pizza := MenuItem{ID: 1, Name: "Calabresa", Price: 4500, OptionGroups: []string{"Tamanho", "Borda"}}
+	grande := []Modifier{{"Tamanho", "Grande", 1500}, {"Borda", "Catupiry", 800}}
+
+	o := NewOrder(1)
+	o.AddItemWithModifiers(pizza, 1, "", grande)
+	o.AddItem(pizza, 1, "")
+	o.AddItemWithModifiers(pizza, 1, "", grande)
+	if len(o.Items) != 2 || o.Items[0].Quantity != 2 {
+		t.Fatalf("items = %+v, want 2x with modifiers and 1x plain", o.Items)
+	}
+	if got := o.Total(); got != 2*(4500+1500+800)+4500 {
+		t.Errorf("Total = %d, want %d", got, 2*(4500+1500+800)+4500)
+	}
+
+	m := &Menu{Items: []MenuItem{pizza}, OptionGroups: pizzaGroups()}
+	if groups := m.OptionGroupsFor(pizza); len(groups) != 2 || groups[1].Name != "Borda" {
+		t.Errorf("OptionGroupsFor = %+v", groups)
+	}
+}
+
+func TestParseOptionGroup(t *testing.T) {
+	g, err := ParseOptionGroup("Borda (0-1): Catupiry +8,00; Cheddar +8; Sem borda")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := OptionGroup{Name: "Borda", Min: 0, Max: 1, Options: []Option{{"Catupiry", 800}, {"Cheddar", 800}, {"Sem borda", 0}}}
+	if !reflect.DeepEqual(g, want) {
+		t.Errorf("ParseOptionGroup = %+v, want %+v", g, want)
+	}
+	if s := g.String(); s != "Borda (0-1): Catupiry +8,00; Cheddar +8,00; Sem borda" {
+		t.Errorf("String = %q", s)
+	}
+
+	g, err = ParseOptionGroup("Extras (0-): Bacon +5,00; Coca-Cola +6,50; Sem queijo -2,00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Max != 0 || g.Options[1].Name != "Coca-Cola" || g.Options[2].Price != -200 {
+		t.Errorf("ParseOptionGroup = %+v", g)
+	}
+
+	for _, bad := range []string{"Borda", "(1): A", "Tamanho (2-1): A; B", "Tamanho (3): A; B"} {
+		if _, err := ParseOptionGroup(bad); err == nil {
+			t.Errorf("ParseOptionGroup(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package pos
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Role is what an operator account may do.
+type Role string
+
+const (
+	RoleCaixa   Role = "caixa"   // takes orders, charges them and runs the register
+	RoleGarcom  Role = "garcom"  // takes orders only
+	RoleGerente Role = "gerente" // everything, including settings, accounts and refunds
+)
+
+// Roles lists the roles in display order.
+func Roles() []Role {
+	return []Role{RoleCaixa, RoleGarcom, RoleGerente}
+}
+
+// Label is the role name shown to users.
+func (r Role) Label() string {
+	switch r {
+	case RoleCaixa:
+		return "Caixa"
+	case RoleGarcom:
+		return "Garcom"
+	case RoleGerente:
+		return "Gerente"
+	}
+	return string(r)
+}
+
+// RoleFromLabel is the inverse of Label.
+func RoleFromLabel(label string) (Role, bool) {
+	for _, r := range Roles() {
+		if r.Label() == label {
+			return r, true
+		}
+	}
+	return "", false
+}
+
+// Operator is a user account. Orders, refunds and cash movements carry
+// the ID of the operator logged in when they happened; IDs are never
+// reused, so inactive accounts keep their history.
+type Operator struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Role    Role   `json:"role"`
+	PINHash string `json:"pin_hash"` // see HashPIN
+	Active  bool   `json:"active"`
+}
+
+// NewOperator returns an active account with its PIN hashed.
+func NewOperator(id int, name string, role Role, pin string) (Operator, error) {
+	op := Operator{ID: id, Name: strings.TrimSpace(name), Role: role, Active: true}
+	if op.Name == "" {
+		return Operator{}, fmt.Errorf("informe o nome do operador")
+	}
+	if !slices.Contains(Roles(), role) {
+		return Operator{}, fmt.Errorf("funcao desconhecida: %s", role)
+	}
+	if err := op.SetPIN(pin); err != nil {
+		return Operator{}, err
+	}
+	return op, nil
+}
+
+// SetPIN replaces the PIN, which must have 4 to 8 digits.
+func (op *Operator) SetPIN(pin string) error {
+	if len(pin) < 4 || len(pin) > 8 || strings.IndexFunc(pin, func(r rune) bool { return !unicode.IsDigit(r) }) >= 0 {
+		return fmt.Errorf("o PIN deve ter de 4 a 8 digitos")
+	}
+	hash, err := HashPIN(pin)
+	if err != nil {
+		return err
+	}
+	op.PINHash = hash
+	return nil
+}
+
+// CheckPIN reports whether pin is the operator's PIN.
+func (op Operator) CheckPIN(pin string) bool {
+	return CheckPIN(op.PINHash, pin)
+}
+
+// CanCharge reports whether the operator may finalize orders and handle
+// the cash register.
+func (op Operator) CanCharge() bool {
+	return op.Role == RoleCaixa || op.Role == RoleGerente
+}
+
+// IsManager reports whether the operator may change settings, accounts
+// and authorize refunds.
+func (op Operator) IsManager() bool {
+	return op.Role == RoleGerente
+}
+
+// ActiveOperators returns the accounts that can log in.
+func ActiveOperators(ops []Operator) []Operator {
+	var active []Operator
+	for _, op := range ops {
+		if op.Active {
+			active = append(active, op)
+		}
+	}
+	return active
+}
+
+// ValidateOperators checks a set of accounts before saving: names must be
+// unique, since login is by name, and while any account is active one of
+// them must be a manager, or nobody could manage the accounts again.
+func ValidateOperators(ops []Operator) error {
+	names := map[string]bool{}
+	manager := false
+	for _, op := range ops {
+		key := strings.ToLower(strings.TrimSpace(op.Name))
+		if key == "" {
+			return fmt.Errorf("informe o nome do operador")
+		}
+		if names[key] {
+			return fmt.Errorf("ja existe um operador chamado %s", op.Name)
+		}
+		names[key] = true
+		if op.Active && op.IsManager() {
+			manager = true
+		}
+	}
+	if len(ActiveOperators(ops)) > 0 && !manager {
+		return fmt.Errorf("mantenha ao menos um gerente ativo")
+	}
+	return nil
+}
+
+// FindOperator returns the account with id.
+func FindOperator(ops []Operator, id int) (Operator, bool) {
+	for _, op := range ops {
+		if op.ID == id {
+			return op, true
+		}
+	}
+	return Operator{}, false
+}
+
+// NextOperatorID returns an ID not used by any account.
+func NextOperatorID(ops []Operator) int {
+	id := 1
+	for _, op := range ops {
+		if op.ID >= id {
+			id = op.ID + 1
+		}
+	}
+	return id
+}
+
+// Authenticate returns the active account named name whose PIN is pin.
+func Authenticate(ops []Operator, name, pin string) (Operator, error) {
+	for _, op := range ops {
+		if op.Active && op.Name == name {
+			if !op.CheckPIN(pin) {
+				return Operator{}, fmt.Errorf("PIN incorreto")
+			}
+			return op, nil
+		}
+	}
+	return Operator{}, fmt.Errorf("operador %q nao encontrado", name)
+}
+
+// AuthorizeManager returns the active manager whose PIN is pin, for
+// actions an operator needs a manager to approve.
+func AuthorizeManager(ops []Operator, pin string) (Operator, error) {
+	for _, op := range ops {
+		if op.Active && op.IsManager() && op.CheckPIN(pin) {
+			return op, nil
+		}
+	}
+	return Operator{}, fmt.Errorf("PIN do gerente incorreto")
+}
+
+// OperatorSales is what one operator did during a day. Orders, revenue
+// and service go to whoever took the order; discounts, cancellations and
+// refunds to whoever applied them. ID 0 collects orders made with no one
+// logged in.
+type OperatorSales struct {
+	OperatorID  int    `json:"operator_id"`
+	Name        string `json:"name,omitempty"` // filled by NameOperators
+	Orders      int    `json:"orders"`         // finalized orders taken
+	Revenue     int64  `json:"revenue"`        // centavos, after refunds
+	Service     int64  `json:"service,omitempty"`
+	Discounts   int64  `json:"discounts,omitempty"`
+	Cancelled   int    `json:"cancelled,omitempty"`
+	Refunds     int    `json:"refunds,omitempty"`
+	RefundTotal int64  `json:"refund_total,omitempty"`
+}
+
+// operatorSales breaks orders down per operator, by ID. It returns nil
+// when no order carries an operator, as before accounts were set up.
+func operatorSales(orders []Order) []OperatorSales {
+	byID := map[int]*OperatorSales{}
+	get := func(id int) *OperatorSales {
+		if byID[id] == nil {
+			byID[id] = &OperatorSales{OperatorID: id}
+		}
+		return byID[id]
+	}
+
+	for _, o := range orders {
+		if o.Status == StatusCancelado {
+			by := o.ClosedByID
+			if n := len(o.Refunds); n > 0 {
+				by = o.Refunds[n-1].OperatorID
+			}
+			get(by).Cancelled++
+		}
+		if !o.WasPaid() {
+			continue
+		}
+		taker := get(o.OperatorID)
+		taker.Revenue += o.NetTotal()
+		taker.Service += o.ServiceCharge() - o.refundedService()
+		if o.Status == StatusFinalizado {
+			taker.Orders++
+		}
+		if o.Discount > 0 {
+			get(o.DiscountByID).Discounts += o.Discount
+		}
+		for _, r := range o.Refunds {
+			s := get(r.OperatorID)
+			s.Refunds++
+			s.RefundTotal += r.Amount
+		}
+	}
+
+	if len(byID) == 0 || (len(byID) == 1 && byID[0] != nil) {
+		return nil
+	}
+	sales := make([]OperatorSales, 0, len(byID))
+	for _, s := range byID {
+		sales = append(sales, *s)
+	}
+	sort.Slice(sales, func(i, j int) bool { return sales[i].OperatorID < sales[j].OperatorID })
+	return sales
+}
+
+// OperatorName is the name of the account with id, "" for ID 0 (no one
+// logged in) and a placeholder for an account no longer on file.
+func OperatorName(ops []Operator, id int) string {
+	if id == 0 {
+		return ""
+	}
+	if op, ok := FindOperator(ops, id); ok {
+		return op.Name
+	}
+	return fmt.Sprintf("Operador %d", id)
+}
+
+// NameOperators fills in the operator names of the per-operator
+// breakdown from the accounts.
+func (s *DaySummary) NameOperators(ops []Operator) {
+	for i := range s.ByOperator {
+		s.ByOperator[i].Name = OperatorName(ops, s.ByOperator[i].OperatorID)
+		if s.ByOperator[i].Name == "" {
+			s.ByOperator[i].Name = "Sem operador"
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package pos
+
+import "testing"
+
+func TestOperatorAccounts(t *testing.T) {
+	if _, err := NewOperator(1, "Ana", RoleGerente, "12a4"); err == nil {
+		t.Error("a PIN with letters should be rejected")
+	}
+	if _, err := NewOperator(1, " ", RoleGerente, "1234"); err == nil {
+		t.Error("an operator without name should be rejected")
+	}
+
+	ana, err := NewOperator(1, "Ana", RoleGerente, "1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bia, err := NewOperator(NextOperatorID([]Operator{ana}), "Bia", RoleGarcom, "5678")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bia.ID != 2 {
+		t.Errorf("next ID = %d, want 2", bia.ID)
+	}
+	ops := []Operator{ana, bia}
+
+	if _, err := Authenticate(ops, "Bia", "1234"); err == nil {
+		t.Error("Bia logged in with Ana's PIN")
+	}
+	if op, err := Authenticate(ops, "Bia", "5678"); err != nil || op.ID != 2 {
+		t.Errorf("Authenticate = %+v, %v", op, err)
+	}
+	if op, err := AuthorizeManager(ops, "1234"); err != nil || op.Name != "Ana" {
+		t.Errorf("AuthorizeManager = %+v, %v", op, err)
+	}
+	if _, err := AuthorizeManager(ops, "5678"); err == nil {
+		t.Error("a waiter's PIN authorized as manager")
+	}
+	if bia.CanCharge() || !ana.CanCharge() || bia.IsManager() {
+		t.Error("role permissions are wrong")
+	}
+
+	if err := ValidateOperators([]Operator{bia}); err == nil {
+		t.Error("accounts without an active manager should be rejected")
+	}
+	if err := ValidateOperators([]Operator{ana, {ID: 3, Name: "ana", Role: RoleCaixa}}); err == nil {
+		t.Error("duplicate names should be rejected")
+	}
+
+	ops[1].Active = false
+	if _, err := Authenticate(ops, "Bia", "5678"); err == nil {
+		t.Error("an inactive operator logged in")
+	}
+}
+
+func TestDaySummaryByOperator(t *testing.T) {
+	taken := func(number, operator int, price int64) Order {
+		o := NewOrder(number)
+		o.AddItem(MenuItem{ID: 1, Name: "Pizza", Price: price}, 1, "")
+		o.OperatorID = operator
+		o.Finalize(PaymentDinheiro)
+		o.ClosedByID = 1
+		return *o
+	}
+
+	legacy := ComputeDaySummary("2026-03-14", []Order{taken(1, 0, 5000)})
+	if legacy.ByOperator != nil {
+		t.Errorf("orders without operator should not be broken down: %+v", legacy.ByOperator)
+	}
+
+	discounted := taken(2, 2, 5000)
+	discounted.Discount = 500
+	discounted.DiscountByID = 1
+	refunded := taken(3, 2, 3000)
+	if _, err := refunded.CancelPaid(Refund{Method: PaymentDinheiro, Reason: "erro", OperatorID: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := ComputeDaySummary("2026-03-14", []Order{taken(1, 0, 5000), discounted, refunded})
+	s.NameOperators([]Operator{{ID: 1, Name: "Ana"}, {ID: 2, Name: "Bia"}})
+	if len(s.ByOperator) != 3 {
+		t.Fatalf("ByOperator = %+v", s.ByOperator)
+	}
+	none, ana, bia := s.ByOperator[0], s.ByOperator[1], s.ByOperator[2]
+	if none.Name != "Sem operador" || none.Orders != 1 || none.Revenue != 5000 {
+		t.Errorf("no operator = %+v", none)
+	}
+	if ana.Name != "Ana" || ana.Orders != 0 || ana.Discounts != 500 || ana.Cancelled != 1 || ana.RefundTotal != 3000 {
+		t.Errorf("Ana = %+v", ana)
+	}
+	if bia.Name != "Bia" || bia.Orders != 1 || bia.Revenue != 4500 {
+		t.Errorf("Bia = %+v", bia)
+	}
+}
@@ -2,6 +2,7 @@ package pos
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 )
@@ -28,16 +29,48 @@ type MenuItem struct {
 	Price    int64  `json:"price"` // centavos
 	Category string `json:"category"`
 	Active   bool   `json:"active"`
+
+	OptionGroups []string `json:"option_groups,omitempty"` // names of Menu.OptionGroups offered with the item
 }
 
 type OrderItem struct {
-	Item     MenuItem `json:"item"`
-	Quantity int      `json:"quantity"`
-	Notes    string   `json:"notes"`
+	Item      MenuItem   `json:"item"`
+	Quantity  int        `json:"quantity"`
+	Notes     string     `json:"notes"`
+	Modifiers []Modifier `json:"modifiers,omitempty"`
+	Flavors   []Flavor   `json:"flavors,omitempty"` // set for pizzas of several flavors
+
+	// Components are the items bundled in a combo line, per combo.
+	Components []OrderItem `json:"components,omitempty"`
+
+	// PriceRule names the scheduled price charged instead of RegularPrice,
+	// the menu price when the item was added.
+	PriceRule    string `json:"price_rule,omitempty"`
+	RegularPrice int64  `json:"regular_price,omitempty"`
+
+	// Sent is how many units the kitchen already received; for a combo
+	// component, counted over all the combos of the line.
+	Sent  int `json:"sent,omitempty"`
+	Ready int `json:"ready,omitempty"` // sent units the kitchen reported ready
+}
+
+// UnitPrice is the item price plus its modifiers. A combo also charges
+// the modifiers of its components, such as a stuffed crust.
+func (oi OrderItem) UnitPrice() int64 {
+	price := oi.Item.Price
+	for _, m := range oi.Modifiers {
+		price += m.Price
+	}
+	for _, c := range oi.Components {
+		for _, m := range c.Modifiers {
+			price += m.Price * int64(c.Quantity)
+		}
+	}
+	return price
 }
 
 func (oi OrderItem) Total() int64 {
-	return oi.Item.Price * int64(oi.Quantity)
+	return oi.UnitPrice() * int64(oi.Quantity)
 }
 
 type PaymentSplit struct {
@@ -46,17 +79,34 @@ type PaymentSplit struct {
 }
 
 type Order struct {
-	Number       int              `json:"number"`
-	Items        []OrderItem      `json:"items"`
-	Customer     string           `json:"customer"`
-	Table        string           `json:"table"`
-	Discount     int64            `json:"discount"` // centavos
-	Payment      PaymentMethod    `json:"payment"`
-	Payments     []PaymentSplit   `json:"payments,omitempty"`
-	CashReceived int64            `json:"cash_received,omitempty"`
-	Status       OrderStatus      `json:"status"`
-	CreatedAt    time.Time        `json:"created_at"`
-	ClosedAt     time.Time        `json:"closed_at,omitempty"`
+	Number         int            `json:"number"`
+	Items          []OrderItem    `json:"items"`
+	Customer       string         `json:"customer"`
+	Table          string         `json:"table"`
+	Discount       int64          `json:"discount"`                  // centavos
+	ServicePercent int            `json:"service_percent,omitempty"` // taxa de servico; 0 = none
+	Payment        PaymentMethod  `json:"payment"`
+	Payments       []PaymentSplit `json:"payments,omitempty"`
+	CashReceived   int64          `json:"cash_received,omitempty"`
+	Status         OrderStatus    `json:"status"`
+	CreatedAt      time.Time      `json:"created_at"`
+	ClosedAt       time.Time      `json:"closed_at,omitempty"`
+	SessionID      int            `json:"session_id,omitempty"`      // cash session it was paid in
+	BillRequested  bool           `json:"bill_requested,omitempty"`  // table asked for the bill
+	SubBills       []SubBill      `json:"sub_bills,omitempty"`       // set when the bill is split
+	KitchenCancels []OrderItem    `json:"kitchen_cancels,omitempty"` // sent units taken off, not yet printed
+	Refunds        []Refund       `json:"refunds,omitempty"`         // estornos after payment
+	Reprints       []Reprint      `json:"reprints,omitempty"`        // copies printed from the history
+
+	// Operators responsible for the order, by Operator.ID; 0 when no one
+	// was logged in.
+	OperatorID   int `json:"operator_id,omitempty"`    // took the order
+	ClosedByID   int `json:"closed_by_id,omitempty"`   // finalized or cancelled it
+	DiscountByID int `json:"discount_by_id,omitempty"` // applied the discount
+
+	// PriceRules are the scheduled prices AddItem applies; they come from
+	// the menu and are not saved with the order.
+	PriceRules []PriceRule `json:"-"`
 }
 
 func NewOrder(number int) *Order {
@@ -69,18 +119,88 @@ func NewOrder(number int) *Order {
 	}
 }
 
+// Clone returns a copy of o that shares no slices with it, for drafts such
+// as the receipt preview.
+func (o *Order) Clone() *Order {
+	c := *o
+	c.Items = cloneItems(o.Items)
+	c.Payments = slices.Clone(o.Payments)
+	if o.SubBills != nil {
+		c.SubBills = make([]SubBill, len(o.SubBills))
+		for i, b := range o.SubBills {
+			c.SubBills[i] = b.clone()
+		}
+	}
+	c.KitchenCancels = cloneItems(o.KitchenCancels)
+	if o.Refunds != nil {
+		c.Refunds = make([]Refund, len(o.Refunds))
+		for i, r := range o.Refunds {
+			r.Items = slices.Clone(r.Items)
+			c.Refunds[i] = r
+		}
+	}
+	c.Reprints = slices.Clone(o.Reprints)
+	c.PriceRules = slices.Clone(o.PriceRules)
+	return &c
+}
+
+func (oi OrderItem) clone() OrderItem {
+	oi.Item.OptionGroups = slices.Clone(oi.Item.OptionGroups)
+	oi.Modifiers = slices.Clone(oi.Modifiers)
+	oi.Flavors = slices.Clone(oi.Flavors)
+	oi.Components = cloneItems(oi.Components)
+	return oi
+}
+
+func cloneItems(items []OrderItem) []OrderItem {
+	if items == nil {
+		return nil
+	}
+	c := make([]OrderItem, len(items))
+	for i, oi := range items {
+		c[i] = oi.clone()
+	}
+	return c
+}
+
 func (o *Order) AddItem(item MenuItem, quantity int, notes string) {
+	o.AddItemWithModifiers(item, quantity, notes, nil)
+}
+
+// AddItemWithModifiers adds an item with the options chosen for it, at the
+// price charged now after the order's price rules. Lines only merge when
+// item, notes, modifiers and price all match.
+func (o *Order) AddItemWithModifiers(item MenuItem, quantity int, notes string, mods []Modifier) {
+	o.addLine(o.priced(OrderItem{
+		Item:      item,
+		Quantity:  quantity,
+		Notes:     notes,
+		Modifiers: mods,
+	}))
+}
+
+// addLine appends line, or adds its quantity to an identical line.
+func (o *Order) addLine(line OrderItem) {
 	for i, oi := range o.Items {
-		if oi.Item.ID == item.ID && oi.Notes == notes {
-			o.Items[i].Quantity += quantity
+		if oi.sameLine(line) {
+			o.Items[i].Quantity += line.Quantity
+			o.Items[i].Sent += line.Sent
+			o.Items[i].Ready += line.Ready
+			for j, c := range line.Components {
+				o.Items[i].Components[j].Sent += c.Sent
+				o.Items[i].Components[j].Ready += c.Ready
+			}
 			return
 		}
 	}
-	o.Items = append(o.Items, OrderItem{
-		Item:     item,
-		Quantity: quantity,
-		Notes:    notes,
-	})
+	o.Items = append(o.Items, line)
+}
+
+func (oi OrderItem) sameLine(other OrderItem) bool {
+	return oi.Item.ID == other.Item.ID && oi.Item.Name == other.Item.Name &&
+		oi.Item.Price == other.Item.Price && oi.Notes == other.Notes &&
+		sameModifiers(oi.Modifiers, other.Modifiers) && sameFlavors(oi.Flavors, other.Flavors) &&
+		sameComponents(oi.Components, other.Components)
 }
 
 func PaymentMethodLabels() []string {
@@ -91,10 +211,13 @@ func (o *Order) isValidItemIndex(index int) bool {
 	return index >= 0 && index < len(o.Items)
 }
 
+// RemoveItem takes a line off the order; units already sent to the
+// kitchen are recorded for a cancellation ticket.
 func (o *Order) RemoveItem(index int) {
 	if !o.isValidItemIndex(index) {
 		return
 	}
+	o.cancelSent(o.Items[index], 0)
 	o.Items = append(o.Items[:index], o.Items[index+1:]...)
 }
 
@@ -106,13 +229,41 @@ func (o *Order) UpdateQuantity(index, quantity int) {
 		o.RemoveItem(index)
 		return
 	}
+	o.Items[index] = o.cancelSent(o.Items[index], quantity)
 	o.Items[index].Quantity = quantity
 }
 
-func (o *Order) UpdateNotes(index int, notes string) {
+// IncrementItem adds one unit of line index at the price charged now: it
+// joins a line at the same price, or starts a new one when the price rules
+// give a different price than when the line was added.
+func (o *Order) IncrementItem(index int) {
 	if !o.isValidItemIndex(index) {
 		return
 	}
+	oi := o.Items[index]
+	if oi.IsCombo() {
+		o.UpdateQuantity(index, oi.Quantity+1)
+		return
+	}
+	unit := oi.clone()
+	if unit.PriceRule != "" {
+		unit.Item.Price = unit.RegularPrice
+	}
+	unit.PriceRule, unit.RegularPrice = "", 0
+	unit.Quantity, unit.Sent, unit.Ready = 1, 0, 0
+	o.addLine(o.priced(unit))
+}
+
+// UpdateNotes changes the notes of a line. Units already sent to the
+// kitchen are cancelled and become pending again, so the kitchen gets the
+// new notes on a ticket of their own.
+func (o *Order) UpdateNotes(index int, notes string) {
+	if !o.isValidItemIndex(index) || o.Items[index].Notes == notes {
+		return
+	}
+	if !o.Items[index].IsCombo() {
+		o.Items[index] = o.cancelSent(o.Items[index], 0)
+	}
 	o.Items[index].Notes = notes
 }
 
@@ -124,7 +275,8 @@ func (o *Order) Subtotal() int64 {
 	return total
 }
 
-func (o *Order) Total() int64 {
+// DiscountedSubtotal is the subtotal after the discount, never negative.
+func (o *Order) DiscountedSubtotal() int64 {
 	total := o.Subtotal() - o.Discount
 	if total < 0 {
 		return 0
@@ -132,6 +284,25 @@ func (o *Order) Total() int64 {
 	return total
 }
 
+// EffectiveDiscount is the part of Discount actually taken off, which is
+// capped at the subtotal.
+func (o *Order) EffectiveDiscount() int64 {
+	return o.Subtotal() - o.DiscountedSubtotal()
+}
+
+// ServiceCharge is the taxa de servico on the discounted subtotal, rounded
+// to the nearest centavo.
+func (o *Order) ServiceCharge() int64 {
+	if o.ServicePercent <= 0 {
+		return 0
+	}
+	return (o.DiscountedSubtotal()*int64(o.ServicePercent) + 50) / 100
+}
+
+func (o *Order) Total() int64 {
+	return o.DiscountedSubtotal() + o.ServiceCharge()
+}
+
 func (o *Order) Finalize(payment PaymentMethod) {
 	o.Status = StatusFinalizado
 	o.Payment = payment
@@ -191,15 +362,41 @@ func (o *Order) Cancel() {
 	o.ClosedAt = time.Now()
 }
 
+// IsEmpty reports whether an open order has nothing worth keeping: no
+// items and no customer or table to identify it.
+func (o *Order) IsEmpty() bool {
+	return len(o.Items) == 0 && strings.TrimSpace(o.Customer) == "" && strings.TrimSpace(o.Table) == ""
+}
+
+// Label identifies an open order for the operator: "Mesa 5 - Ana (#12)".
+func (o *Order) Label() string {
+	var parts []string
+	if t := strings.TrimSpace(o.Table); t != "" {
+		parts = append(parts, "Mesa "+t)
+	}
+	if c := strings.TrimSpace(o.Customer); c != "" {
+		parts = append(parts, c)
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("Pedido #%d", o.Number)
+	}
+	return fmt.Sprintf("%s (#%d)", strings.Join(parts, " - "), o.Number)
+}
+
 type DaySummary struct {
-	Date             string                    `json:"date"`
-	TotalOrders      int                       `json:"total_orders"`
-	FinalizedOrders  int                       `json:"finalized_orders"`
-	CancelledOrders  int                       `json:"cancelled_orders"`
-	TotalRevenue     int64                     `json:"total_revenue"`
-	ByPayment        map[PaymentMethod]int64   `json:"by_payment"`
-	OrdersByPayment  map[PaymentMethod]int     `json:"orders_by_payment"`
-	AverageTicket    int64                     `json:"average_ticket"`
+	Date            string                  `json:"date"`
+	TotalOrders     int                     `json:"total_orders"`
+	FinalizedOrders int                     `json:"finalized_orders"`
+	CancelledOrders int                     `json:"cancelled_orders"`
+	TotalRevenue    int64                   `json:"total_revenue"`
+	ServiceTotal    int64                   `json:"service_total"` // taxa de servico collected, for staff
+	ByPayment       map[PaymentMethod]int64 `json:"by_payment"`
+	OrdersByPayment map[PaymentMethod]int   `json:"orders_by_payment"`
+	AverageTicket   int64                   `json:"average_ticket"`
+	Refunds         int                     `json:"refunds,omitempty"`      // estornos of the day's sales
+	RefundTotal     int64                   `json:"refund_total,omitempty"` // already taken off the revenue
+	Items           []ItemSales             `json:"items,omitempty"`        // finalized orders, best sellers first
+	ByOperator      []OperatorSales         `json:"by_operator,omitempty"`  // empty when no order has an operator
 }
 
 func ComputeDaySummary(date string, orders []Order) DaySummary {
@@ -209,24 +406,43 @@ func ComputeDaySummary(date string, orders []Order) DaySummary {
 		OrdersByPayment: make(map[PaymentMethod]int),
 	}
 
+	var finalized []Order
 	for _, o := range orders {
 		s.TotalOrders++
-		switch o.Status {
-		case StatusFinalizado:
+		if o.Status == StatusCancelado {
+			s.CancelledOrders++
+		}
+		if !o.WasPaid() {
+			continue
+		}
+		// Refunds reverse the sale on the day it was made, leaving only
+		// what was kept.
+		s.TotalRevenue += o.NetTotal()
+		s.ServiceTotal += o.ServiceCharge() - o.refundedService()
+		for _, p := range o.EffectivePayments() {
+			s.ByPayment[p.Method] += p.Amount
+		}
+		for _, r := range o.Refunds {
+			s.ByPayment[r.Method] -= r.Amount
+			s.RefundTotal += r.Amount
+			s.Refunds++
+		}
+		if o.Status == StatusFinalizado {
 			s.FinalizedOrders++
-			s.TotalRevenue += o.Total()
 			for _, p := range o.EffectivePayments() {
-				s.ByPayment[p.Method] += p.Amount
 				s.OrdersByPayment[p.Method]++
 			}
-		case StatusCancelado:
-			s.CancelledOrders++
+			net := o
+			net.Items = o.netItems()
+			finalized = append(finalized, net)
 		}
 	}
 
 	if s.FinalizedOrders > 0 {
 		s.AverageTicket = s.TotalRevenue / int64(s.FinalizedOrders)
 	}
+	s.Items = itemSales(finalized)
+	s.ByOperator = operatorSales(orders)
 
 	return s
 }
@@ -241,7 +457,10 @@ func FormatDateBR(isoDate string) string {
 }
 
 type Menu struct {
-	Items []MenuItem `json:"items"`
+	Items        []MenuItem    `json:"items"`
+	OptionGroups []OptionGroup `json:"option_groups,omitempty"`
+	Combos       []Combo       `json:"combos,omitempty"`
+	PriceRules   []PriceRule   `json:"price_rules,omitempty"`
 }
 
 func NewMenu() *Menu {
@@ -49,6 +49,38 @@ func TestOrderTotals(t *testing.T) {
 	}
 }
 
+func TestServiceCharge(t *testing.T) {
+	order := NewOrder(1)
+	order.AddItem(MenuItem{ID: 1, Name: "Picanha", Price: 8990}, 1, "")
+	order.AddItem(MenuItem{ID: 2, Name: "Chopp", Price: 1200}, 2, "")
+	order.Discount = 1000
+	order.ServicePercent = 10
+
+	// 10% of (11390 - 1000) = 1039, rounded.
+	if got := order.ServiceCharge(); got != 1039 {
+		t.Errorf("ServiceCharge = %d, want 1039", got)
+	}
+	if got := order.Total(); got != 10390+1039 {
+		t.Errorf("Total = %d, want %d", got, 10390+1039)
+	}
+
+	order.Finalize(PaymentDinheiro)
+	order.CashReceived = 12000
+	if got := order.CashChange(); got != 12000-11429 {
+		t.Errorf("CashChange = %d, want %d", got, 12000-11429)
+	}
+
+	s := ComputeDaySummary("2026-02-05", []Order{*order})
+	if s.ServiceTotal != 1039 || s.TotalRevenue != 11429 {
+		t.Errorf("summary service/revenue = %d/%d, want 1039/11429", s.ServiceTotal, s.TotalRevenue)
+	}
+
+	order.Discount = 20000
+	if order.ServiceCharge() != 0 || order.Total() != 0 {
+		t.Error("discount above the subtotal should leave no service charge")
+	}
+}
+
 func TestMenuCategories(t *testing.T) {
 	menu := NewMenu()
 	menu.AddItem(MenuItem{Name: "Cafe", Price: 550, Category: "Bebidas"})
@@ -97,6 +129,25 @@ func TestCancel(t *testing.T) {
 	}
 }
 
+func TestOrderLabel(t *testing.T) {
+	order := NewOrder(12)
+	if !order.IsEmpty() {
+		t.Error("new order should be empty")
+	}
+	if got := order.Label(); got != "Pedido #12" {
+		t.Errorf("Label() = %q, want %q", got, "Pedido #12")
+	}
+
+	order.Table = "5"
+	order.Customer = "Ana"
+	if order.IsEmpty() {
+		t.Error("order with a table should not be empty")
+	}
+	if got := order.Label(); got != "Mesa 5 - Ana (#12)" {
+		t.Errorf("Label() = %q, want %q", got, "Mesa 5 - Ana (#12)")
+	}
+}
+
 func TestComputeDaySummary(t *testing.T) {
 	orders := []Order{
 		{
@@ -184,6 +235,28 @@ func TestUpdateNotes(t *testing.T) {
 	}
 }
 
+func TestOrderClone(t *testing.T) {
+	order := NewOrder(1)
+	order.AddItemWithModifiers(MenuItem{ID: 1, Name: "Pizza", Price: 5000}, 1, "",
+		[]Modifier{{Group: "Borda", Option: "Catupiry", Price: 800}})
+	order.AddItem(MenuItem{ID: 2, Name: "Chopp", Price: 1200}, 2, "")
+	order.Payments = []PaymentSplit{{PaymentPix, 8200}}
+	if err := order.SplitEvenly(2); err != nil {
+		t.Fatal(err)
+	}
+
+	draft := order.Clone()
+	draft.Items[0].Modifiers[0].Price = 0
+	draft.Items[1].Quantity = 5
+	draft.Payments[0].Method = PaymentDinheiro
+	draft.SubBills[0].Amount = 0
+
+	if order.Items[0].Modifiers[0].Price != 800 || order.Items[1].Quantity != 2 ||
+		order.Payments[0].Method != PaymentPix || order.SubBills[0].Amount != 4100 {
+		t.Errorf("changing the clone changed the order: %+v", order)
+	}
+}
+
 func TestFinalizeSplit(t *testing.T) {
 	order := NewOrder(1)
 	order.AddItem(MenuItem{ID: 1, Name: "Cafe", Price: 550, Active: true}, 2, "")
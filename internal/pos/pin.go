@@ -0,0 +1,58 @@
+package pos
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PINs are stored as "pbkdf2-sha256$<iterations>$<salt>$<key>", with salt
+// and key in unpadded base64.
+const (
+	pinScheme     = "pbkdf2-sha256"
+	pinIterations = 600_000
+	pinSaltLen    = 16
+	pinKeyLen     = 32
+)
+
+// HashPIN derives the stored form of a PIN with a random salt.
+func HashPIN(pin string) (string, error) {
+	if strings.TrimSpace(pin) == "" {
+		return "", fmt.Errorf("PIN vazio")
+	}
+	salt := make([]byte, pinSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("gerar salt: %w", err)
+	}
+	key, err := pbkdf2.Key(sha256.New, pin, salt, pinIterations, pinKeyLen)
+	if err != nil {
+		return "", fmt.Errorf("derivar PIN: %w", err)
+	}
+	enc := base64.RawStdEncoding
+	return fmt.Sprintf("%s$%d$%s$%s", pinScheme, pinIterations, enc.EncodeToString(salt), enc.EncodeToString(key)), nil
+}
+
+// CheckPIN reports whether pin matches a hash made by HashPIN.
+func CheckPIN(hash, pin string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != pinScheme {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return false
+	}
+	enc := base64.RawStdEncoding
+	salt, err1 := enc.DecodeString(parts[2])
+	want, err2 := enc.DecodeString(parts[3])
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	got, err := pbkdf2.Key(sha256.New, pin, salt, iterations, len(want))
+	return err == nil && subtle.ConstantTimeCompare(got, want) == 1
+}
@@ -0,0 +1,16 @@
+package pos
+
+import "testing"
+
+func TestHashPIN(t *testing.T) {
+	hash, err := HashPIN("4321")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !CheckPIN(hash, "4321") || CheckPIN(hash, "1234") || CheckPIN("4321", "4321") {
+		t.Error("CheckPIN does not match HashPIN")
+	}
+	if other, _ := HashPIN("4321"); other == hash {
+		t.Error("hashes of the same PIN should use different salts")
+	}
+}
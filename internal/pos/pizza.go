@@ -0,0 +1,151 @@
+package pos
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PizzaPricing is how a pizza with several flavors is priced.
+type PizzaPricing string
+
+const (
+	PricingHighest   PizzaPricing = "maior"   // price of the most expensive flavor
+	PricingAverage   PizzaPricing = "media"   // average of the flavor prices
+	PricingFractions PizzaPricing = "fracoes" // each flavor charged by its fraction
+)
+
+// PizzaPricings lists the pricing rules in display order.
+func PizzaPricings() []PizzaPricing {
+	return []PizzaPricing{PricingHighest, PricingAverage, PricingFractions}
+}
+
+func (p PizzaPricing) Label() string {
+	switch p {
+	case PricingAverage:
+		return "Media dos sabores"
+	case PricingFractions:
+		return "Soma das fracoes"
+	}
+	return "Maior sabor"
+}
+
+const (
+	MinFlavors = 2
+	MaxFlavors = 4
+)
+
+// Flavor is one fraction of a pizza with several flavors ("meio a meio").
+// Parts is its share of the pizza relative to the other flavors: 1 and 1
+// are halves, 2, 1 and 1 are a half and two quarters.
+type Flavor struct {
+	Item  MenuItem `json:"item"`
+	Parts int      `json:"parts"`
+}
+
+// flavorsTotalParts is the denominator shared by the flavors' fractions.
+func flavorsTotalParts(flavors []Flavor) int {
+	total := 0
+	for _, f := range flavors {
+		total += f.Parts
+	}
+	return total
+}
+
+// FlavorFraction renders the fraction of the pizza taken by flavors[i] in
+// lowest terms, such as "1/2" or "1/4".
+func FlavorFraction(flavors []Flavor, i int) string {
+	num, den := flavors[i].Parts, flavorsTotalParts(flavors)
+	if d := gcd(num, den); d > 1 {
+		num, den = num/d, den/d
+	}
+	return fmt.Sprintf("%d/%d", num, den)
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// PriceFlavors applies the pricing rule to the flavors, rounding to the
+// nearest centavo.
+func PriceFlavors(flavors []Flavor, rule PizzaPricing) int64 {
+	if len(flavors) == 0 {
+		return 0
+	}
+	switch rule {
+	case PricingAverage:
+		var sum int64
+		for _, f := range flavors {
+			sum += f.Item.Price
+		}
+		n := int64(len(flavors))
+		return (sum*2 + n) / (2 * n)
+	case PricingFractions:
+		var sum int64
+		for _, f := range flavors {
+			sum += f.Item.Price * int64(f.Parts)
+		}
+		total := int64(flavorsTotalParts(flavors))
+		return (sum*2 + total) / (2 * total)
+	default:
+		var highest int64
+		for _, f := range flavors {
+			if f.Item.Price > highest {
+				highest = f.Item.Price
+			}
+		}
+		return highest
+	}
+}
+
+// NewCompositePizza builds the menu item for a pizza of 2 to 4 flavors,
+// priced by rule. It takes the category of the first flavor, so it is
+// routed to the same station.
+func NewCompositePizza(flavors []Flavor, rule PizzaPricing) (MenuItem, error) {
+	if len(flavors) < MinFlavors || len(flavors) > MaxFlavors {
+		return MenuItem{}, fmt.Errorf("escolha de %d a %d sabores", MinFlavors, MaxFlavors)
+	}
+	names := make([]string, len(flavors))
+	for i, f := range flavors {
+		if f.Parts <= 0 {
+			return MenuItem{}, fmt.Errorf("fracao invalida para %s", f.Item.Name)
+		}
+		names[i] = FlavorFraction(flavors, i) + " " + f.Item.Name
+	}
+	return MenuItem{
+		Name:     "Pizza " + strings.Join(names, " + "),
+		Price:    PriceFlavors(flavors, rule),
+		Category: flavors[0].Item.Category,
+		Active:   true,
+	}, nil
+}
+
+// AddPizza adds a pizza of several flavors as a single order line.
+func (o *Order) AddPizza(flavors []Flavor, rule PizzaPricing, quantity int, notes string, mods []Modifier) error {
+	item, err := NewCompositePizza(flavors, rule)
+	if err != nil {
+		return err
+	}
+	o.addLine(o.priced(OrderItem{
+		Item:      item,
+		Quantity:  quantity,
+		Notes:     notes,
+		Modifiers: mods,
+		Flavors:   append([]Flavor(nil), flavors...),
+	}))
+	return nil
+}
+
+func sameFlavors(a, b []Flavor) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Item.ID != b[i].Item.ID || a[i].Parts != b[i].Parts {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package pos
+
+import "testing"
+
+func TestPriceFlavors(t *testing.T) {
+	calabresa := MenuItem{ID: 1, Name: "Calabresa", Price: 4500, Category: "Pizzas Salgadas"}
+	camarao := MenuItem{ID: 2, Name: "Camarao", Price: 7000, Category: "Pizzas Salgadas"}
+	mussarela := MenuItem{ID: 3, Name: "Mussarela", Price: 4000, Category: "Pizzas Salgadas"}
+	flavors := []Flavor{{camarao, 2}, {calabresa, 1}, {mussarela, 1}}
+
+	tests := []struct {
+		rule PizzaPricing
+		want int64
+	}{
+		{PricingHighest, 7000},
+		{PricingAverage, 5167},   // 15500 / 3
+		{PricingFractions, 5625}, // 7000/2 + 4500/4 + 4000/4
+	}
+	for _, tc := range tests {
+		if got := PriceFlavors(flavors, tc.rule); got != tc.want {
+			t.Errorf("PriceFlavors(%s) = %d, want %d", tc.rule, got, tc.want)
+		}
+	}
+	if got := FlavorFraction(flavors, 0); got != "1/2" {
+		t.Errorf("FlavorFraction = %q, want 1/2", got)
+	}
+}
+
+func TestAddPizza(t *testing.T) {
+	calabresa := MenuItem{ID: 1, Name: "Calabresa", Price: 4500, Category: "Pizzas Salgadas"}
+	portuguesa := MenuItem{ID: 2, Name: "Portuguesa", Price: 5300, Category: "Pizzas Salgadas"}
+	half := []Flavor{{calabresa, 1}, {portuguesa, 1}}
+
+	o := NewOrder(1)
+	if err := o.AddPizza(half, PricingHighest, 1, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.AddPizza(half, PricingHighest, 1, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.AddPizza([]Flavor{{portuguesa, 1}, {calabresa, 1}}, PricingHighest, 1, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(o.Items) != 2 || o.Items[0].Quantity != 2 {
+		t.Fatalf("items = %+v, want the same halves merged", o.Items)
+	}
+	oi := o.Items[0]
+	if oi.Item.Name != "Pizza 1/2 Calabresa + 1/2 Portuguesa" || oi.Item.Category != "Pizzas Salgadas" {
+		t.Errorf("composite item = %+v", oi.Item)
+	}
+	if o.Total() != 3*5300 {
+		t.Errorf("Total = %d, want %d", o.Total(), 3*5300)
+	}
+
+	if err := o.AddPizza(half[:1], PricingHighest, 1, "", nil); err == nil {
+		t.Error("a single flavor should be rejected")
+	}
+	five := []Flavor{{calabresa, 1}, {calabresa, 1}, {calabresa, 1}, {calabresa, 1}, {calabresa, 1}}
+	if err := o.AddPizza(five, PricingHighest, 1, "", nil); err == nil {
+		t.Error("five flavors should be rejected")
+	}
+}
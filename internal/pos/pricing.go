@@ -0,0 +1,226 @@
+package pos
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PriceRule changes the price of an item or category during a time window
+// on some days of the week, like a happy hour. It sets either a fixed
+// price or a percentage off.
+type PriceRule struct {
+	Name       string         `json:"name"`
+	Days       []time.Weekday `json:"days,omitempty"` // empty = every day
+	Start      string         `json:"start"`          // "17:00"
+	End        string         `json:"end"`            // "19:00", exclusive; before Start = past midnight
+	ItemID     int            `json:"item_id,omitempty"`
+	Category   string         `json:"category,omitempty"` // path.Match pattern
+	Price      int64          `json:"price,omitempty"`    // fixed price in centavos
+	PercentOff int            `json:"percent_off,omitempty"`
+}
+
+// now is the clock used when adding items; tests replace it.
+var now = time.Now
+
+// parseClock reads "17:00" as minutes after midnight.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("horario invalido %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// ActiveAt reports whether the rule applies at t. A window ending past
+// midnight belongs to the day it started.
+func (r PriceRule) ActiveAt(t time.Time) bool {
+	start, err1 := parseClock(r.Start)
+	end, err2 := parseClock(r.End)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+	switch {
+	case start < end:
+		if minute < start || minute >= end {
+			return false
+		}
+	case minute >= start:
+	case minute < end:
+		day = (day + 6) % 7
+	default:
+		return false
+	}
+	if len(r.Days) == 0 {
+		return true
+	}
+	for _, d := range r.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether the rule covers item.
+func (r PriceRule) Matches(item MenuItem) bool {
+	if r.ItemID != 0 {
+		return r.ItemID == item.ID
+	}
+	ok, _ := path.Match(r.Category, item.Category)
+	return r.Category != "" && ok
+}
+
+// Apply returns the price the rule charges for price.
+func (r PriceRule) Apply(price int64) int64 {
+	if r.PercentOff > 0 {
+		return (price*int64(100-r.PercentOff) + 50) / 100
+	}
+	return r.Price
+}
+
+// priced applies the cheapest rule active now to a line being added,
+// keeping the regular price and the rule name on it.
+func (o *Order) priced(line OrderItem) OrderItem {
+	t := now()
+	best := line.Item.Price
+	for _, r := range o.PriceRules {
+		if !r.Matches(line.Item) || !r.ActiveAt(t) {
+			continue
+		}
+		if p := r.Apply(line.Item.Price); p < best {
+			best = p
+			line.PriceRule = r.Name
+		}
+	}
+	if line.PriceRule != "" {
+		line.RegularPrice = line.Item.Price
+		line.Item.Price = best
+	}
+	return line
+}
+
+var weekdayNames = []string{"dom", "seg", "ter", "qua", "qui", "sex", "sab"}
+
+func parseWeekday(s string) (time.Weekday, error) {
+	for i, name := range weekdayNames {
+		if strings.EqualFold(strings.TrimSpace(s), name) {
+			return time.Weekday(i), nil
+		}
+	}
+	return 0, fmt.Errorf("dia invalido %q", s)
+}
+
+// parseDays reads "seg-sex", "sab,dom" or "todos".
+func parseDays(s string) ([]time.Weekday, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.EqualFold(s, "todos") {
+		return nil, nil
+	}
+	var days []time.Weekday
+	for _, part := range strings.Split(s, ",") {
+		from, to, isRange := strings.Cut(part, "-")
+		first, err := parseWeekday(from)
+		if err != nil {
+			return nil, err
+		}
+		last := first
+		if isRange {
+			if last, err = parseWeekday(to); err != nil {
+				return nil, err
+			}
+		}
+		for d := first; ; d = (d + 1) % 7 {
+			days = append(days, d)
+			if d == last {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+func formatDays(days []time.Weekday) string {
+	if len(days) == 0 {
+		return "todos"
+	}
+	names := make([]string, len(days))
+	for i, d := range days {
+		names[i] = weekdayNames[d]
+	}
+	return strings.Join(names, ",")
+}
+
+// ParsePriceRule reads the one-line form used by the menu editor:
+//
+//	Happy Hour | seg-sex | 17:00-19:00 | Chopp | 20%
+//	Rodizio de domingo | dom | 11:00-15:00 | Pizza Calabresa | 39,90
+//
+// The target is a menu item name or a category pattern; the price is a
+// fixed value or a percentage off.
+func (m *Menu) ParsePriceRule(line string) (PriceRule, error) {
+	fields := strings.Split(line, "|")
+	if len(fields) != 5 {
+		return PriceRule{}, fmt.Errorf("regra deve ter 5 campos separados por |: %q", line)
+	}
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	r := PriceRule{Name: fields[0]}
+	if r.Name == "" {
+		return PriceRule{}, fmt.Errorf("regra sem nome: %q", line)
+	}
+	var err error
+	if r.Days, err = parseDays(fields[1]); err != nil {
+		return PriceRule{}, fmt.Errorf("regra %s: %w", r.Name, err)
+	}
+	start, end, ok := strings.Cut(fields[2], "-")
+	if !ok {
+		return PriceRule{}, fmt.Errorf("regra %s: horario deve ser inicio-fim", r.Name)
+	}
+	if _, err := parseClock(start); err != nil {
+		return PriceRule{}, fmt.Errorf("regra %s: %w", r.Name, err)
+	}
+	if _, err := parseClock(end); err != nil {
+		return PriceRule{}, fmt.Errorf("regra %s: %w", r.Name, err)
+	}
+	r.Start, r.End = strings.TrimSpace(start), strings.TrimSpace(end)
+
+	if item, ok := m.itemByName(fields[3]); ok {
+		r.ItemID = item.ID
+	} else if m.hasCategory(fields[3]) {
+		r.Category = fields[3]
+	} else {
+		return PriceRule{}, fmt.Errorf("regra %s: item ou categoria desconhecido %q", r.Name, fields[3])
+	}
+
+	if pct, isPercent := strings.CutSuffix(fields[4], "%"); isPercent {
+		r.PercentOff, err = strconv.Atoi(strings.TrimSpace(pct))
+		if err != nil || r.PercentOff <= 0 || r.PercentOff > 100 {
+			return PriceRule{}, fmt.Errorf("regra %s: desconto invalido %q", r.Name, fields[4])
+		}
+	} else {
+		r.Price, err = parseSignedPrice(fields[4])
+		if err != nil || r.Price < 0 {
+			return PriceRule{}, fmt.Errorf("regra %s: preco invalido %q", r.Name, fields[4])
+		}
+	}
+	return r, nil
+}
+
+// FormatPriceRule is the inverse of ParsePriceRule.
+func (m *Menu) FormatPriceRule(r PriceRule) string {
+	target := r.Category
+	if item, ok := m.itemByID(r.ItemID); ok && r.ItemID != 0 {
+		target = item.Name
+	}
+	price := fmt.Sprintf("%d,%02d", r.Price/100, r.Price%100)
+	if r.PercentOff > 0 {
+		price = fmt.Sprintf("%d%%", r.PercentOff)
+	}
+	return fmt.Sprintf("%s | %s | %s-%s | %s | %s", r.Name, formatDays(r.Days), r.Start, r.End, target, price)
+}
@@ -0,0 +1,145 @@
+package pos
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestPriceRuleActiveAt(t *testing.T) {
+	weekdays := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	happy := PriceRule{Days: weekdays, Start: "17:00", End: "19:00"}
+	late := PriceRule{Days: []time.Weekday{time.Friday}, Start: "23:00", End: "02:00"}
+
+	// 2026-03-13 is a Friday.
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 3, day, hour, minute, 0, 0, time.Local)
+	}
+	tests := []struct {
+		rule PriceRule
+		t    time.Time
+		want bool
+	}{
+		{happy, at(13, 17, 0), true},
+		{happy, at(13, 18, 59), true},
+		{happy, at(13, 19, 0), false},
+		{happy, at(13, 16, 59), false},
+		{happy, at(14, 18, 0), false}, // Saturday
+		{late, at(13, 23, 30), true},
+		{late, at(14, 1, 30), true}, // still Friday night
+		{late, at(14, 2, 0), false},
+		{late, at(14, 23, 30), false},
+		{PriceRule{Start: "11:00", End: "15:00"}, at(15, 12, 0), true}, // every day
+	}
+	for _, tc := range tests {
+		if got := tc.rule.ActiveAt(tc.t); got != tc.want {
+			t.Errorf("%s-%s at %s = %v, want %v", tc.rule.Start, tc.rule.End, tc.t.Format("Mon 15:04"), got, tc.want)
+		}
+	}
+}
+
+func TestParsePriceRule(t *testing.T) {
+	m := comboMenu()
+	m.AddItem(MenuItem{Name: "Chopp 300ml", Price: 1200, Category: "Chopp"})
+
+	r, err := m.ParsePriceRule("Happy Hour | seg-sex | 17:00-19:00 | Chopp | 25%")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Category != "Chopp" || r.PercentOff != 25 || len(r.Days) != 5 || r.Days[0] != time.Monday {
+		t.Errorf("rule = %+v", r)
+	}
+	if got := m.FormatPriceRule(r); got != "Happy Hour | seg,ter,qua,qui,sex | 17:00-19:00 | Chopp | 25%" {
+		t.Errorf("FormatPriceRule = %q", got)
+	}
+
+	r, err = m.ParsePriceRule("Domingo | sab-dom | 11:00-15:00 | Calabresa | 39,90")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.ItemID != 1 || r.Price != 3990 || len(r.Days) != 2 || r.Days[1] != time.Sunday {
+		t.Errorf("rule = %+v", r)
+	}
+
+	for _, line := range []string{
+		"Faltando | seg | 17:00-19:00 | Chopp",
+		"Dia | feriado | 17:00-19:00 | Chopp | 10%",
+		"Hora | seg | 25:00-19:00 | Chopp | 10%",
+		"Alvo | seg | 17:00-19:00 | Feijoada | 10%",
+		"Desconto | seg | 17:00-19:00 | Chopp | 150%",
+	} {
+		if _, err := m.ParsePriceRule(line); err == nil {
+			t.Errorf("ParsePriceRule(%q) should fail", line)
+		}
+	}
+}
+
+func TestAddItemAppliesPriceRules(t *testing.T) {
+	defer func(saved func() time.Time) { now = saved }(now)
+	chopp := MenuItem{ID: 9, Name: "Chopp 300ml", Price: 1200, Category: "Chopp"}
+	o := NewOrder(1)
+	o.PriceRules = []PriceRule{
+		{Name: "Happy Hour", Start: "17:00", End: "19:00", Category: "Chopp", PercentOff: 25},
+		{Name: "Chopp em dobro", Start: "18:00", End: "19:00", ItemID: 9, Price: 850},
+	}
+
+	now = func() time.Time { return time.Date(2026, 3, 13, 16, 0, 0, 0, time.Local) }
+	o.AddItem(chopp, 1, "")
+	now = func() time.Time { return time.Date(2026, 3, 13, 17, 30, 0, 0, time.Local) }
+	o.AddItem(chopp, 2, "")
+	now = func() time.Time { return time.Date(2026, 3, 13, 18, 30, 0, 0, time.Local) }
+	o.AddItem(chopp, 1, "")
+
+	if len(o.Items) != 3 {
+		t.Fatalf("items = %+v, want one line per price", o.Items)
+	}
+	if o.Items[0].PriceRule != "" || o.Items[0].Item.Price != 1200 {
+		t.Errorf("before happy hour = %+v", o.Items[0])
+	}
+	if o.Items[1].PriceRule != "Happy Hour" || o.Items[1].Item.Price != 900 || o.Items[1].RegularPrice != 1200 {
+		t.Errorf("happy hour = %+v", o.Items[1])
+	}
+	if o.Items[2].PriceRule != "Chopp em dobro" || o.Items[2].Item.Price != 850 {
+		t.Errorf("cheapest rule should win: %+v", o.Items[2])
+	}
+
+	// The price charged survives a round trip without the rules.
+	data, err := json.Marshal(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved Order
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if saved.Subtotal() != 1200+2*900+850 || saved.Items[1].PriceRule != "Happy Hour" {
+		t.Errorf("saved order = %+v", saved.Items)
+	}
+}
+
+func TestIncrementItemRepricesNewUnits(t *testing.T) {
+	defer func(saved func() time.Time) { now = saved }(now)
+	chopp := MenuItem{ID: 9, Name: "Chopp 300ml", Price: 1200, Category: "Chopp"}
+	o := NewOrder(1)
+	o.PriceRules = []PriceRule{{Name: "Happy Hour", Start: "17:00", End: "19:00", Category: "Chopp", PercentOff: 25}}
+
+	now = func() time.Time { return time.Date(2026, 3, 13, 17, 30, 0, 0, time.Local) }
+	o.AddItem(chopp, 1, "")
+	o.IncrementItem(0)
+	if len(o.Items) != 1 || o.Items[0].Quantity != 2 {
+		t.Fatalf("items = %+v, want 2x at the happy hour price", o.Items)
+	}
+
+	now = func() time.Time { return time.Date(2026, 3, 13, 19, 30, 0, 0, time.Local) }
+	o.IncrementItem(0)
+	if len(o.Items) != 2 || o.Items[1].Quantity != 1 || o.Items[1].Item.Price != 1200 || o.Items[1].PriceRule != "" {
+		t.Fatalf("items = %+v, want the new unit at the regular price", o.Items)
+	}
+	o.IncrementItem(0)
+	if len(o.Items) != 2 || o.Items[0].Quantity != 2 || o.Items[1].Quantity != 2 {
+		t.Errorf("items = %+v, want the unit joined to the regular price line", o.Items)
+	}
+	if o.Subtotal() != 2*900+2*1200 {
+		t.Errorf("subtotal = %d, want %d", o.Subtotal(), 2*900+2*1200)
+	}
+}
@@ -0,0 +1,156 @@
+package pos
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RefundItem is a number of units of one order line given back.
+type RefundItem struct {
+	Line     int `json:"line"` // index in Order.Items
+	Quantity int `json:"quantity"`
+}
+
+// Refund reverses all or part of a finalized order (estorno). It is booked
+// against the order, so the day and cash session of the sale show it.
+type Refund struct {
+	Items        []RefundItem  `json:"items"`
+	Amount       int64         `json:"amount"`            // centavos given back
+	Service      int64         `json:"service,omitempty"` // part of Amount that was service charge
+	Method       PaymentMethod `json:"method"`
+	Reason       string        `json:"reason"`
+	AuthorizedBy string        `json:"authorized_by,omitempty"` // manager who typed the PIN
+	SessionID    int           `json:"session_id,omitempty"`    // cash session that paid it out
+	OperatorID   int           `json:"operator_id,omitempty"`   // operator who made it
+	Time         time.Time     `json:"time"`
+}
+
+// RefundedUnits is how many units of line were already given back.
+func (o *Order) RefundedUnits(line int) int {
+	n := 0
+	for _, r := range o.Refunds {
+		for _, ri := range r.Items {
+			if ri.Line == line {
+				n += ri.Quantity
+			}
+		}
+	}
+	return n
+}
+
+// RefundedTotal sums the refunds of the order.
+func (o *Order) RefundedTotal() int64 {
+	var total int64
+	for _, r := range o.Refunds {
+		total += r.Amount
+	}
+	return total
+}
+
+func (o *Order) refundedService() int64 {
+	var total int64
+	for _, r := range o.Refunds {
+		total += r.Service
+	}
+	return total
+}
+
+// NetTotal is what the customer paid after refunds.
+func (o *Order) NetTotal() int64 {
+	return o.Total() - o.RefundedTotal()
+}
+
+// WasPaid reports whether the order was paid, even if later cancelled.
+func (o *Order) WasPaid() bool {
+	return o.Status == StatusFinalizado || len(o.Refunds) > 0
+}
+
+// RemainingItems lists the units not yet refunded, line by line.
+func (o *Order) RemainingItems() []RefundItem {
+	var items []RefundItem
+	for i, oi := range o.Items {
+		if n := oi.Quantity - o.RefundedUnits(i); n > 0 {
+			items = append(items, RefundItem{Line: i, Quantity: n})
+		}
+	}
+	return items
+}
+
+// RefundAmount is the value of items after the order's discount and with
+// its service charge, and the service part of it. Refunding every
+// remaining unit gives back exactly what is left, so rounding never adds
+// up to more than was paid.
+func (o *Order) RefundAmount(items []RefundItem) (amount, service int64) {
+	remaining := 0
+	for _, ri := range o.RemainingItems() {
+		remaining += ri.Quantity
+	}
+	units := 0
+	var value int64
+	for _, ri := range items {
+		units += ri.Quantity
+		value += o.Items[ri.Line].UnitPrice() * int64(ri.Quantity)
+	}
+	if units == remaining {
+		return o.NetTotal(), o.ServiceCharge() - o.refundedService()
+	}
+	subtotal := o.Subtotal()
+	if subtotal == 0 {
+		return 0, 0
+	}
+	amount = (value*o.Total()*2 + subtotal) / (2 * subtotal)
+	service = (value*o.ServiceCharge()*2 + subtotal) / (2 * subtotal)
+	return min(amount, o.NetTotal()), service
+}
+
+// Refund gives back the units in r.Items of a finalized order, filling in
+// the amount and time. Once every unit is given back the order becomes
+// cancelled.
+func (o *Order) Refund(r Refund) (Refund, error) {
+	if o.Status != StatusFinalizado {
+		return Refund{}, fmt.Errorf("pedido #%d nao esta finalizado", o.Number)
+	}
+	r.Reason = strings.TrimSpace(r.Reason)
+	if r.Reason == "" {
+		return Refund{}, fmt.Errorf("informe o motivo do estorno")
+	}
+	if len(r.Items) == 0 {
+		return Refund{}, fmt.Errorf("escolha os itens a estornar")
+	}
+	requested := map[int]int{}
+	for _, ri := range r.Items {
+		if ri.Line < 0 || ri.Line >= len(o.Items) || ri.Quantity <= 0 {
+			return Refund{}, fmt.Errorf("item invalido para estorno")
+		}
+		requested[ri.Line] += ri.Quantity
+		if left := o.Items[ri.Line].Quantity - o.RefundedUnits(ri.Line); requested[ri.Line] > left {
+			return Refund{}, fmt.Errorf("%s: so restam %d para estornar", o.Items[ri.Line].Item.Name, left)
+		}
+	}
+	r.Amount, r.Service = o.RefundAmount(r.Items)
+	r.Time = time.Now()
+	o.Refunds = append(o.Refunds, r)
+	if len(o.RemainingItems()) == 0 {
+		o.Status = StatusCancelado
+	}
+	return r, nil
+}
+
+// CancelPaid cancels a finalized order, refunding everything not given
+// back yet.
+func (o *Order) CancelPaid(r Refund) (Refund, error) {
+	r.Items = o.RemainingItems()
+	return o.Refund(r)
+}
+
+// netItems is the order's lines without the refunded units.
+func (o *Order) netItems() []OrderItem {
+	var items []OrderItem
+	for i, oi := range o.Items {
+		if oi.Quantity -= o.RefundedUnits(i); oi.Quantity > 0 {
+			items = append(items, oi)
+		}
+	}
+	return items
+}
@@ -0,0 +1,92 @@
+package pos
+
+import "testing"
+
+func refundTestOrder() *Order {
+	o := NewOrder(7)
+	o.AddItem(MenuItem{ID: 1, Name: "Pizza", Price: 5000}, 2, "")
+	o.AddItem(MenuItem{ID: 2, Name: "Refri", Price: 1000}, 1, "")
+	o.Discount = 1000
+	o.ServicePercent = 10
+	o.Finalize(PaymentCartao)
+	o.SessionID = 1
+	return o
+}
+
+func TestPartialRefund(t *testing.T) {
+	o := refundTestOrder()
+	// Subtotal 110, discount 10, service 10: total 110.
+	if o.Total() != 11000 {
+		t.Fatalf("total = %d", o.Total())
+	}
+
+	if _, err := o.Refund(Refund{Items: []RefundItem{{Line: 0, Quantity: 1}}, Method: PaymentCartao}); err == nil {
+		t.Error("a refund without reason should fail")
+	}
+	if _, err := o.Refund(Refund{Items: []RefundItem{{Line: 1, Quantity: 2}}, Reason: "x"}); err == nil {
+		t.Error("refunding more units than ordered should fail")
+	}
+
+	r, err := o.Refund(Refund{Items: []RefundItem{{Line: 0, Quantity: 1}}, Method: PaymentCartao, Reason: "pizza fria"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 50 of 110 subtotal, scaled to the 110 total: 50,00, of which 4,55 service.
+	if r.Amount != 5000 || r.Service != 455 {
+		t.Errorf("refund = %d (service %d), want 5000 (455)", r.Amount, r.Service)
+	}
+	if o.Status != StatusFinalizado || o.NetTotal() != 6000 {
+		t.Errorf("status %s, net %d after a partial refund", o.Status, o.NetTotal())
+	}
+
+	r, err = o.CancelPaid(Refund{Method: PaymentCartao, Reason: "cliente desistiu"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Amount != 6000 || len(r.Items) != 2 {
+		t.Errorf("cancel refund = %+v, want the remaining 6000", r)
+	}
+	if o.Status != StatusCancelado || o.NetTotal() != 0 {
+		t.Errorf("status %s, net %d after cancelling", o.Status, o.NetTotal())
+	}
+	if o.ServiceCharge()-o.refundedService() != 0 {
+		t.Errorf("service left = %d", o.ServiceCharge()-o.refundedService())
+	}
+	if _, err := o.CancelPaid(Refund{Reason: "de novo"}); err == nil {
+		t.Error("a cancelled order cannot be refunded again")
+	}
+}
+
+func TestDaySummaryReversesRefunds(t *testing.T) {
+	partial := refundTestOrder()
+	if _, err := partial.Refund(Refund{Items: []RefundItem{{Line: 1, Quantity: 1}}, Method: PaymentDinheiro, Reason: "errado", SessionID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	cancelled := refundTestOrder()
+	if _, err := cancelled.CancelPaid(Refund{Method: PaymentCartao, Reason: "erro de lancamento", SessionID: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := ComputeDaySummary("2024-03-08", []Order{*partial, *cancelled})
+	// Refri refund: 10 of 110 scaled to 110 = 10,00.
+	if s.TotalRevenue != 10000 {
+		t.Errorf("revenue = %d, want 10000", s.TotalRevenue)
+	}
+	if s.FinalizedOrders != 1 || s.CancelledOrders != 1 {
+		t.Errorf("finalized %d, cancelled %d", s.FinalizedOrders, s.CancelledOrders)
+	}
+	if s.Refunds != 2 || s.RefundTotal != 12000 {
+		t.Errorf("refunds %d totalling %d", s.Refunds, s.RefundTotal)
+	}
+	if s.ByPayment[PaymentCartao] != 11000 || s.ByPayment[PaymentDinheiro] != -1000 {
+		t.Errorf("by payment = %v", s.ByPayment)
+	}
+	if len(s.Items) != 1 || s.Items[0].Name != "Pizza" || s.Items[0].Quantity != 2 {
+		t.Errorf("items = %+v, want only the 2 pizzas kept", s.Items)
+	}
+
+	session := NewCashSession(1, 0, "Ana")
+	if cash := session.ExpectedCash([]Order{*partial, *cancelled}); cash != -1000 {
+		t.Errorf("expected cash = %d, want the refund taken out", cash)
+	}
+}
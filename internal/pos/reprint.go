@@ -0,0 +1,27 @@
+package pos
+
+import "time"
+
+// ReprintKind is the document printed again for a stored order.
+type ReprintKind string
+
+const (
+	ReprintReceipt ReprintKind = "Recibo"
+	ReprintKitchen ReprintKind = "Comanda"
+)
+
+// Reprint records a second copy (2a via) printed after the order closed,
+// so repeated copies can be audited.
+type Reprint struct {
+	Kind       ReprintKind `json:"kind"`
+	OperatorID int         `json:"operator_id,omitempty"` // operator who asked for it
+	Time       time.Time   `json:"time"`
+}
+
+// RecordReprint notes a copy of kind printed now by operatorID and returns
+// it.
+func (o *Order) RecordReprint(kind ReprintKind, operatorID int) Reprint {
+	r := Reprint{Kind: kind, OperatorID: operatorID, Time: time.Now()}
+	o.Reprints = append(o.Reprints, r)
+	return r
+}
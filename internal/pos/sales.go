@@ -0,0 +1,50 @@
+package pos
+
+import "sort"
+
+// ItemSales is how much of one item a day sold. Items sold in combos
+// count under their own name, with their share of the combo price.
+type ItemSales struct {
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+	Revenue  int64  `json:"revenue"` // centavos, before order discount and service
+}
+
+// itemSales adds up the items of the orders, best sellers first.
+func itemSales(orders []Order) []ItemSales {
+	index := map[string]int{}
+	var sales []ItemSales
+	add := func(name string, qty int, revenue int64) {
+		i, ok := index[name]
+		if !ok {
+			i = len(sales)
+			index[name] = i
+			sales = append(sales, ItemSales{Name: name})
+		}
+		sales[i].Quantity += qty
+		sales[i].Revenue += revenue
+	}
+
+	for _, o := range orders {
+		for _, oi := range o.Items {
+			if !oi.IsCombo() {
+				add(oi.Item.Name, oi.Quantity, oi.Total())
+				continue
+			}
+			// The combo price is shared in proportion to the components'
+			// own prices.
+			weights := make([]int64, len(oi.Components))
+			for i, c := range oi.Components {
+				weights[i] = c.Total()
+			}
+			shares := distribute(oi.Total(), weights)
+			for i, c := range oi.Components {
+				add(c.Item.Name, c.Quantity*oi.Quantity, shares[i])
+			}
+		}
+	}
+	sort.SliceStable(sales, func(i, j int) bool {
+		return sales[i].Quantity > sales[j].Quantity
+	})
+	return sales
+}
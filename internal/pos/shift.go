@@ -0,0 +1,110 @@
+package pos
+
+import "time"
+
+// BusinessDate returns the business day ("2026-03-14") t belongs to. Hours
+// before cutoffHour count as the previous day, so a pizzeria closing at
+// 2am with a cutoff of 4 books its late orders on the evening they began.
+func BusinessDate(t time.Time, cutoffHour int) string {
+	return t.Add(-time.Duration(cutoffHour) * time.Hour).Format("2006-01-02")
+}
+
+// BusinessDayBounds returns the [start, end) interval of a business day.
+func BusinessDayBounds(isoDate string, cutoffHour int, loc *time.Location) (time.Time, time.Time, error) {
+	day, err := time.ParseInLocation("2006-01-02", isoDate, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	start := day.Add(time.Duration(cutoffHour) * time.Hour)
+	return start, start.AddDate(0, 0, 1), nil
+}
+
+type ShiftReportKind string
+
+const (
+	ReportX ShiftReportKind = "X" // leitura X: partial, can be issued any time
+	ReportZ ShiftReportKind = "Z" // reducao Z: issued once when the shift closes
+)
+
+// ShiftReport aggregates the orders and cash movements of one cash
+// session (shift). X reports describe the open session so far and do not
+// change anything; Z reports describe a closed session and carry a
+// sequential number.
+type ShiftReport struct {
+	Kind         ShiftReportKind `json:"kind"`
+	Number       int             `json:"number,omitempty"` // Z sequence number
+	SessionID    int             `json:"session_id"`
+	BusinessDate string          `json:"business_date"`
+	From         time.Time       `json:"from"`
+	To           time.Time       `json:"to"`
+	Operator     string          `json:"operator,omitempty"`
+	Summary      DaySummary      `json:"summary"`
+	OpeningFloat int64           `json:"opening_float"`
+	Suprimentos  int64           `json:"suprimentos"`
+	Sangrias     int64           `json:"sangrias"`
+	ExpectedCash int64           `json:"expected_cash"`
+
+	DrawerOpenings int `json:"drawer_openings,omitempty"` // opened outside of a sale
+}
+
+// NewShiftReport builds the report of a session from its orders. An open
+// session yields an X report up to now; a closed one yields its Z report.
+// Only the refunds the session paid out count, as in CashSession.Sales,
+// so a Z report reprinted after later sessions refund its sales still
+// matches the one issued at closing.
+func NewShiftReport(session *CashSession, orders []Order, cutoffHour int) ShiftReport {
+	var own []Order
+	for _, o := range orders {
+		if o.SessionID == session.ID {
+			own = append(own, o.refundedBy(session.ID))
+		}
+	}
+
+	r := ShiftReport{
+		Kind:         ReportX,
+		SessionID:    session.ID,
+		BusinessDate: BusinessDate(session.OpenedAt, cutoffHour),
+		From:         session.OpenedAt,
+		To:           time.Now(),
+		Operator:     session.OpenedBy,
+		OpeningFloat: session.OpeningFloat,
+		Suprimentos:  session.MovementTotal(MovementSuprimento),
+		Sangrias:     session.MovementTotal(MovementSangria),
+		ExpectedCash: session.ExpectedCash(own),
+
+		DrawerOpenings: len(session.DrawerOpenings),
+	}
+	r.Summary = ComputeDaySummary(r.BusinessDate, own)
+
+	if !session.IsOpen() {
+		r.Kind = ReportZ
+		r.Number = session.ZNumber
+		r.To = session.ClosedAt
+		if session.ClosedBy != "" {
+			r.Operator = session.ClosedBy
+		}
+		if expected, ok := session.Expected[PaymentDinheiro]; ok {
+			r.ExpectedCash = expected
+		}
+	}
+	return r
+}
+
+// refundedBy is o with only the refunds session paid out. An order that
+// later sessions cancelled is still a sale for this one.
+func (o Order) refundedBy(session int) Order {
+	var refunds []Refund
+	for _, r := range o.Refunds {
+		if r.SessionID == session {
+			refunds = append(refunds, r)
+		}
+	}
+	if len(refunds) == len(o.Refunds) {
+		return o
+	}
+	o.Refunds = refunds
+	if o.Status == StatusCancelado && len(o.RemainingItems()) > 0 {
+		o.Status = StatusFinalizado
+	}
+	return o
+}
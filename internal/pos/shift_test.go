@@ -0,0 +1,91 @@
+package pos
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBusinessDate(t *testing.T) {
+	tests := []struct {
+		at     time.Time
+		cutoff int
+		want   string
+	}{
+		{time.Date(2026, 3, 14, 23, 30, 0, 0, time.Local), 4, "2026-03-14"},
+		{time.Date(2026, 3, 15, 1, 45, 0, 0, time.Local), 4, "2026-03-14"},
+		{time.Date(2026, 3, 15, 4, 0, 0, 0, time.Local), 4, "2026-03-15"},
+		{time.Date(2026, 3, 15, 1, 45, 0, 0, time.Local), 0, "2026-03-15"},
+	}
+	for _, tc := range tests {
+		if got := BusinessDate(tc.at, tc.cutoff); got != tc.want {
+			t.Errorf("BusinessDate(%s, %d) = %s, want %s", tc.at.Format("01-02 15:04"), tc.cutoff, got, tc.want)
+		}
+	}
+}
+
+func TestShiftReportXAndZ(t *testing.T) {
+	s := NewCashSession(2, 10000, "Ana")
+	s.OpenedAt = time.Date(2026, 3, 14, 18, 0, 0, 0, time.Local)
+	orders := cashTestOrders(2)
+
+	x := NewShiftReport(s, orders, 4)
+	if x.Kind != ReportX || x.Number != 0 {
+		t.Errorf("open session should give an unnumbered X report, got %s #%d", x.Kind, x.Number)
+	}
+	if x.BusinessDate != "2026-03-14" {
+		t.Errorf("BusinessDate = %s, want 2026-03-14", x.BusinessDate)
+	}
+	// Only the two finalized orders of session 2 count as sales.
+	if x.Summary.FinalizedOrders != 2 || x.Summary.TotalRevenue != 8000 {
+		t.Errorf("summary = %d orders / %d, want 2 / 8000", x.Summary.FinalizedOrders, x.Summary.TotalRevenue)
+	}
+	if x.ExpectedCash != 16000 {
+		t.Errorf("ExpectedCash = %d, want 16000", x.ExpectedCash)
+	}
+	if !s.IsOpen() {
+		t.Error("an X report must not close the session")
+	}
+
+	if err := s.Close(map[PaymentMethod]int64{PaymentDinheiro: 16000}, "Bruno", orders); err != nil {
+		t.Fatal(err)
+	}
+	s.ZNumber = 7
+	z := NewShiftReport(s, orders, 4)
+	if z.Kind != ReportZ || z.Number != 7 {
+		t.Errorf("closed session should give Z #7, got %s #%d", z.Kind, z.Number)
+	}
+	if !z.To.Equal(s.ClosedAt) || z.Operator != "Bruno" {
+		t.Errorf("Z report should end at the closing by Bruno, got %s by %q", z.To, z.Operator)
+	}
+}
+
+func TestZReportIgnoresLaterRefunds(t *testing.T) {
+	s := NewCashSession(2, 10000, "Ana")
+	orders := cashTestOrders(2)
+	if err := s.Close(map[PaymentMethod]int64{PaymentDinheiro: 16000}, "Ana", orders); err != nil {
+		t.Fatal(err)
+	}
+	s.ZNumber = 3
+	issued := NewShiftReport(s, orders, 4)
+
+	// The next session cancels a cash sale of this one and refunds the
+	// split sale by card.
+	if _, err := orders[0].CancelPaid(Refund{Method: PaymentDinheiro, Reason: "reclamacao", SessionID: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := orders[1].Refund(Refund{Items: []RefundItem{{Line: 0, Quantity: 1}}, Method: PaymentCartao, Reason: "troca", SessionID: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	reprint := NewShiftReport(s, orders, 4)
+	if !reflect.DeepEqual(reprint.Summary, issued.Summary) || reprint.ExpectedCash != issued.ExpectedCash {
+		t.Errorf("reprinted Z = %+v, want the one issued at closing %+v", reprint.Summary, issued.Summary)
+	}
+	sales := s.Sales(orders)
+	for _, m := range PaymentMethods() {
+		if reprint.Summary.ByPayment[m] != sales[m] {
+			t.Errorf("Z %s = %d, session sales %d", m, reprint.Summary.ByPayment[m], sales[m])
+		}
+	}
+}
@@ -0,0 +1,335 @@
+package pos
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// SubBillItem is the part of an order line charged to one sub-bill: either
+// some of its units, or Part/Shares of the whole line when it is shared.
+type SubBillItem struct {
+	Item       MenuItem    `json:"item"`
+	Quantity   int         `json:"quantity"`
+	Modifiers  []Modifier  `json:"modifiers,omitempty"`
+	Components []OrderItem `json:"components,omitempty"`
+	Part       int         `json:"part,omitempty"` // shares of the line charged here; 0 = 1
+	Shares     int         `json:"shares"`         // shares the line was divided in; 1 = whole units
+	Amount     int64       `json:"amount"`         // centavos
+}
+
+// Fraction is the part of a shared line charged to the sub-bill, as in
+// "1/3"; it is empty when whole units are charged.
+func (si SubBillItem) Fraction() string {
+	if si.Shares <= 1 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", max(si.Part, 1), si.Shares)
+}
+
+// SubBill is the share of an order paid by one person when the bill is
+// split. Each sub-bill is paid on its own.
+type SubBill struct {
+	Number       int            `json:"number"`          // 1-based
+	Items        []SubBillItem  `json:"items,omitempty"` // empty when split evenly
+	Subtotal     int64          `json:"subtotal"`        // items before discount and service
+	Discount     int64          `json:"discount"`        // share of the order discount
+	Service      int64          `json:"service"`         // share of the service charge
+	Amount       int64          `json:"amount"`          // to pay
+	Payments     []PaymentSplit `json:"payments,omitempty"`
+	CashReceived int64          `json:"cash_received,omitempty"`
+	PaidAt       time.Time      `json:"paid_at,omitempty"`
+}
+
+func (b SubBill) clone() SubBill {
+	if b.Items != nil {
+		items := make([]SubBillItem, len(b.Items))
+		for i, si := range b.Items {
+			si.Modifiers = slices.Clone(si.Modifiers)
+			si.Components = cloneItems(si.Components)
+			items[i] = si
+		}
+		b.Items = items
+	}
+	b.Payments = slices.Clone(b.Payments)
+	return b
+}
+
+func (b *SubBill) IsPaid() bool {
+	return !b.PaidAt.IsZero()
+}
+
+func (b *SubBill) cashPortion() int64 {
+	var cash int64
+	for _, p := range b.Payments {
+		if p.Method == PaymentDinheiro {
+			cash += p.Amount
+		}
+	}
+	return cash
+}
+
+func (b *SubBill) CashChange() int64 {
+	if change := b.CashReceived - b.cashPortion(); change > 0 && b.cashPortion() > 0 {
+		return change
+	}
+	return 0
+}
+
+// DivideEvenly splits amount into n parts that add up to it exactly. The
+// leftover centavos go one each to the first parts.
+func DivideEvenly(amount int64, n int) []int64 {
+	parts := make([]int64, n)
+	if n <= 0 {
+		return parts
+	}
+	base, rest := amount/int64(n), amount%int64(n)
+	for i := range parts {
+		parts[i] = base
+		if int64(i) < rest {
+			parts[i]++
+		}
+	}
+	return parts
+}
+
+// distribute splits amount in proportion to weights, adding up exactly.
+// The centavos lost to rounding go one each to the first weighted parts.
+func distribute(amount int64, weights []int64) []int64 {
+	var total int64
+	for _, w := range weights {
+		total += w
+	}
+	if total <= 0 {
+		return DivideEvenly(amount, len(weights))
+	}
+	parts := make([]int64, len(weights))
+	left := amount
+	for i, w := range weights {
+		parts[i] = amount * w / total
+		left -= parts[i]
+	}
+	for i := 0; left > 0; i = (i + 1) % len(parts) {
+		if weights[i] > 0 {
+			parts[i]++
+			left--
+		}
+	}
+	return parts
+}
+
+func (o *Order) checkSplittable(n int) error {
+	if o.Status != StatusAberto {
+		return fmt.Errorf("pedido #%d nao esta aberto", o.Number)
+	}
+	if len(o.Items) == 0 {
+		return fmt.Errorf("pedido #%d sem itens", o.Number)
+	}
+	if n < 2 {
+		return fmt.Errorf("divida a conta em pelo menos 2 partes")
+	}
+	if o.HasPaidSubBills() {
+		return fmt.Errorf("pedido #%d ja tem contas pagas", o.Number)
+	}
+	return nil
+}
+
+// SplitEvenly divides the order total among n people.
+func (o *Order) SplitEvenly(n int) error {
+	if err := o.checkSplittable(n); err != nil {
+		return err
+	}
+	subtotals := DivideEvenly(o.Subtotal(), n)
+	discounts := DivideEvenly(o.EffectiveDiscount(), n)
+	services := DivideEvenly(o.ServiceCharge(), n)
+	bills := make([]SubBill, n)
+	for i := range bills {
+		bills[i] = SubBill{Number: i + 1, Subtotal: subtotals[i], Discount: discounts[i], Service: services[i]}
+		bills[i].Amount = subtotals[i] - discounts[i] + services[i]
+	}
+	o.SubBills = bills
+	return nil
+}
+
+// SplitByItems divides the order among n people by item: shares[i][b] is
+// what sub-bill b (0-based) takes of line i. When a line's shares add up
+// to its quantity they are units, so 2 and 1 of 3 beers charge two beers
+// to one bill and one to the other; otherwise the line total is divided
+// in proportion to them, 1 and 1 being half each. The discount and service
+// charge are shared in proportion to each sub-bill's items.
+func (o *Order) SplitByItems(n int, shares [][]int) error {
+	if err := o.checkSplittable(n); err != nil {
+		return err
+	}
+	if len(shares) != len(o.Items) {
+		return fmt.Errorf("divisao com %d itens, pedido tem %d", len(shares), len(o.Items))
+	}
+
+	bills := make([]SubBill, n)
+	for i := range bills {
+		bills[i].Number = i + 1
+	}
+	for i, oi := range o.Items {
+		if len(shares[i]) > n {
+			return fmt.Errorf("item %s: conta %d inexistente", oi.Item.Name, len(shares[i]))
+		}
+		weights := make([]int64, len(shares[i]))
+		total := 0
+		for b, w := range shares[i] {
+			if w < 0 {
+				return fmt.Errorf("item %s: parte negativa na conta %d", oi.Item.Name, b+1)
+			}
+			weights[b] = int64(w)
+			total += w
+		}
+		if total == 0 {
+			return fmt.Errorf("item %s sem pagante", oi.Item.Name)
+		}
+		units := total == oi.Quantity
+		amounts := distribute(oi.Total(), weights)
+		for b, w := range shares[i] {
+			if w == 0 {
+				continue
+			}
+			si := SubBillItem{
+				Item:       oi.Item,
+				Quantity:   oi.Quantity,
+				Modifiers:  oi.Modifiers,
+				Components: oi.Components,
+				Part:       w,
+				Shares:     total,
+				Amount:     amounts[b],
+			}
+			if units {
+				si.Quantity, si.Part, si.Shares = w, 0, 1
+			}
+			bills[b].Items = append(bills[b].Items, si)
+			bills[b].Subtotal += amounts[b]
+		}
+	}
+
+	weights := make([]int64, n)
+	for i, b := range bills {
+		if len(b.Items) == 0 {
+			return fmt.Errorf("conta %d sem itens", b.Number)
+		}
+		weights[i] = b.Subtotal
+	}
+	discounts := distribute(o.EffectiveDiscount(), weights)
+	services := distribute(o.ServiceCharge(), weights)
+	for i := range bills {
+		bills[i].Discount = discounts[i]
+		bills[i].Service = services[i]
+		bills[i].Amount = bills[i].Subtotal - discounts[i] + services[i]
+	}
+	o.SubBills = bills
+	return nil
+}
+
+// ClearSplit undoes the split while nothing has been paid.
+func (o *Order) ClearSplit() error {
+	if o.HasPaidSubBills() {
+		return fmt.Errorf("pedido #%d ja tem contas pagas", o.Number)
+	}
+	o.SubBills = nil
+	return nil
+}
+
+func (o *Order) HasPaidSubBills() bool {
+	for _, b := range o.SubBills {
+		if b.IsPaid() {
+			return true
+		}
+	}
+	return false
+}
+
+// AllSubBillsPaid reports whether a split order is fully paid.
+func (o *Order) AllSubBillsPaid() bool {
+	if len(o.SubBills) == 0 {
+		return false
+	}
+	for _, b := range o.SubBills {
+		if !b.IsPaid() {
+			return false
+		}
+	}
+	return true
+}
+
+// SplitOutdated reports whether the order changed after it was split, so
+// the sub-bills no longer add up to the total.
+func (o *Order) SplitOutdated() bool {
+	var sum int64
+	for _, b := range o.SubBills {
+		sum += b.Amount
+	}
+	return len(o.SubBills) > 0 && sum != o.Total()
+}
+
+// PaySubBill records the payment of sub-bill i (0-based). The payments
+// must add up to its amount; cashReceived, when given, must cover the
+// cash part.
+func (o *Order) PaySubBill(i int, payments []PaymentSplit, cashReceived int64) error {
+	if i < 0 || i >= len(o.SubBills) {
+		return fmt.Errorf("conta %d inexistente", i+1)
+	}
+	if o.SplitOutdated() {
+		return fmt.Errorf("pedido #%d foi alterado depois da divisao", o.Number)
+	}
+	b := &o.SubBills[i]
+	if b.IsPaid() {
+		return fmt.Errorf("conta %d ja foi paga", b.Number)
+	}
+	var sum int64
+	for _, p := range payments {
+		sum += p.Amount
+	}
+	if sum != b.Amount {
+		return fmt.Errorf("pagamentos (%s) diferem do valor da conta %d (%s)", FormatBRL(sum), b.Number, FormatBRL(b.Amount))
+	}
+
+	b.Payments = payments
+	b.CashReceived = 0
+	if cash := b.cashPortion(); cash > 0 && cashReceived > 0 {
+		if cashReceived < cash {
+			b.Payments = nil
+			return fmt.Errorf("valor recebido (%s) menor que o pagamento em dinheiro (%s)", FormatBRL(cashReceived), FormatBRL(cash))
+		}
+		b.CashReceived = cashReceived
+	}
+	b.PaidAt = time.Now()
+	return nil
+}
+
+// FinalizeSubBills finalizes a split order once every sub-bill is paid,
+// with the payments of all sub-bills added up per method.
+func (o *Order) FinalizeSubBills() error {
+	if !o.AllSubBillsPaid() {
+		return fmt.Errorf("pedido #%d tem contas em aberto", o.Number)
+	}
+	byMethod := make(map[PaymentMethod]int64)
+	var received int64
+	for _, b := range o.SubBills {
+		for _, p := range b.Payments {
+			byMethod[p.Method] += p.Amount
+		}
+		if b.CashReceived > 0 {
+			received += b.CashReceived
+		} else {
+			received += b.cashPortion()
+		}
+	}
+	var payments []PaymentSplit
+	for _, m := range PaymentMethods() {
+		if byMethod[m] > 0 {
+			payments = append(payments, PaymentSplit{Method: m, Amount: byMethod[m]})
+		}
+	}
+	o.CashReceived = 0
+	if byMethod[PaymentDinheiro] > 0 {
+		o.CashReceived = received
+	}
+	o.FinalizeSplit(payments)
+	return nil
+}
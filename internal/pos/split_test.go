@@ -0,0 +1,161 @@
+package pos
+
+import (
+	"reflect"
+	"testing"
+)
+
+func splitTestOrder() *Order {
+	o := NewOrder(7)
+	o.AddItem(MenuItem{ID: 1, Name: "Pizza Grande", Price: 6000}, 1, "")
+	o.AddItem(MenuItem{ID: 2, Name: "Chopp", Price: 1200}, 2, "")
+	o.AddItem(MenuItem{ID: 3, Name: "Refrigerante", Price: 700}, 1, "")
+	return o
+}
+
+func TestDivideEvenly(t *testing.T) {
+	if got := DivideEvenly(10000, 3); !reflect.DeepEqual(got, []int64{3334, 3333, 3333}) {
+		t.Errorf("DivideEvenly(10000, 3) = %v", got)
+	}
+	if got := DivideEvenly(10, 4); !reflect.DeepEqual(got, []int64{3, 3, 2, 2}) {
+		t.Errorf("DivideEvenly(10, 4) = %v", got)
+	}
+}
+
+func TestSplitEvenly(t *testing.T) {
+	o := splitTestOrder() // 9100
+	o.Discount = 100
+	if err := o.SplitEvenly(4); err != nil {
+		t.Fatal(err)
+	}
+	var sum int64
+	for _, b := range o.SubBills {
+		sum += b.Amount
+	}
+	if sum != o.Total() || o.SubBills[0].Amount != 2250 {
+		t.Errorf("sub-bills %+v should add up to %d", o.SubBills, o.Total())
+	}
+	if err := o.SplitEvenly(1); err == nil {
+		t.Error("splitting in 1 should fail")
+	}
+}
+
+func TestSplitByItems(t *testing.T) {
+	o := splitTestOrder()
+	o.Discount = 910 // 10%
+	// Pizza shared by all three, chopps to person 1, soda to person 3.
+	err := o.SplitByItems(3, [][]int{{1, 1, 1}, {2}, {0, 0, 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantSubtotals := []int64{2000 + 2400, 2000, 2000 + 700}
+	var sum int64
+	for i, b := range o.SubBills {
+		if b.Subtotal != wantSubtotals[i] {
+			t.Errorf("bill %d subtotal = %d, want %d", b.Number, b.Subtotal, wantSubtotals[i])
+		}
+		sum += b.Amount
+	}
+	if sum != o.Total() {
+		t.Errorf("sub-bills add up to %d, want %d", sum, o.Total())
+	}
+	if o.SubBills[1].Amount != 1800 || o.SubBills[1].Items[0].Fraction() != "1/3" {
+		t.Errorf("bill 2 = %+v, want a third of the pizza less 10%%", o.SubBills[1])
+	}
+
+	if err := o.SplitByItems(3, [][]int{{1}, {2}, {1}}); err == nil {
+		t.Error("a sub-bill without items should be rejected")
+	}
+	if err := o.SplitByItems(3, [][]int{{1}, {0, 0}, {1}}); err == nil {
+		t.Error("an item without payers should be rejected")
+	}
+	if err := o.SplitByItems(3, [][]int{{1, 1, 1, 1}, {2}, {1}}); err == nil {
+		t.Error("a share for a fourth bill should be rejected")
+	}
+}
+
+func TestSplitByItemsUnits(t *testing.T) {
+	o := NewOrder(8)
+	o.AddItem(MenuItem{ID: 2, Name: "Chopp", Price: 1200}, 3, "")
+	o.AddItem(MenuItem{ID: 1, Name: "Pizza Grande", Price: 6000}, 1, "")
+	// Two of the three chopps to person 1, one to person 2; pizza 2/3 to 1/3.
+	if err := o.SplitByItems(2, [][]int{{2, 1}, {2, 1}}); err != nil {
+		t.Fatal(err)
+	}
+
+	chopp := o.SubBills[0].Items[0]
+	if chopp.Quantity != 2 || chopp.Fraction() != "" || chopp.Amount != 2400 {
+		t.Errorf("bill 1 chopp = %+v, want 2 whole units for 2400", chopp)
+	}
+	if got := o.SubBills[1].Items[0]; got.Quantity != 1 || got.Amount != 1200 {
+		t.Errorf("bill 2 chopp = %+v, want 1 unit for 1200", got)
+	}
+	pizza := o.SubBills[0].Items[1]
+	if pizza.Fraction() != "2/3" || pizza.Amount != 4000 {
+		t.Errorf("bill 1 pizza = %+v, want 2/3 for 4000", pizza)
+	}
+	if o.SubBills[0].Amount != 6400 || o.SubBills[1].Amount != 3200 {
+		t.Errorf("bills = %d and %d, want 6400 and 3200", o.SubBills[0].Amount, o.SubBills[1].Amount)
+	}
+}
+
+func TestPaySubBills(t *testing.T) {
+	o := splitTestOrder()
+	if err := o.SplitEvenly(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.PaySubBill(0, []PaymentSplit{{PaymentDinheiro, 4550}}, 5000); err != nil {
+		t.Fatal(err)
+	}
+	if c := o.SubBills[0].CashChange(); c != 450 {
+		t.Errorf("change = %d, want 450", c)
+	}
+	if err := o.PaySubBill(0, []PaymentSplit{{PaymentPix, 4550}}, 0); err == nil {
+		t.Error("paying a sub-bill twice should fail")
+	}
+	if err := o.SplitEvenly(3); err == nil {
+		t.Error("re-splitting after a payment should fail")
+	}
+	if err := o.FinalizeSubBills(); err == nil {
+		t.Error("finalizing with an unpaid sub-bill should fail")
+	}
+	if err := o.PaySubBill(1, []PaymentSplit{{PaymentCartao, 4000}}, 0); err == nil {
+		t.Error("a payment short of the sub-bill amount should fail")
+	}
+	if err := o.PaySubBill(1, []PaymentSplit{{PaymentCartao, 4550}}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := o.FinalizeSubBills(); err != nil {
+		t.Fatal(err)
+	}
+	if o.Status != StatusFinalizado || len(o.Payments) != 2 {
+		t.Fatalf("order = %s with %v, want finalized with two payments", o.Status, o.Payments)
+	}
+	if o.CashChange() != 450 {
+		t.Errorf("order change = %d, want 450", o.CashChange())
+	}
+}
+
+func TestSplitWithServiceCharge(t *testing.T) {
+	o := splitTestOrder() // 9100
+	o.Discount = 100
+	o.ServicePercent = 10 // 900
+	if err := o.SplitByItems(2, [][]int{{1, 1}, {0, 2}, {1}}); err != nil {
+		t.Fatal(err)
+	}
+	var service, discount, sum int64
+	for _, b := range o.SubBills {
+		if b.Amount != b.Subtotal-b.Discount+b.Service {
+			t.Errorf("bill %d amount %d does not match its parts", b.Number, b.Amount)
+		}
+		service += b.Service
+		discount += b.Discount
+		sum += b.Amount
+	}
+	if service != o.ServiceCharge() || discount != 100 || sum != o.Total() {
+		t.Errorf("shares add up to service %d, discount %d, total %d; want %d, 100, %d",
+			service, discount, sum, o.ServiceCharge(), o.Total())
+	}
+}
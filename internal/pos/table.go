@@ -0,0 +1,187 @@
+package pos
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Table is one numbered table of the floor layout.
+type Table struct {
+	Number int    `json:"number"`
+	Area   string `json:"area"` // "Salao", "Varanda", ...
+}
+
+type TableStatus string
+
+const (
+	TableLivre   TableStatus = "Livre"
+	TableOcupada TableStatus = "Ocupada"
+	TableConta   TableStatus = "Conta" // bill requested, awaiting payment
+)
+
+// TableState is a table together with the open orders seated at it.
+type TableState struct {
+	Table  Table
+	Status TableStatus
+	Orders []*Order
+	Total  int64     // running total of all its orders
+	Since  time.Time // when the oldest order was opened
+}
+
+// Elapsed is how long the table has been occupied.
+func (s TableState) Elapsed(now time.Time) time.Duration {
+	if s.Since.IsZero() {
+		return 0
+	}
+	return now.Sub(s.Since)
+}
+
+// TableNumber parses the order's Table field as a table number.
+func (o *Order) TableNumber() (int, bool) {
+	n, err := strconv.Atoi(strings.TrimSpace(o.Table))
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// TableStates matches open orders to the layout. A table is awaiting
+// payment once every order at it has asked for the bill.
+func TableStates(tables []Table, open []*Order) []TableState {
+	states := make([]TableState, len(tables))
+	index := make(map[int]int, len(tables))
+	for i, t := range tables {
+		states[i] = TableState{Table: t, Status: TableLivre}
+		index[t.Number] = i
+	}
+
+	for _, o := range open {
+		n, ok := o.TableNumber()
+		if !ok {
+			continue
+		}
+		i, ok := index[n]
+		if !ok {
+			continue
+		}
+		s := &states[i]
+		s.Orders = append(s.Orders, o)
+		s.Total += o.Total()
+		if s.Since.IsZero() || o.CreatedAt.Before(s.Since) {
+			s.Since = o.CreatedAt
+		}
+	}
+
+	for i := range states {
+		s := &states[i]
+		if len(s.Orders) == 0 {
+			continue
+		}
+		s.Status = TableConta
+		for _, o := range s.Orders {
+			if !o.BillRequested {
+				s.Status = TableOcupada
+				break
+			}
+		}
+	}
+	return states
+}
+
+// TableAreas lists the areas of the layout in order of first appearance.
+func TableAreas(tables []Table) []string {
+	seen := map[string]bool{}
+	var areas []string
+	for _, t := range tables {
+		if !seen[t.Area] {
+			seen[t.Area] = true
+			areas = append(areas, t.Area)
+		}
+	}
+	return areas
+}
+
+// Merge moves the items of other into o, as when two tables are joined.
+// Discounts add up and o keeps the earlier opening time and its own
+// service charge; other is left empty. A split nobody paid yet is dropped
+// from both, since it no longer adds up, and orders with paid sub-bills
+// cannot be merged at all.
+func (o *Order) Merge(other *Order) error {
+	for _, x := range []*Order{o, other} {
+		if x.HasPaidSubBills() {
+			return fmt.Errorf("pedido #%d tem contas pagas", x.Number)
+		}
+	}
+	for _, oi := range other.Items {
+		o.addLine(oi)
+	}
+	o.KitchenCancels = append(o.KitchenCancels, other.TakeKitchenCancellations()...)
+	if o.Discount == 0 {
+		o.DiscountByID = other.DiscountByID
+	}
+	o.Discount += other.Discount
+	if strings.TrimSpace(o.Customer) == "" {
+		o.Customer = other.Customer
+	}
+	if other.CreatedAt.Before(o.CreatedAt) {
+		o.CreatedAt = other.CreatedAt
+	}
+	o.BillRequested = o.BillRequested && other.BillRequested
+	o.SubBills = nil
+	other.Items = nil
+	other.Discount = 0
+	other.SubBills = nil
+	return nil
+}
+
+// ParseTableNumbers reads a list of table numbers and ranges such as
+// "1-10, 15".
+func ParseTableNumbers(spec string) ([]int, error) {
+	var numbers []int
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		from, to, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil || first <= 0 {
+			return nil, fmt.Errorf("mesa invalida: %q", part)
+		}
+		last := first
+		if isRange {
+			last, err = strconv.Atoi(strings.TrimSpace(to))
+			if err != nil || last < first {
+				return nil, fmt.Errorf("faixa de mesas invalida: %q", part)
+			}
+		}
+		for n := first; n <= last; n++ {
+			numbers = append(numbers, n)
+		}
+	}
+	return numbers, nil
+}
+
+// FormatTableNumbers is the inverse of ParseTableNumbers, collapsing
+// consecutive numbers into ranges.
+func FormatTableNumbers(numbers []int) string {
+	sorted := append([]int(nil), numbers...)
+	sort.Ints(sorted)
+	var parts []string
+	for i := 0; i < len(sorted); {
+		j := i
+		for j+1 < len(sorted) && sorted[j+1] == sorted[j]+1 {
+			j++
+		}
+		if j > i {
+			parts = append(parts, fmt.Sprintf("%d-%d", sorted[i], sorted[j]))
+		} else {
+			parts = append(parts, strconv.Itoa(sorted[i]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ", ")
+}
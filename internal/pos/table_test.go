@@ -0,0 +1,128 @@
+package pos
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTableStates(t *testing.T) {
+	tables := []Table{{1, "Salao"}, {2, "Salao"}, {10, "Varanda"}}
+	opened := time.Date(2026, 3, 14, 20, 0, 0, 0, time.Local)
+
+	a := NewOrder(1)
+	a.Table = "2"
+	a.CreatedAt = opened
+	a.AddItem(MenuItem{ID: 1, Name: "Chopp", Price: 1200}, 2, "")
+	b := NewOrder(2)
+	b.Table = " 2 "
+	b.CreatedAt = opened.Add(10 * time.Minute)
+	b.AddItem(MenuItem{ID: 2, Name: "Pizza", Price: 6000}, 1, "")
+	c := NewOrder(3)
+	c.Table = "10"
+	c.CreatedAt = opened
+	c.BillRequested = true
+	balcao := NewOrder(4)
+	balcao.Customer = "Ana"
+
+	states := TableStates(tables, []*Order{a, b, c, balcao})
+	if states[0].Status != TableLivre {
+		t.Errorf("table 1 = %s, want %s", states[0].Status, TableLivre)
+	}
+	if s := states[1]; s.Status != TableOcupada || len(s.Orders) != 2 || s.Total != 8400 {
+		t.Errorf("table 2 = %s with %d orders / %d, want Ocupada with 2 / 8400", s.Status, len(s.Orders), s.Total)
+	}
+	if got := states[1].Elapsed(opened.Add(45 * time.Minute)); got != 45*time.Minute {
+		t.Errorf("Elapsed = %s, want 45m", got)
+	}
+	if states[2].Status != TableConta {
+		t.Errorf("table 10 = %s, want %s", states[2].Status, TableConta)
+	}
+	if got := TableAreas(tables); !reflect.DeepEqual(got, []string{"Salao", "Varanda"}) {
+		t.Errorf("TableAreas = %v", got)
+	}
+}
+
+func TestOrderMerge(t *testing.T) {
+	chopp := MenuItem{ID: 1, Name: "Chopp", Price: 1200}
+	dst := NewOrder(1)
+	dst.AddItem(chopp, 1, "")
+	src := NewOrder(2)
+	src.Customer = "Bruno"
+	src.CreatedAt = dst.CreatedAt.Add(-time.Hour)
+	src.AddItem(chopp, 2, "")
+	src.AddItem(MenuItem{ID: 2, Name: "Fritas", Price: 3000}, 1, "sem sal")
+	src.Discount = 500
+
+	if err := dst.Merge(src); err != nil {
+		t.Fatal(err)
+	}
+	if len(dst.Items) != 2 || dst.Items[0].Quantity != 3 {
+		t.Errorf("merged items = %+v, want 3x Chopp and Fritas", dst.Items)
+	}
+	if dst.Total() != 3600+3000-500 {
+		t.Errorf("Total = %d, want %d", dst.Total(), 3600+3000-500)
+	}
+	if dst.Customer != "Bruno" || !dst.CreatedAt.Equal(src.CreatedAt) {
+		t.Errorf("merged order should take the customer and earlier opening time")
+	}
+	if len(src.Items) != 0 || src.Discount != 0 {
+		t.Error("source order should be left empty")
+	}
+}
+
+func TestOrderMergeWithSplits(t *testing.T) {
+	chopp := MenuItem{ID: 1, Name: "Chopp", Price: 1200}
+	dst := NewOrder(1)
+	dst.AddItem(chopp, 2, "")
+	dst.ServicePercent = 10
+	if err := dst.SplitEvenly(2); err != nil {
+		t.Fatal(err)
+	}
+	src := NewOrder(2)
+	src.AddItem(chopp, 2, "")
+	src.ServicePercent = 12
+	if err := src.SplitEvenly(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.PaySubBill(0, []PaymentSplit{{PaymentPix, src.SubBills[0].Amount}}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := dst.Merge(src); err == nil {
+		t.Fatal("merging an order with paid sub-bills should fail")
+	}
+	if len(dst.Items) != 1 || dst.Items[0].Quantity != 2 || len(src.SubBills) != 2 {
+		t.Fatal("a refused merge should leave both orders as they were")
+	}
+
+	src.SubBills[0].PaidAt = time.Time{}
+	if err := dst.Merge(src); err != nil {
+		t.Fatal(err)
+	}
+	if dst.SubBills != nil || src.SubBills != nil {
+		t.Error("unpaid splits should be dropped by the merge")
+	}
+	if dst.ServicePercent != 10 || dst.Total() != 4800+480 {
+		t.Errorf("merged order charges %d%%, total %d; want the target's 10%%, %d", dst.ServicePercent, dst.Total(), 4800+480)
+	}
+}
+
+func TestParseTableNumbers(t *testing.T) {
+	got, err := ParseTableNumbers("1-4, 7,10 - 11")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []int{1, 2, 3, 4, 7, 10, 11}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseTableNumbers = %v, want %v", got, want)
+	}
+	if s := FormatTableNumbers(got); s != "1-4, 7, 10-11" {
+		t.Errorf("FormatTableNumbers = %q", s)
+	}
+	for _, bad := range []string{"a", "5-2", "0"} {
+		if _, err := ParseTableNumbers(bad); err == nil {
+			t.Errorf("ParseTableNumbers(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package printer
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// BarcodeKind selects the symbology for GS k (function B).
+type BarcodeKind byte
+
+const (
+	BarcodeEAN13   BarcodeKind = 67
+	BarcodeITF     BarcodeKind = 70
+	BarcodeCode128 BarcodeKind = 73
+)
+
+// HRI (human readable interpretation) positions for GS H.
+type HRIPosition byte
+
+const (
+	HRINone  HRIPosition = 0
+	HRIAbove HRIPosition = 1
+	HRIBelow HRIPosition = 2
+	HRIBoth  HRIPosition = 3
+)
+
+// BarcodeHeight sets the bar height in dots (GS h).
+func (rb *ReceiptBuilder) BarcodeHeight(dots byte) *ReceiptBuilder {
+	if dots == 0 {
+		dots = 1
+	}
+	rb.buf.Write([]byte{0x1D, 0x68, dots})
+	return rb
+}
+
+// BarcodeWidth sets the narrow module width, 2 to 6 dots (GS w).
+func (rb *ReceiptBuilder) BarcodeWidth(dots byte) *ReceiptBuilder {
+	if dots < 2 {
+		dots = 2
+	}
+	if dots > 6 {
+		dots = 6
+	}
+	rb.buf.Write([]byte{0x1D, 0x77, dots})
+	return rb
+}
+
+// BarcodeHRI sets where the digits are printed relative to the bars (GS H).
+func (rb *ReceiptBuilder) BarcodeHRI(pos HRIPosition) *ReceiptBuilder {
+	rb.buf.Write([]byte{0x1D, 0x48, byte(pos)})
+	return rb
+}
+
+// Barcode prints data in the given symbology using the height, width and
+// HRI settings currently in effect. Data the symbology cannot encode is
+// logged and skipped rather than sent, since the printer would otherwise
+// print garbage or swallow the following bytes.
+func (rb *ReceiptBuilder) Barcode(kind BarcodeKind, data string) *ReceiptBuilder {
+	payload, err := barcodePayload(kind, data)
+	if err != nil {
+		log.Printf("Codigo de barras ignorado: %v", err)
+		return rb
+	}
+	rb.buf.Write([]byte{0x1D, 0x6B, byte(kind), byte(len(payload))})
+	rb.buf.Write(payload)
+	return rb
+}
+
+// ValidateBarcode reports whether data can be printed in the symbology.
+func ValidateBarcode(kind BarcodeKind, data string) error {
+	_, err := barcodePayload(kind, data)
+	return err
+}
+
+func barcodePayload(kind BarcodeKind, data string) ([]byte, error) {
+	switch kind {
+	case BarcodeEAN13:
+		if !isDigits(data) || (len(data) != 12 && len(data) != 13) {
+			return nil, fmt.Errorf("EAN-13 exige 12 ou 13 digitos: %q", data)
+		}
+		if len(data) == 13 && ean13CheckDigit(data[:12]) != data[12] {
+			return nil, fmt.Errorf("EAN-13 com digito verificador invalido: %q", data)
+		}
+		// The printer computes the check digit itself.
+		return []byte(data[:12]), nil
+
+	case BarcodeITF:
+		if !isDigits(data) || len(data) == 0 || len(data)%2 != 0 {
+			return nil, fmt.Errorf("ITF exige quantidade par de digitos: %q", data)
+		}
+		return []byte(data), nil
+
+	case BarcodeCode128:
+		return code128Payload(data)
+	}
+	return nil, fmt.Errorf("tipo de codigo de barras desconhecido: %d", kind)
+}
+
+// code128Payload prefixes the code set selector. Even-length numeric data
+// uses code set C (two digits per symbol, half the width); anything else
+// uses code set B, where a literal '{' is escaped as "{{".
+func code128Payload(data string) ([]byte, error) {
+	if data == "" {
+		return nil, fmt.Errorf("CODE128 vazio")
+	}
+
+	var payload []byte
+	if isDigits(data) && len(data)%2 == 0 {
+		payload = append(payload, '{', 'C')
+		for i := 0; i < len(data); i += 2 {
+			payload = append(payload, (data[i]-'0')*10+(data[i+1]-'0'))
+		}
+	} else {
+		for i := 0; i < len(data); i++ {
+			if data[i] < 0x20 || data[i] > 0x7E {
+				return nil, fmt.Errorf("CODE128 aceita apenas ASCII imprimivel: %q", data)
+			}
+		}
+		payload = append(payload, '{', 'B')
+		payload = append(payload, strings.ReplaceAll(data, "{", "{{")...)
+	}
+
+	if len(payload) > 255 {
+		return nil, fmt.Errorf("CODE128 longo demais: %d bytes", len(payload))
+	}
+	return payload, nil
+}
+
+func ean13CheckDigit(digits12 string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		d := int(digits12[i] - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// OrderBarcode returns the CODE128 content identifying an order on
+// printed receipts.
+func OrderBarcode(number int) string {
+	return fmt.Sprintf("%06d", number)
+}
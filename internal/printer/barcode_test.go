@@ -0,0 +1,81 @@
+package printer
+
+import (
+	"bytes"
+	"testing"
+
+	"notinha/internal/pos"
+)
+
+func TestBarcodePayloads(t *testing.T) {
+	tests := []struct {
+		kind BarcodeKind
+		data string
+		want []byte
+	}{
+		{BarcodeCode128, "000042", []byte{'{', 'C', 0, 0, 42}},
+		{BarcodeCode128, "A{1", []byte("{BA{{1")},
+		{BarcodeCode128, "12345", []byte("{B12345")},
+		{BarcodeEAN13, "789100031550", []byte("789100031550")},
+		{BarcodeEAN13, "7891000315507", []byte("789100031550")},
+		{BarcodeITF, "1234", []byte("1234")},
+	}
+	for _, tc := range tests {
+		got, err := barcodePayload(tc.kind, tc.data)
+		if err != nil {
+			t.Errorf("barcodePayload(%d, %q) error: %v", tc.kind, tc.data, err)
+			continue
+		}
+		if !bytes.Equal(got, tc.want) {
+			t.Errorf("barcodePayload(%d, %q) = %q, want %q", tc.kind, tc.data, got, tc.want)
+		}
+	}
+}
+
+func TestBarcodeRejectsInvalidData(t *testing.T) {
+	invalid := []struct {
+		kind BarcodeKind
+		data string
+	}{
+		{BarcodeEAN13, "12345"},
+		{BarcodeEAN13, "7891000315508"}, // wrong check digit
+		{BarcodeEAN13, "78910003155a"},
+		{BarcodeITF, "123"},
+		{BarcodeCode128, ""},
+		{BarcodeCode128, "Pão"},
+	}
+	for _, tc := range invalid {
+		if err := ValidateBarcode(tc.kind, tc.data); err == nil {
+			t.Errorf("ValidateBarcode(%d, %q) should fail", tc.kind, tc.data)
+		}
+	}
+
+	rb := &ReceiptBuilder{}
+	rb.Barcode(BarcodeITF, "123")
+	if len(rb.Build()) != 0 {
+		t.Error("invalid barcode should not emit any bytes")
+	}
+}
+
+func TestBarcodeCommand(t *testing.T) {
+	rb := &ReceiptBuilder{}
+	rb.BarcodeHeight(80).BarcodeWidth(9).BarcodeHRI(HRIBelow).Barcode(BarcodeCode128, "0042")
+
+	want := []byte{
+		0x1D, 0x68, 80,
+		0x1D, 0x77, 6, // width clamped to 6
+		0x1D, 0x48, 2,
+		0x1D, 0x6B, 73, 4, '{', 'C', 0, 42,
+	}
+	if got := rb.Build(); !bytes.Equal(got, want) {
+		t.Errorf("command = % X, want % X", got, want)
+	}
+}
+
+func TestReceiptHasOrderBarcode(t *testing.T) {
+	receipt := BuildReceipt(pixTestData(pos.PaymentDinheiro))
+	want := []byte{0x1D, 0x6B, byte(BarcodeCode128), 5, '{', 'C', 0, 0, 42}
+	if !bytes.Contains(receipt, want) {
+		t.Error("receipt should end with the order number as a CODE128 barcode")
+	}
+}
@@ -0,0 +1,122 @@
+package printer
+
+import (
+	"fmt"
+
+	"notinha/internal/pos"
+	"notinha/internal/storage"
+)
+
+// CashClosingData holds a closed cash session for the closing receipt.
+type CashClosingData struct {
+	Restaurant   storage.RestaurantInfo
+	Session      *pos.CashSession
+	CharsPerLine int
+}
+
+// BuildCashClosingReceipt prints the cash session closing ("fechamento de
+// caixa"): opening float, movements, and expected vs counted per payment
+// method, with signature lines.
+func BuildCashClosingReceipt(data CashClosingData) []byte {
+	w := data.CharsPerLine
+	if w <= 0 {
+		w = 48
+	}
+	s := data.Session
+
+	rb := NewReceiptBuilder()
+
+	rb.AlignCenter()
+	writeTitle(rb, data.Restaurant.Name, w)
+	rb.Separator('-', w)
+	writeTitle(rb, "FECHAMENTO DE CAIXA", w)
+	rb.Line(fmt.Sprintf("Caixa #%d", s.ID))
+	rb.Separator('-', w)
+
+	rb.AlignLeft()
+	rb.Line(formatTotalLine("Abertura:", formatDateTime(s.OpenedAt), w))
+	if s.OpenedBy != "" {
+		rb.Line(formatTotalLine("Aberto por:", s.OpenedBy, w))
+	}
+	if !s.ClosedAt.IsZero() {
+		rb.Line(formatTotalLine("Fechamento:", formatDateTime(s.ClosedAt), w))
+	}
+	if s.ClosedBy != "" {
+		rb.Line(formatTotalLine("Fechado por:", s.ClosedBy, w))
+	}
+	rb.Separator('-', w)
+
+	rb.Line(formatTotalLine("Fundo de troco:", pos.FormatBRL(s.OpeningFloat), w))
+	writeCashMovements(rb, s, w)
+
+	rb.Separator('-', w)
+	for _, m := range pos.PaymentMethods() {
+		if s.Expected[m] == 0 && s.Counted[m] == 0 {
+			continue
+		}
+		rb.Bold().Line(string(m)).NoBold()
+		rb.Line(formatTotalLine("  Esperado:", pos.FormatBRL(s.Expected[m]), w))
+		rb.Line(formatTotalLine("  Contado:", pos.FormatBRL(s.Counted[m]), w))
+		rb.Line(formatTotalLine("  Diferenca:", formatSignedBRL(s.Difference(m)), w))
+	}
+	rb.Separator('-', w)
+
+	diff := s.TotalDifference()
+	label := "DIFERENCA:"
+	switch {
+	case diff > 0:
+		label = "SOBRA:"
+	case diff < 0:
+		label = "FALTA:"
+	}
+	rb.Bold().
+		Line(formatTotalLine(label, formatSignedBRL(diff), w)).
+		NoBold()
+
+	rb.Feed(3).
+		AlignCenter().
+		Line("________________________________").
+		Line("Operador").
+		Feed(2).
+		Line("________________________________").
+		Line("Gerente").
+		AlignLeft()
+
+	rb.Feed(4).PartialCut()
+
+	return rb.Build()
+}
+
+func writeCashMovements(rb *ReceiptBuilder, s *pos.CashSession, w int) {
+	if len(s.Movements) == 0 {
+		return
+	}
+	rb.Separator('-', w)
+	rb.AlignCenter().Bold().Line("MOVIMENTACOES").NoBold().AlignLeft()
+	for _, m := range s.Movements {
+		amount := pos.FormatBRL(m.Amount)
+		if m.Kind == pos.MovementSangria {
+			amount = "-" + amount
+		}
+		rb.Line(formatTotalLine(fmt.Sprintf("%s %s", m.Time.Format("15:04"), m.Kind), amount, w))
+		detail := m.Reason
+		if m.Operator != "" {
+			detail += " (" + m.Operator + ")"
+		}
+		rb.Row(noteTable(w), "  *", detail)
+	}
+	rb.Line(formatTotalLine("Total suprimentos:", pos.FormatBRL(s.MovementTotal(pos.MovementSuprimento)), w))
+	rb.Line(formatTotalLine("Total sangrias:", "-"+pos.FormatBRL(s.MovementTotal(pos.MovementSangria)), w))
+}
+
+// formatSignedBRL formats an amount with an explicit sign, so a shortage
+// prints as "-R$ 5,00" and a surplus as "+R$ 5,00".
+func formatSignedBRL(centavos int64) string {
+	switch {
+	case centavos > 0:
+		return "+" + pos.FormatBRL(centavos)
+	case centavos < 0:
+		return "-" + pos.FormatBRL(-centavos)
+	}
+	return pos.FormatBRL(0)
+}
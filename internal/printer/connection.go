@@ -13,6 +13,31 @@ type Printer struct {
 	mu     sync.Mutex
 }
 
+// Open opens a connection to the printer at the given device path.
+// Paths starting with "tcp://" use the network transport, "file://" the
+// virtual printer; anything else is handed to the platform transport (USB
+// device file or Windows spooler).
+func Open(devicePath string) (*Printer, error) {
+	if IsNetworkPath(devicePath) {
+		return openNetwork(devicePath)
+	}
+	if IsFilePath(devicePath) {
+		return openFile(devicePath)
+	}
+	return openDevice(devicePath)
+}
+
+// DetectPrinters returns the locally attached printers followed by any
+// network printers answering on the raw printing port in subnet.
+// An empty subnet skips the network scan.
+func DetectPrinters(subnet string) []string {
+	printers := detectDevices()
+	if subnet != "" {
+		printers = append(printers, ScanNetwork(subnet, DefaultNetworkPort)...)
+	}
+	return printers
+}
+
 // Close closes the printer connection.
 func (p *Printer) Close() error {
 	p.mu.Lock()
@@ -69,6 +94,39 @@ func (p *Printer) OpenDrawer() error {
 	return p.Print(rb)
 }
 
+// Status asks the printer for its real-time state (paper, cover, drawer).
+// It returns ErrStatusUnsupported when the transport is write-only.
+func (p *Printer) Status() (PrinterStatus, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.device == nil {
+		return PrinterStatus{}, fmt.Errorf("impressora nao conectada")
+	}
+	q, ok := p.device.(statusQuerier)
+	if !ok {
+		return PrinterStatus{}, ErrStatusUnsupported
+	}
+	return q.queryStatus()
+}
+
+// SetCharsPerLine sets the paper width used by transports that render what
+// they receive, such as the virtual printer's text copy.
+func (p *Printer) SetCharsPerLine(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if fw, ok := p.device.(*FileWriter); ok {
+		fw.setCharsPerLine(n)
+	}
+}
+
+// IsVirtual reports whether jobs are saved to files instead of printed.
+func (p *Printer) IsVirtual() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, ok := p.device.(*FileWriter)
+	return ok
+}
+
 // IsConnected returns true if the printer device is open.
 func (p *Printer) IsConnected() bool {
 	p.mu.Lock()
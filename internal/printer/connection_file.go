@@ -0,0 +1,128 @@
+package printer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileScheme is the device path prefix that selects the virtual printer,
+// e.g. "file:///home/caixa/cupons". Every job is saved in that directory
+// instead of being printed.
+const FileScheme = "file://"
+
+// IsFilePath reports whether devicePath selects the virtual printer.
+func IsFilePath(devicePath string) bool {
+	return strings.HasPrefix(devicePath, FileScheme)
+}
+
+// fileDirectory extracts the directory from a file:// device path.
+// "file:///C:/cupons" is accepted on Windows.
+func fileDirectory(devicePath string) (string, error) {
+	dir := strings.TrimPrefix(devicePath, FileScheme)
+	if len(dir) >= 3 && dir[0] == '/' && dir[2] == ':' {
+		dir = dir[1:]
+	}
+	if dir == "" {
+		return "", fmt.Errorf("pasta vazia em %q", devicePath)
+	}
+	return filepath.FromSlash(dir), nil
+}
+
+// FileWriter is a virtual printer that stores each write as one job: the
+// raw ESC/POS bytes in a .bin file and the rendered preview in a .txt file
+// with the same timestamped name. It keeps an electronic copy of
+// everything printed and lets the app run without a printer attached.
+type FileWriter struct {
+	dir          string
+	charsPerLine int
+	now          func() time.Time
+	mu           sync.Mutex
+}
+
+func openFile(devicePath string) (*Printer, error) {
+	dir, err := fileDirectory(devicePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("criar pasta %s: %w", dir, err)
+	}
+	fw := &FileWriter{dir: dir, charsPerLine: 48, now: time.Now}
+	return &Printer{device: fw, path: devicePath}, nil
+}
+
+// Dir returns the directory where jobs are saved.
+func (fw *FileWriter) Dir() string {
+	return fw.dir
+}
+
+func (fw *FileWriter) setCharsPerLine(n int) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	if n > 0 {
+		fw.charsPerLine = n
+	}
+}
+
+func (fw *FileWriter) Write(p []byte) (int, error) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	base, err := fw.createJob(p)
+	if err != nil {
+		return 0, err
+	}
+	text := ParsePreview(p, fw.charsPerLine).Text()
+	if err := os.WriteFile(base+".txt", []byte(text), 0644); err != nil {
+		return len(p), fmt.Errorf("salvar %s.txt: %w", base, err)
+	}
+	return len(p), nil
+}
+
+// createJob writes data to a new timestamped .bin file and returns its
+// path without extension. Jobs saved in the same millisecond get a
+// numeric suffix instead of overwriting each other.
+func (fw *FileWriter) createJob(data []byte) (string, error) {
+	t := fw.now()
+	stamp := fmt.Sprintf("%s-%03d", t.Format("2006-01-02_15-04-05"), t.Nanosecond()/int(time.Millisecond))
+	for n := 1; ; n++ {
+		base := filepath.Join(fw.dir, stamp)
+		if n > 1 {
+			base = fmt.Sprintf("%s_%d", base, n)
+		}
+		f, err := os.OpenFile(base+".bin", os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("salvar trabalho em %s: %w", fw.dir, err)
+		}
+		_, err = f.Write(data)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return "", fmt.Errorf("salvar %s.bin: %w", base, err)
+		}
+		return base, nil
+	}
+}
+
+// queryStatus reports the virtual printer online while its directory
+// exists, so the spooler holds jobs if it is removed or unmounted.
+func (fw *FileWriter) queryStatus() (PrinterStatus, error) {
+	info, err := os.Stat(fw.dir)
+	if err != nil || !info.IsDir() {
+		return PrinterStatus{}, nil
+	}
+	return PrinterStatus{Online: true}, nil
+}
+
+func (fw *FileWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,77 @@
+package printer
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileDirectory(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"file:///tmp/cupons", filepath.FromSlash("/tmp/cupons")},
+		{"file:///C:/cupons", filepath.FromSlash("C:/cupons")},
+		{"file://cupons", "cupons"},
+	}
+	for _, tc := range tests {
+		got, err := fileDirectory(tc.path)
+		if err != nil || got != tc.want {
+			t.Errorf("fileDirectory(%q) = %q, %v; want %q", tc.path, got, err, tc.want)
+		}
+	}
+	if _, err := fileDirectory("file://"); err == nil {
+		t.Error("empty file:// path should be rejected")
+	}
+}
+
+func TestFilePrinterSavesJobs(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cupons")
+	p, err := Open(FileScheme + filepath.ToSlash(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	p.SetCharsPerLine(32)
+
+	fw := p.device.(*FileWriter)
+	fixed := time.Date(2026, 10, 17, 14, 30, 5, 123e6, time.Local)
+	fw.now = func() time.Time { return fixed }
+
+	job := NewReceiptBuilder().AlignCenter().Line("Pão de queijo").PartialCut().Build()
+	for i := 0; i < 2; i++ {
+		if err := p.Write(job); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, name := range []string{"2026-10-17_14-30-05-123", "2026-10-17_14-30-05-123_2"} {
+		bin, err := os.ReadFile(filepath.Join(dir, name+".bin"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(bin, job) {
+			t.Errorf("%s.bin should hold the raw job bytes", name)
+		}
+		txt, err := os.ReadFile(filepath.Join(dir, name+".txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(txt), "         Pão de queijo\n") {
+			t.Errorf("%s.txt = %q, want centered text on 32 columns", name, txt)
+		}
+	}
+
+	status, err := p.Status()
+	if err != nil || !status.Ready() {
+		t.Errorf("virtual printer should be ready, got %v, %v", status, err)
+	}
+	os.RemoveAll(dir)
+	if status, _ := p.Status(); status.Ready() {
+		t.Error("virtual printer should go offline when its folder is removed")
+	}
+}
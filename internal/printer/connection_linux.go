@@ -8,10 +8,17 @@ import (
 	"path/filepath"
 )
 
-// Open opens a connection to the printer at the given device path.
+// openDevice opens a connection to the printer at the given device path.
 // It sends an ESC/POS init command to verify the printer is physically present.
-func Open(devicePath string) (*Printer, error) {
-	f, err := os.OpenFile(devicePath, os.O_WRONLY, 0)
+// The device is opened read-write when the driver allows it, so status
+// requests can be answered; otherwise it falls back to write-only.
+func openDevice(devicePath string) (*Printer, error) {
+	readable := true
+	f, err := os.OpenFile(devicePath, os.O_RDWR, 0)
+	if err != nil {
+		readable = false
+		f, err = os.OpenFile(devicePath, os.O_WRONLY, 0)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("abrir impressora %s: %w", devicePath, err)
 	}
@@ -21,11 +28,25 @@ func Open(devicePath string) (*Printer, error) {
 		return nil, fmt.Errorf("impressora nao respondeu em %s: %w", devicePath, err)
 	}
 
-	return &Printer{device: f, path: devicePath}, nil
+	return &Printer{device: &usbDevice{File: f, readable: readable}, path: devicePath}, nil
+}
+
+// usbDevice is a /dev/usb/lp* handle. usblp supports reads from
+// bidirectional printers, which is how status answers come back.
+type usbDevice struct {
+	*os.File
+	readable bool
+}
+
+func (d *usbDevice) queryStatus() (PrinterStatus, error) {
+	if !d.readable {
+		return PrinterStatus{}, ErrStatusUnsupported
+	}
+	return queryRealtimeStatus(d.File)
 }
 
-// DetectPrinters returns available USB printer device paths on Linux.
-func DetectPrinters() []string {
+// detectDevices returns available USB printer device paths on Linux.
+func detectDevices() []string {
 	matches, err := filepath.Glob("/dev/usb/lp*")
 	if err != nil {
 		return nil
@@ -0,0 +1,237 @@
+package printer
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NetworkScheme is the device path prefix that selects the raw TCP transport,
+// e.g. "tcp://192.168.0.50:9100".
+const NetworkScheme = "tcp://"
+
+// DefaultNetworkPort is the raw printing (JetDirect) port used when the
+// device path does not specify one.
+const DefaultNetworkPort = 9100
+
+// Timeouts for the network transport. Variables so tests can shorten them.
+var (
+	networkDialTimeout  = 3 * time.Second
+	networkWriteTimeout = 5 * time.Second
+	networkProbeTimeout = 300 * time.Millisecond
+)
+
+// maxScanHosts bounds subnet discovery so a misconfigured /8 does not
+// spawn millions of probes.
+const maxScanHosts = 1024
+
+// IsNetworkPath reports whether devicePath selects the TCP transport.
+func IsNetworkPath(devicePath string) bool {
+	return strings.HasPrefix(devicePath, NetworkScheme)
+}
+
+// networkAddress extracts "host:port" from a tcp:// device path, adding the
+// default port when it is missing.
+func networkAddress(devicePath string) (string, error) {
+	hostport := strings.TrimSuffix(strings.TrimPrefix(devicePath, NetworkScheme), "/")
+	if hostport == "" {
+		return "", fmt.Errorf("endereco de rede vazio em %q", devicePath)
+	}
+	if _, _, err := net.SplitHostPort(hostport); err != nil {
+		hostport = net.JoinHostPort(hostport, fmt.Sprint(DefaultNetworkPort))
+	}
+	return hostport, nil
+}
+
+// NetworkWriter sends raw data to a printer over a TCP socket. A broken
+// connection is detected before each write and re-established once, so a
+// printer that was power-cycled or briefly unplugged from the LAN keeps
+// working without a manual reconnect.
+type NetworkWriter struct {
+	addr string
+	conn net.Conn
+	mu   sync.Mutex
+}
+
+func openNetwork(devicePath string) (*Printer, error) {
+	addr, err := networkAddress(devicePath)
+	if err != nil {
+		return nil, err
+	}
+
+	nw := &NetworkWriter{addr: addr}
+	if _, err := nw.Write(CmdInit); err != nil {
+		nw.Close()
+		return nil, fmt.Errorf("impressora nao respondeu em %s: %w", devicePath, err)
+	}
+
+	return &Printer{device: nw, path: devicePath}, nil
+}
+
+func (nw *NetworkWriter) dial() error {
+	conn, err := net.DialTimeout("tcp", nw.addr, networkDialTimeout)
+	if err != nil {
+		return fmt.Errorf("conectar em %s: %w", nw.addr, err)
+	}
+	nw.conn = conn
+	return nil
+}
+
+func (nw *NetworkWriter) dropConn() {
+	if nw.conn != nil {
+		nw.conn.Close()
+		nw.conn = nil
+	}
+}
+
+// alive checks whether the peer has closed the connection, discarding what
+// the printer sent unprompted, such as automatic status, on the way.
+func (nw *NetworkWriter) alive() bool {
+	return nw.conn != nil && drainInput(nw.conn) == nil
+}
+
+func (nw *NetworkWriter) Write(p []byte) (int, error) {
+	nw.mu.Lock()
+	defer nw.mu.Unlock()
+
+	if !nw.alive() {
+		nw.dropConn()
+		if err := nw.dial(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := nw.writeConn(p)
+	if err == nil || n > 0 {
+		// Never resend after a partial write: the printer would
+		// duplicate the part it already received.
+		return n, err
+	}
+
+	nw.dropConn()
+	if err := nw.dial(); err != nil {
+		return 0, err
+	}
+	return nw.writeConn(p)
+}
+
+func (nw *NetworkWriter) writeConn(p []byte) (int, error) {
+	if err := nw.conn.SetWriteDeadline(time.Now().Add(networkWriteTimeout)); err != nil {
+		return 0, err
+	}
+	n, err := nw.conn.Write(p)
+	if err != nil {
+		return n, fmt.Errorf("escrever em %s: %w", nw.addr, err)
+	}
+	return n, nil
+}
+
+func (nw *NetworkWriter) queryStatus() (PrinterStatus, error) {
+	nw.mu.Lock()
+	defer nw.mu.Unlock()
+
+	if !nw.alive() {
+		nw.dropConn()
+		if err := nw.dial(); err != nil {
+			return PrinterStatus{}, err
+		}
+	}
+	status, err := queryRealtimeStatus(nw.conn)
+	if err != nil {
+		// A half-read answer would desync later requests.
+		nw.dropConn()
+	}
+	return status, err
+}
+
+func (nw *NetworkWriter) Close() error {
+	nw.mu.Lock()
+	defer nw.mu.Unlock()
+	if nw.conn == nil {
+		return nil
+	}
+	err := nw.conn.Close()
+	nw.conn = nil
+	return err
+}
+
+// ScanNetwork probes every host of the given IPv4 subnet (CIDR notation,
+// e.g. "192.168.0.0/24") for an open raw printing port and returns the
+// responding hosts as tcp:// device paths.
+func ScanNetwork(subnet string, port int) []string {
+	hosts, err := subnetHosts(subnet)
+	if err != nil {
+		return nil
+	}
+
+	const workers = 64
+	jobs := make(chan string)
+	found := make([]bool, len(hosts))
+	index := make(map[string]int, len(hosts))
+	for i, h := range hosts {
+		index[h] = i
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for host := range jobs {
+				addr := net.JoinHostPort(host, fmt.Sprint(port))
+				conn, err := net.DialTimeout("tcp", addr, networkProbeTimeout)
+				if err != nil {
+					continue
+				}
+				conn.Close()
+				found[index[host]] = true
+			}
+		}()
+	}
+	for _, h := range hosts {
+		jobs <- h
+	}
+	close(jobs)
+	wg.Wait()
+
+	var printers []string
+	for i, ok := range found {
+		if ok {
+			printers = append(printers, NetworkScheme+net.JoinHostPort(hosts[i], fmt.Sprint(port)))
+		}
+	}
+	return printers
+}
+
+// subnetHosts lists the usable host addresses of an IPv4 CIDR, skipping the
+// network and broadcast addresses for prefixes shorter than /31.
+func subnetHosts(subnet string) ([]string, error) {
+	ip, ipnet, err := net.ParseCIDR(strings.TrimSpace(subnet))
+	if err != nil {
+		return nil, fmt.Errorf("sub-rede invalida %q: %w", subnet, err)
+	}
+	if ip.To4() == nil {
+		return nil, fmt.Errorf("sub-rede %q nao e IPv4", subnet)
+	}
+
+	ones, bits := ipnet.Mask.Size()
+	size := 1 << (bits - ones)
+	if size > maxScanHosts {
+		return nil, fmt.Errorf("sub-rede %q grande demais (max %d hosts)", subnet, maxScanHosts)
+	}
+
+	base := ipnet.IP.To4()
+	start := uint32(base[0])<<24 | uint32(base[1])<<16 | uint32(base[2])<<8 | uint32(base[3])
+
+	var hosts []string
+	for i := 0; i < size; i++ {
+		if size > 2 && (i == 0 || i == size-1) {
+			continue
+		}
+		n := start + uint32(i)
+		hosts = append(hosts, net.IPv4(byte(n>>24), byte(n>>16), byte(n>>8), byte(n)).String())
+	}
+	return hosts, nil
+}
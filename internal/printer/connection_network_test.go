@@ -0,0 +1,180 @@
+package printer
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeNetworkPrinter accepts connections on a local port and forwards
+// everything each connection sends on received.
+type fakeNetworkPrinter struct {
+	ln       net.Listener
+	received chan []byte
+	conns    chan net.Conn
+}
+
+func newFakeNetworkPrinter(t *testing.T) *fakeNetworkPrinter {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	fp := &fakeNetworkPrinter{
+		ln:       ln,
+		received: make(chan []byte, 16),
+		conns:    make(chan net.Conn, 16),
+	}
+	go fp.serve()
+	t.Cleanup(func() { ln.Close() })
+	return fp
+}
+
+func (fp *fakeNetworkPrinter) serve() {
+	for {
+		conn, err := fp.ln.Accept()
+		if err != nil {
+			return
+		}
+		fp.conns <- conn
+		go func() {
+			buf := make([]byte, 4096)
+			for {
+				n, err := conn.Read(buf)
+				if n > 0 {
+					fp.received <- append([]byte(nil), buf[:n]...)
+				}
+				if err != nil {
+					return
+				}
+			}
+		}()
+	}
+}
+
+func (fp *fakeNetworkPrinter) path() string {
+	return NetworkScheme + fp.ln.Addr().String()
+}
+
+func (fp *fakeNetworkPrinter) expect(t *testing.T, want []byte) {
+	t.Helper()
+	var got []byte
+	deadline := time.After(2 * time.Second)
+	for !bytes.Equal(got, want) {
+		select {
+		case chunk := <-fp.received:
+			got = append(got, chunk...)
+		case <-deadline:
+			t.Fatalf("received %v, want %v", got, want)
+		}
+	}
+}
+
+func TestNetworkAddress(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"tcp://192.168.0.50:9100", "192.168.0.50:9100"},
+		{"tcp://192.168.0.50", "192.168.0.50:9100"},
+		{"tcp://impressora.local:9101/", "impressora.local:9101"},
+	}
+	for _, tc := range tests {
+		got, err := networkAddress(tc.path)
+		if err != nil || got != tc.want {
+			t.Errorf("networkAddress(%q) = %q, %v; want %q", tc.path, got, err, tc.want)
+		}
+	}
+	if _, err := networkAddress("tcp://"); err == nil {
+		t.Error("networkAddress(tcp://) should fail")
+	}
+}
+
+func TestOpenNetworkPrinterWrites(t *testing.T) {
+	fp := newFakeNetworkPrinter(t)
+
+	p, err := Open(fp.path())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer p.Close()
+
+	fp.expect(t, CmdInit)
+
+	if err := p.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	fp.expect(t, []byte("hello\n"))
+
+	if p.Path() != fp.path() {
+		t.Errorf("Path = %q, want %q", p.Path(), fp.path())
+	}
+}
+
+func TestNetworkPrinterReconnects(t *testing.T) {
+	fp := newFakeNetworkPrinter(t)
+
+	p, err := Open(fp.path())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer p.Close()
+	fp.expect(t, CmdInit)
+
+	// Printer power-cycles: it drops the connection on its side.
+	first := <-fp.conns
+	first.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	if err := p.Write([]byte("again")); err != nil {
+		t.Fatalf("Write after drop: %v", err)
+	}
+	fp.expect(t, []byte("again"))
+
+	select {
+	case <-fp.conns:
+	case <-time.After(time.Second):
+		t.Fatal("expected a second connection after reconnect")
+	}
+}
+
+func TestOpenNetworkPrinterUnreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	if _, err := Open(NetworkScheme + addr); err == nil {
+		t.Fatal("Open should fail when nothing listens")
+	}
+}
+
+func TestScanNetwork(t *testing.T) {
+	fp := newFakeNetworkPrinter(t)
+	port := fp.ln.Addr().(*net.TCPAddr).Port
+
+	found := ScanNetwork("127.0.0.1/32", port)
+	if len(found) != 1 || found[0] != fp.path() {
+		t.Errorf("ScanNetwork = %v, want [%s]", found, fp.path())
+	}
+}
+
+func TestSubnetHosts(t *testing.T) {
+	hosts, err := subnetHosts("192.168.0.0/30")
+	if err != nil {
+		t.Fatalf("subnetHosts: %v", err)
+	}
+	if strings.Join(hosts, ",") != "192.168.0.1,192.168.0.2" {
+		t.Errorf("hosts = %v", hosts)
+	}
+	if _, err := subnetHosts("10.0.0.0/8"); err == nil {
+		t.Error("subnetHosts should reject subnets larger than maxScanHosts")
+	}
+}
+
+var _ io.WriteCloser = (*NetworkWriter)(nil)
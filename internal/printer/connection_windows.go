@@ -23,8 +23,10 @@ var (
 
 const (
 	printerStatusError        = 0x00000002
+	printerStatusPaperOut     = 0x00000010
 	printerStatusOffline      = 0x00000080
 	printerStatusNotAvailable = 0x00001000
+	printerStatusDoorOpen     = 0x00400000
 	printerStatusMask         = printerStatusError | printerStatusOffline | printerStatusNotAvailable
 )
 
@@ -107,6 +109,20 @@ func (sw *SpoolerWriter) Write(p []byte) (int, error) {
 	return int(written), nil
 }
 
+// queryStatus maps the spooler's status flags onto PrinterStatus. The
+// spooler does not expose the paper near-end sensor or the drawer pin.
+func (sw *SpoolerWriter) queryStatus() (PrinterStatus, error) {
+	flags, err := printerStatus(sw.handle)
+	if err != nil {
+		return PrinterStatus{}, err
+	}
+	return PrinterStatus{
+		Online:    flags&(printerStatusOffline|printerStatusNotAvailable|printerStatusError) == 0,
+		PaperOut:  flags&printerStatusPaperOut != 0,
+		CoverOpen: flags&printerStatusDoorOpen != 0,
+	}, nil
+}
+
 func (sw *SpoolerWriter) Close() error {
 	if sw.handle == 0 {
 		return nil
@@ -145,11 +161,11 @@ func printerStatus(handle uintptr) (uint32, error) {
 	return status, nil
 }
 
-// Open opens a connection to a Windows printer via the Spooler API.
+// openDevice opens a connection to a Windows printer via the Spooler API.
 // After opening the spooler handle, it queries status flags to detect
 // offline or unavailable printers, since OpenPrinterW succeeds for any
 // registered driver even without a physical device.
-func Open(printerName string) (*Printer, error) {
+func openDevice(printerName string) (*Printer, error) {
 	sw, err := openSpooler(printerName)
 	if err != nil {
 		return nil, err
@@ -172,8 +188,8 @@ const (
 	printerEnumLocal = 0x00000002
 )
 
-// DetectPrinters returns names of locally installed printers on Windows.
-func DetectPrinters() []string {
+// detectDevices returns names of locally installed printers on Windows.
+func detectDevices() []string {
 	var needed, count uint32
 
 	// First call to get required buffer size.
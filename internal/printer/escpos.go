@@ -23,14 +23,23 @@ var (
 	CmdCodePage858 = []byte{0x1B, 0x74, 0x13}
 )
 
+// QR code error correction levels for GS ( k.
+const (
+	QRCorrectionL byte = 48 // ~7%
+	QRCorrectionM byte = 49 // ~15%
+	QRCorrectionQ byte = 50 // ~25%
+	QRCorrectionH byte = 51 // ~30%
+)
+
 func CmdFeedLines(n byte) []byte {
 	return []byte{0x1B, 0x64, n}
 }
 
 // ReceiptBuilder provides a fluent API for constructing ESC/POS byte sequences.
 type ReceiptBuilder struct {
-	buf     bytes.Buffer
-	encoder *charmap.Charmap
+	buf        bytes.Buffer
+	encoder    *charmap.Charmap
+	paperWidth int // printable width in dots; 0 means 80mm paper
 }
 
 func NewReceiptBuilder() *ReceiptBuilder {
@@ -42,6 +51,19 @@ func NewReceiptBuilder() *ReceiptBuilder {
 	return rb
 }
 
+// PaperWidth sets the printable width in dots used to scale images.
+func (rb *ReceiptBuilder) PaperWidth(dots int) *ReceiptBuilder {
+	rb.paperWidth = dots
+	return rb
+}
+
+func (rb *ReceiptBuilder) paperDots() int {
+	if rb.paperWidth > 0 {
+		return rb.paperWidth
+	}
+	return 48 * DotsPerChar
+}
+
 func (rb *ReceiptBuilder) AlignLeft() *ReceiptBuilder {
 	rb.buf.Write(CmdAlignLeft)
 	return rb
@@ -132,6 +154,29 @@ func (rb *ReceiptBuilder) OpenDrawer() *ReceiptBuilder {
 	return rb
 }
 
+// QRCode prints data as a model 2 QR code using the printer's native
+// GS ( k commands. moduleSize is the dot width of each module (1-16).
+func (rb *ReceiptBuilder) QRCode(data string, moduleSize byte, correction byte) *ReceiptBuilder {
+	if moduleSize < 1 {
+		moduleSize = 1
+	}
+	if moduleSize > 16 {
+		moduleSize = 16
+	}
+	n := len(data) + 3
+	if n > 7092 {
+		return rb
+	}
+
+	rb.buf.Write([]byte{0x1D, 0x28, 0x6B, 0x04, 0x00, 0x31, 0x41, 0x32, 0x00}) // model 2
+	rb.buf.Write([]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x43, moduleSize})
+	rb.buf.Write([]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x45, correction})
+	rb.buf.Write([]byte{0x1D, 0x28, 0x6B, byte(n), byte(n >> 8), 0x31, 0x50, 0x30})
+	rb.buf.WriteString(data)
+	rb.buf.Write([]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x51, 0x30}) // print
+	return rb
+}
+
 func (rb *ReceiptBuilder) Build() []byte {
 	return rb.buf.Bytes()
 }
@@ -0,0 +1,213 @@
+package printer
+
+import (
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"sync"
+	"time"
+)
+
+// DotsPerChar is the width in dots of one Font A character, so a paper
+// of CharsPerLine columns is CharsPerLine*DotsPerChar dots wide
+// (48 columns = 576 dots on 80mm paper).
+const DotsPerChar = 12
+
+// rasterBandRows is the tallest band sent in one GS v 0 command; larger
+// images are split so printers with a small receive buffer cope.
+const rasterBandRows = 255
+
+type Dither int
+
+const (
+	DitherFloydSteinberg Dither = iota // error diffusion, best for photos and gradients
+	DitherThreshold                    // plain 50% cut, best for line-art logos
+)
+
+// Raster is a 1-bit image packed 8 dots per byte, MSB first, with each row
+// padded to a whole byte. A set bit prints black.
+type Raster struct {
+	Width  int // dots
+	Height int // dots
+	Data   []byte
+}
+
+// BytesPerRow returns the packed row stride.
+func (r *Raster) BytesPerRow() int {
+	return (r.Width + 7) / 8
+}
+
+// Rasterize converts img to a 1-bit raster no wider than maxWidth dots,
+// scaling it down (never up) while keeping the aspect ratio. Transparent
+// pixels are treated as white paper.
+func Rasterize(img image.Image, maxWidth int, dither Dither) *Raster {
+	gray := scaledGray(img, maxWidth)
+	h := len(gray)
+	w := 0
+	if h > 0 {
+		w = len(gray[0])
+	}
+
+	r := &Raster{Width: w, Height: h}
+	stride := r.BytesPerRow()
+	r.Data = make([]byte, stride*h)
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			old := gray[y][x]
+			black := old < 128
+			if black {
+				r.Data[y*stride+x/8] |= 0x80 >> (x % 8)
+			}
+			if dither != DitherFloydSteinberg {
+				continue
+			}
+			var quant float64 = 255
+			if black {
+				quant = 0
+			}
+			e := old - quant
+			if x+1 < w {
+				gray[y][x+1] += e * 7 / 16
+			}
+			if y+1 < h {
+				if x > 0 {
+					gray[y+1][x-1] += e * 3 / 16
+				}
+				gray[y+1][x] += e * 5 / 16
+				if x+1 < w {
+					gray[y+1][x+1] += e * 1 / 16
+				}
+			}
+		}
+	}
+	return r
+}
+
+// scaledGray returns the image as luminance rows (0 black - 255 white),
+// box-averaged down to maxWidth when it is wider.
+func scaledGray(img image.Image, maxWidth int) [][]float64 {
+	b := img.Bounds()
+	srcW, srcH := b.Dx(), b.Dy()
+	if srcW == 0 || srcH == 0 {
+		return nil
+	}
+	w, h := srcW, srcH
+	if maxWidth > 0 && srcW > maxWidth {
+		w = maxWidth
+		h = srcH * maxWidth / srcW
+		if h < 1 {
+			h = 1
+		}
+	}
+
+	rows := make([][]float64, h)
+	for y := 0; y < h; y++ {
+		rows[y] = make([]float64, w)
+		y0, y1 := y*srcH/h, (y+1)*srcH/h
+		if y1 == y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < w; x++ {
+			x0, x1 := x*srcW/w, (x+1)*srcW/w
+			if x1 == x0 {
+				x1 = x0 + 1
+			}
+			var sum float64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					sum += luminance(img, b.Min.X+sx, b.Min.Y+sy)
+				}
+			}
+			rows[y][x] = sum / float64((y1-y0)*(x1-x0))
+		}
+	}
+	return rows
+}
+
+// luminance composites the pixel over white and returns its brightness.
+func luminance(img image.Image, x, y int) float64 {
+	r, g, b, a := img.At(x, y).RGBA()
+	// Premultiplied: add the white showing through the transparent part.
+	white := 0xFFFF - a
+	r, g, b = r+white, g+white, b+white
+	return (0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)) / 257
+}
+
+// Image prints img as a raster bitmap, dithered with Floyd-Steinberg and
+// scaled down to the builder's paper width.
+func (rb *ReceiptBuilder) Image(img image.Image) *ReceiptBuilder {
+	return rb.Raster(Rasterize(img, rb.paperDots(), DitherFloydSteinberg))
+}
+
+// Raster prints an already converted bitmap with GS v 0, in bands of at
+// most rasterBandRows rows.
+func (rb *ReceiptBuilder) Raster(r *Raster) *ReceiptBuilder {
+	if r == nil || r.Width == 0 || r.Height == 0 {
+		return rb
+	}
+	stride := r.BytesPerRow()
+	for top := 0; top < r.Height; top += rasterBandRows {
+		rows := r.Height - top
+		if rows > rasterBandRows {
+			rows = rasterBandRows
+		}
+		rb.buf.Write([]byte{0x1D, 0x76, 0x30, 0x00,
+			byte(stride), byte(stride >> 8),
+			byte(rows), byte(rows >> 8)})
+		rb.buf.Write(r.Data[top*stride : (top+rows)*stride])
+	}
+	return rb
+}
+
+type logoKey struct {
+	path  string
+	width int
+}
+
+type logoEntry struct {
+	modTime time.Time
+	raster  *Raster
+}
+
+var (
+	logoMu    sync.Mutex
+	logoCache = map[logoKey]logoEntry{}
+)
+
+// LoadLogo decodes the image file at path (PNG, JPEG or GIF) and converts
+// it to a raster at most maxWidth dots wide. The conversion is cached and
+// only redone when the file changes.
+func LoadLogo(path string, maxWidth int) (*Raster, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("abrir logo %s: %w", path, err)
+	}
+
+	key := logoKey{path: path, width: maxWidth}
+	logoMu.Lock()
+	entry, ok := logoCache[key]
+	logoMu.Unlock()
+	if ok && entry.modTime.Equal(info.ModTime()) {
+		return entry.raster, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("abrir logo %s: %w", path, err)
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decodificar logo %s: %w", path, err)
+	}
+
+	raster := Rasterize(img, maxWidth, DitherFloydSteinberg)
+	logoMu.Lock()
+	logoCache[key] = logoEntry{modTime: info.ModTime(), raster: raster}
+	logoMu.Unlock()
+	return raster, nil
+}
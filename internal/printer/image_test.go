@@ -0,0 +1,124 @@
+package printer
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func solidImage(w, h int, c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func countSetBits(data []byte) int {
+	n := 0
+	for _, b := range data {
+		for ; b != 0; b &= b - 1 {
+			n++
+		}
+	}
+	return n
+}
+
+func TestRasterizeSolidColors(t *testing.T) {
+	black := Rasterize(solidImage(10, 2, color.Black), 576, DitherFloydSteinberg)
+	if black.Width != 10 || black.Height != 2 || black.BytesPerRow() != 2 {
+		t.Fatalf("raster = %dx%d stride %d, want 10x2 stride 2", black.Width, black.Height, black.BytesPerRow())
+	}
+	if got := countSetBits(black.Data); got != 20 {
+		t.Errorf("black bits = %d, want 20 (row padding must stay clear)", got)
+	}
+
+	white := Rasterize(solidImage(10, 2, color.White), 576, DitherFloydSteinberg)
+	if got := countSetBits(white.Data); got != 0 {
+		t.Errorf("white bits = %d, want 0", got)
+	}
+
+	transparent := Rasterize(solidImage(8, 8, color.Transparent), 576, DitherThreshold)
+	if got := countSetBits(transparent.Data); got != 0 {
+		t.Errorf("transparent bits = %d, want 0 (printed as paper)", got)
+	}
+}
+
+func TestRasterizeDithersGray(t *testing.T) {
+	gray := solidImage(64, 64, color.Gray{Y: 128})
+
+	fs := Rasterize(gray, 576, DitherFloydSteinberg)
+	ratio := float64(countSetBits(fs.Data)) / float64(64*64)
+	if ratio < 0.4 || ratio > 0.6 {
+		t.Errorf("Floyd-Steinberg black ratio = %.2f, want about 0.5", ratio)
+	}
+
+	th := Rasterize(gray, 576, DitherThreshold)
+	if got := countSetBits(th.Data); got != 0 {
+		t.Errorf("threshold on mid gray set %d bits, want 0", got)
+	}
+}
+
+func TestRasterizeScalesToPaperWidth(t *testing.T) {
+	r := Rasterize(solidImage(1152, 200, color.Black), 576, DitherThreshold)
+	if r.Width != 576 || r.Height != 100 {
+		t.Errorf("scaled raster = %dx%d, want 576x100", r.Width, r.Height)
+	}
+
+	small := Rasterize(solidImage(100, 50, color.Black), 576, DitherThreshold)
+	if small.Width != 100 || small.Height != 50 {
+		t.Errorf("small raster = %dx%d, should not be upscaled", small.Width, small.Height)
+	}
+}
+
+func TestRasterCommandBands(t *testing.T) {
+	r := Rasterize(solidImage(16, 300, color.Black), 576, DitherThreshold)
+	rb := &ReceiptBuilder{}
+	rb.Raster(r)
+	out := rb.Build()
+
+	first := []byte{0x1D, 0x76, 0x30, 0x00, 2, 0, 255, 0}
+	if !bytes.HasPrefix(out, first) {
+		t.Fatalf("first band header = % X, want % X", out[:8], first)
+	}
+	second := []byte{0x1D, 0x76, 0x30, 0x00, 2, 0, 45, 0}
+	if !bytes.Contains(out, second) {
+		t.Error("second band header for the remaining 45 rows missing")
+	}
+	if want := 2*8 + 300*2; len(out) != want {
+		t.Errorf("output length = %d, want %d", len(out), want)
+	}
+}
+
+func TestLoadLogoCaches(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logo.png")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, solidImage(40, 20, color.Black)); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	first, err := LoadLogo(path, 576)
+	if err != nil {
+		t.Fatalf("LoadLogo: %v", err)
+	}
+	second, err := LoadLogo(path, 576)
+	if err != nil {
+		t.Fatalf("LoadLogo: %v", err)
+	}
+	if first != second {
+		t.Error("second LoadLogo should return the cached raster")
+	}
+	if first.Width != 40 || first.Height != 20 {
+		t.Errorf("logo = %dx%d, want 40x20", first.Width, first.Height)
+	}
+}
@@ -2,10 +2,63 @@ package printer
 
 import (
 	"fmt"
+	"strings"
+	"time"
+
+	"notinha/internal/pos"
 )
 
+// StationItems is the slice of an order destined to one production printer.
+type StationItems struct {
+	Station string
+	Items   []pos.OrderItem
+}
+
+// GroupByStation splits items by the printer their menu category routes to,
+// keeping the order in which each station first appears. Combos are split
+// into their components, so the pizza and the drink go to their stations.
+func GroupByStation(items []pos.OrderItem, route func(category string) string) []StationItems {
+	var groups []StationItems
+	index := map[string]int{}
+	for _, oi := range pos.ExpandCombos(items) {
+		station := route(oi.Item.Category)
+		i, ok := index[station]
+		if !ok {
+			i = len(groups)
+			index[station] = i
+			groups = append(groups, StationItems{Station: station})
+		}
+		groups[i].Items = append(groups[i].Items, oi)
+	}
+	return groups
+}
+
 // BuildKitchenTicket constructs a kitchen-only ticket (no prices) as ESC/POS bytes.
 func BuildKitchenTicket(data ReceiptData) []byte {
+	return BuildStationTicket(data, "cozinha", pos.ExpandCombos(data.Order.Items))
+}
+
+// BuildStationTicket constructs a production ticket (no prices) listing only
+// the given items, headed by the station name.
+func BuildStationTicket(data ReceiptData, station string, items []pos.OrderItem) []byte {
+	return buildProductionTicket(data, station, "", items)
+}
+
+// BuildAdditionTicket lists items added to an order the station already
+// received, marked ACRESCIMO.
+func BuildAdditionTicket(data ReceiptData, station string, items []pos.OrderItem) []byte {
+	return buildProductionTicket(data, station, "ACRESCIMO", items)
+}
+
+// BuildCancellationTicket lists sent items taken off an order, marked
+// CANCELAMENTO, so the station stops making them.
+func BuildCancellationTicket(data ReceiptData, station string, items []pos.OrderItem) []byte {
+	return buildProductionTicket(data, station, "CANCELAMENTO", items)
+}
+
+// buildProductionTicket prints a station ticket; mark, when set, flags it
+// as a change to an order already sent, with the time of the change.
+func buildProductionTicket(data ReceiptData, station, mark string, items []pos.OrderItem) []byte {
 	w := data.CharsPerLine
 	if w <= 0 {
 		w = 48
@@ -13,10 +66,16 @@ func BuildKitchenTicket(data ReceiptData) []byte {
 
 	rb := NewReceiptBuilder()
 
-	rb.AlignCenter().
-		FontDouble().Bold().
-		Line("*** COZINHA ***").
-		FontNormal().NoBold()
+	rb.AlignCenter()
+	writeTitle(rb, fmt.Sprintf("*** %s ***", strings.ToUpper(station)), w)
+
+	if mark != "" {
+		rb.FontDouble().Bold().
+			Line(mark).
+			FontNormal().NoBold().
+			Line("Hora: " + time.Now().Format("15:04"))
+	}
+	writeReprintMark(rb, data.Reprint)
 
 	rb.Separator('-', w)
 
@@ -25,7 +84,9 @@ func BuildKitchenTicket(data ReceiptData) []byte {
 	rb.Line(formatDateTime(data.Order.CreatedAt))
 
 	if data.Order.Customer != "" {
-		rb.Line("Cliente: " + truncate(data.Order.Customer, w/2-5))
+		// Double width: half as many characters fit.
+		rb.Row(Table{Width: w / 2, Columns: []Column{{Width: 9}, {Wrap: true}}},
+			"Cliente:", data.Order.Customer)
 	}
 	if data.Order.Table != "" {
 		rb.Line("Mesa: " + data.Order.Table)
@@ -34,12 +95,19 @@ func BuildKitchenTicket(data ReceiptData) []byte {
 	rb.FontNormal().NoBold()
 	rb.Separator('-', w)
 
-	for _, oi := range data.Order.Items {
+	for _, oi := range items {
+		qty := fmt.Sprintf("%dx", oi.Quantity)
+		name := oi.Item.Name
+		if len(oi.Flavors) > 0 {
+			name = fmt.Sprintf("Pizza %d sabores", len(oi.Flavors))
+		}
 		rb.Bold().
-			Line(fmt.Sprintf("%dx %s", oi.Quantity, truncate(oi.Item.Name, w-5)))
+			Row(Table{Width: w, Columns: []Column{{Width: TextWidth(qty) + 1}, {Wrap: true}}}, qty, name)
+		writeFlavors(rb, oi.Flavors, w)
 		rb.NoBold()
+		writeModifiers(rb, oi.Modifiers, w, false)
 		if oi.Notes != "" {
-			rb.Line("  * " + oi.Notes)
+			rb.Row(noteTable(w), "  *", oi.Notes)
 		}
 	}
 
@@ -48,3 +116,12 @@ func BuildKitchenTicket(data ReceiptData) []byte {
 
 	return rb.Build()
 }
+
+// writeFlavors lists each fraction of a pizza of several flavors on its
+// own line, so the kitchen sees at a glance how to split the toppings.
+func writeFlavors(rb *ReceiptBuilder, flavors []pos.Flavor, w int) {
+	fractions := Table{Width: w, Columns: []Column{{Width: 7}, {Wrap: true}}}
+	for i, f := range flavors {
+		rb.Row(fractions, "   "+pos.FlavorFraction(flavors, i), f.Item.Name)
+	}
+}
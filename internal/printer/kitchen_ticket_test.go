@@ -0,0 +1,99 @@
+package printer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"notinha/internal/pos"
+	"notinha/internal/storage"
+)
+
+func TestGroupByStation(t *testing.T) {
+	cfg := storage.DefaultConfig()
+	cfg.Routes = []storage.PrinterRoute{
+		{Category: "Chopp", Printer: storage.BarPrinter},
+		{Category: "Drinks", Printer: storage.BarPrinter},
+		{Category: "Cervejas - *", Printer: storage.BarPrinter},
+		{Category: "Pizzas *", Printer: storage.KitchenPrinter},
+	}
+
+	items := []pos.OrderItem{
+		{Item: pos.MenuItem{ID: 1, Name: "Portuguesa", Category: "Pizzas Tradicionais"}, Quantity: 1},
+		{Item: pos.MenuItem{ID: 2, Name: "Chopp 300ml", Category: "Chopp"}, Quantity: 2},
+		{Item: pos.MenuItem{ID: 3, Name: "Skol Lata", Category: "Cervejas - Lata"}, Quantity: 3},
+		{Item: pos.MenuItem{ID: 4, Name: "Batata Frita", Category: "Porções"}, Quantity: 1},
+	}
+
+	groups := GroupByStation(items, cfg.RouteFor)
+	if len(groups) != 2 {
+		t.Fatalf("groups = %d, want 2", len(groups))
+	}
+	if groups[0].Station != storage.KitchenPrinter || len(groups[0].Items) != 2 {
+		t.Errorf("kitchen group = %+v, want Portuguesa and Batata Frita", groups[0])
+	}
+	if groups[1].Station != storage.BarPrinter || len(groups[1].Items) != 2 {
+		t.Errorf("bar group = %+v, want Chopp and Skol", groups[1])
+	}
+}
+
+func TestBuildStationTicketOnlyListsItsItems(t *testing.T) {
+	order := pos.NewOrder(7)
+	order.AddItem(pos.MenuItem{ID: 1, Name: "Portuguesa", Category: "Pizzas Tradicionais"}, 1, "")
+	order.AddItem(pos.MenuItem{ID: 2, Name: "Chopp", Category: "Chopp"}, 2, "")
+
+	data := ReceiptData{Order: order, CharsPerLine: 48}
+	ticket := BuildStationTicket(data, storage.BarPrinter, order.Items[1:])
+
+	if !bytes.Contains(ticket, []byte("*** BAR ***")) {
+		t.Error("ticket should be headed by the station name")
+	}
+	if !bytes.Contains(ticket, []byte("2x Chopp")) {
+		t.Error("ticket should list the bar item")
+	}
+	if bytes.Contains(ticket, []byte("Portuguesa")) {
+		t.Error("ticket should not list kitchen items")
+	}
+}
+
+func TestKitchenTicketListsPizzaFlavors(t *testing.T) {
+	order := pos.NewOrder(7)
+	flavors := []pos.Flavor{
+		{Item: pos.MenuItem{ID: 1, Name: "Calabresa", Price: 4500, Category: "Pizzas Tradicionais"}, Parts: 2},
+		{Item: pos.MenuItem{ID: 2, Name: "Portuguesa", Price: 5300, Category: "Pizzas Tradicionais"}, Parts: 1},
+		{Item: pos.MenuItem{ID: 3, Name: "Mussarela", Price: 4000, Category: "Pizzas Tradicionais"}, Parts: 1},
+	}
+	if err := order.AddPizza(flavors, pos.PricingHighest, 1, "", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	text := ParsePreview(BuildKitchenTicket(ReceiptData{Order: order, CharsPerLine: 48}), 48).Text()
+	for _, want := range []string{"1x Pizza 3 sabores", "   1/2 Calabresa", "   1/4 Portuguesa", "   1/4 Mussarela"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("ticket missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestAdditionAndCancellationTickets(t *testing.T) {
+	order := pos.NewOrder(7)
+	order.AddItem(pos.MenuItem{ID: 1, Name: "Portuguesa", Category: "Pizzas Tradicionais"}, 1, "")
+	data := ReceiptData{Order: order, CharsPerLine: 48}
+
+	plain := ParsePreview(BuildStationTicket(data, storage.KitchenPrinter, order.Items), 48).Text()
+	if strings.Contains(plain, "ACRESCIMO") || strings.Contains(plain, "CANCELAMENTO") {
+		t.Errorf("first ticket should not be marked:\n%s", plain)
+	}
+
+	added := ParsePreview(BuildAdditionTicket(data, storage.KitchenPrinter, order.Items), 48).Text()
+	for _, want := range []string{"C O Z I N H A", "A C R E S C I M O", "Hora: ", "1x Portuguesa"} {
+		if !strings.Contains(added, want) {
+			t.Errorf("addition ticket missing %q:\n%s", want, added)
+		}
+	}
+
+	cancelled := ParsePreview(BuildCancellationTicket(data, storage.KitchenPrinter, order.Items), 48).Text()
+	if !strings.Contains(cancelled, "C A N C E L A M E N T O") {
+		t.Errorf("cancellation ticket should be marked:\n%s", cancelled)
+	}
+}
@@ -0,0 +1,210 @@
+package printer
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// TextAlign is the horizontal alignment of text inside a table column.
+type TextAlign int
+
+const (
+	TextLeft TextAlign = iota
+	TextRight
+	TextCenter
+)
+
+// Column describes one column of a Table.
+type Column struct {
+	Width int       // in characters; 0 shares the space left by fixed columns
+	Align TextAlign // alignment inside the column
+	Wrap  bool      // word-wrap onto continuation lines instead of truncating
+}
+
+// Table lays text out in fixed-width columns on a paper of Width
+// characters. Widths are counted in printed characters: the text is
+// composed to NFC first, so "ç" written as c + combining cedilla still
+// takes one column, the same as the single CP858 byte it is printed as.
+type Table struct {
+	Width   int
+	Columns []Column
+}
+
+// Format lays out one row and returns the printed lines: one, or more
+// when a wrapping column needs continuation lines. Cells beyond the last
+// column are ignored; missing cells are blank. Trailing spaces are
+// trimmed.
+func (t Table) Format(cells ...string) []string {
+	widths := t.columnWidths()
+
+	columns := make([][]string, len(t.Columns))
+	rows := 1
+	for i, col := range t.Columns {
+		cell := ""
+		if i < len(cells) {
+			cell = cells[i]
+		}
+		if col.Wrap {
+			columns[i] = WrapText(cell, widths[i])
+		} else {
+			columns[i] = []string{truncate(cell, widths[i])}
+		}
+		if len(columns[i]) > rows {
+			rows = len(columns[i])
+		}
+	}
+
+	lines := make([]string, rows)
+	for r := range lines {
+		var b strings.Builder
+		for i, col := range t.Columns {
+			text := ""
+			if r < len(columns[i]) {
+				text = columns[i][r]
+			}
+			b.WriteString(alignCell(text, col.Align, widths[i]))
+		}
+		lines[r] = strings.TrimRight(b.String(), " ")
+	}
+	return lines
+}
+
+// columnWidths resolves flexible columns: the space left by fixed columns
+// is split evenly, the last flexible column taking the remainder.
+func (t Table) columnWidths() []int {
+	widths := make([]int, len(t.Columns))
+	free := t.Width
+	var flex []int
+	for i, col := range t.Columns {
+		if col.Width > 0 {
+			widths[i] = col.Width
+			free -= col.Width
+		} else {
+			flex = append(flex, i)
+		}
+	}
+	if free < 0 {
+		free = 0
+	}
+	for n, i := range flex {
+		widths[i] = free / len(flex)
+		if n == len(flex)-1 {
+			widths[i] = free - free/len(flex)*(len(flex)-1)
+		}
+	}
+	return widths
+}
+
+func alignCell(s string, align TextAlign, width int) string {
+	switch align {
+	case TextRight:
+		return padLeft(s, width)
+	case TextCenter:
+		gap := width - TextWidth(s)
+		if gap <= 0 {
+			return s
+		}
+		return strings.Repeat(" ", gap/2) + s + strings.Repeat(" ", gap-gap/2)
+	}
+	return padRight(s, width)
+}
+
+// TextWidth returns how many characters s takes on paper.
+func TextWidth(s string) int {
+	return utf8.RuneCountInString(norm.NFC.String(s))
+}
+
+// WrapText breaks s into lines of at most width characters, at spaces
+// where possible and inside words only when a word alone is too long.
+// It always returns at least one line.
+func WrapText(s string, width int) []string {
+	words := strings.Fields(norm.NFC.String(s))
+	if width <= 0 || len(words) == 0 {
+		return []string{""}
+	}
+
+	var lines []string
+	line := ""
+	for _, word := range words {
+		for utf8.RuneCountInString(word) > width {
+			// Fill what is left of the current line, or start a
+			// line with the head of the word.
+			room := width
+			if line != "" {
+				room = width - utf8.RuneCountInString(line) - 1
+				if room <= 0 {
+					lines = append(lines, line)
+					line = ""
+					room = width
+				}
+			}
+			head, rest := splitRunes(word, room)
+			if line != "" {
+				head = line + " " + head
+				line = ""
+			}
+			lines = append(lines, head)
+			word = rest
+		}
+		switch {
+		case line == "":
+			line = word
+		case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// splitRunes splits s after n runes.
+func splitRunes(s string, n int) (string, string) {
+	i := 0
+	for pos := range s {
+		if i == n {
+			return s[:pos], s[pos:]
+		}
+		i++
+	}
+	return s, ""
+}
+
+// truncate cuts s to at most width characters, never inside a UTF-8
+// sequence.
+func truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	s = norm.NFC.String(s)
+	head, _ := splitRunes(s, width)
+	return head
+}
+
+func padRight(s string, width int) string {
+	if n := TextWidth(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+func padLeft(s string, width int) string {
+	if n := TextWidth(s); n < width {
+		return strings.Repeat(" ", width-n) + s
+	}
+	return s
+}
+
+// Row writes one table row, including any continuation lines.
+func (rb *ReceiptBuilder) Row(t Table, cells ...string) *ReceiptBuilder {
+	for _, line := range t.Format(cells...) {
+		rb.Line(line)
+	}
+	return rb
+}
@@ -0,0 +1,162 @@
+package printer
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"notinha/internal/pos"
+	"notinha/internal/storage"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite testdata/*.golden files")
+
+func TestTableFormat(t *testing.T) {
+	table := Table{Width: 20, Columns: []Column{
+		{Width: 3},
+		{Wrap: true},
+		{Width: 6, Align: TextRight},
+	}}
+	got := table.Format("2x", "Frango com Catupiry", "R$ 10")
+	want := []string{
+		"2x Frango com  R$ 10",
+		"   Catupiry",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Format() = %q, want %q", got, want)
+	}
+}
+
+func TestTableCountsRunesNotBytes(t *testing.T) {
+	table := Table{Width: 16, Columns: []Column{{}, {Width: 6, Align: TextRight}}}
+	// "Porções" composed and decomposed both take 7 columns.
+	for _, name := range []string{"Porções", "Porc\u0327o\u0303es"} {
+		got := table.Format(name, "R$ 5")
+		if want := []string{"Porções     R$ 5"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Format(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestTruncateKeepsRunesWhole(t *testing.T) {
+	if got := truncate("Açaí na tigela", 4); got != "Açaí" {
+		t.Errorf("truncate = %q, want %q", got, "Açaí")
+	}
+}
+
+func TestWrapTextBreaksLongWords(t *testing.T) {
+	got := WrapText("Pizza Supercalifragilistica grande", 10)
+	want := []string{"Pizza Supe", "rcalifragi", "listica", "grande"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WrapText() = %q, want %q", got, want)
+	}
+}
+
+func goldenOrder() *pos.Order {
+	order := pos.NewOrder(7)
+	order.CreatedAt = time.Date(2026, 3, 14, 19, 45, 0, 0, time.Local)
+	order.Customer = "João Conceição"
+	order.Table = "12"
+	order.AddItem(pos.MenuItem{ID: 1, Name: "Porções de Frango com Catupiry e Batata Frita", Price: 4590, Category: "Porções"}, 2, "")
+	order.AddItem(pos.MenuItem{ID: 2, Name: "Açaí", Price: 1800, Category: "Sobremesas"}, 1, "sem granola, com leite condensado e morango")
+	order.AddItem(pos.MenuItem{ID: 3, Name: "Guaraná", Price: 650, Category: "Bebidas"}, 3, "")
+	order.AddItemWithModifiers(pos.MenuItem{ID: 4, Name: "Pizza Calabresa", Price: 4500, Category: "Pizzas"}, 1, "",
+		[]pos.Modifier{{Group: "Tamanho", Option: "Grande", Price: 1500}, {Group: "Borda", Option: "Catupiry", Price: 800}})
+	order.Discount = 500
+	order.ServicePercent = 10
+	order.Finalize(pos.PaymentDinheiro)
+	order.CashReceived = 25000
+	return order
+}
+
+func goldenRestaurant() storage.RestaurantInfo {
+	return storage.RestaurantInfo{
+		Name:    "Pizzaria São João",
+		Address: "Rua das Flores, 123",
+		Phone:   "(11) 5555-0000",
+		Footer:  "Obrigado pela preferência!",
+	}
+}
+
+func TestGoldenTickets(t *testing.T) {
+	receipt := ReceiptData{Restaurant: goldenRestaurant(), Order: goldenOrder(), CharsPerLine: 48}
+	narrow := receipt
+	narrow.CharsPerLine = 32
+
+	summary := SummaryReceiptData{
+		Restaurant: goldenRestaurant(),
+		Summary: pos.ComputeDaySummary("2026-03-14", []pos.Order{
+			*goldenOrder(),
+		}),
+		CharsPerLine: 48,
+	}
+	narrowSummary := summary
+	narrowSummary.CharsPerLine = 32
+	narrowSummary.Summary.ByOperator = []pos.OperatorSales{
+		{OperatorID: 1, Name: "Maria da Conceição Albuquerque", Orders: 1, Revenue: 21153, Service: 1923},
+	}
+
+	opened := time.Date(2026, 3, 14, 17, 0, 0, 0, time.Local)
+	session := &pos.CashSession{
+		ID:           3,
+		Status:       pos.SessionFechada,
+		OpenedAt:     opened,
+		OpenedBy:     "Ana",
+		OpeningFloat: 10000,
+		Movements: []pos.CashMovement{
+			{Kind: pos.MovementSangria, Amount: 5000, Reason: "Deposito no cofre", Operator: "Ana", Time: opened.Add(3 * time.Hour)},
+		},
+		ClosedAt: opened.Add(7 * time.Hour),
+		ClosedBy: "Bruno",
+		Expected: map[pos.PaymentMethod]int64{pos.PaymentDinheiro: 17430, pos.PaymentCartao: 8000},
+		Counted:  map[pos.PaymentMethod]int64{pos.PaymentDinheiro: 17000, pos.PaymentCartao: 8000},
+	}
+	closing := CashClosingData{Restaurant: goldenRestaurant(), Session: session, CharsPerLine: 48}
+
+	session.ZNumber = 12
+	zOrder := *goldenOrder()
+	zOrder.SessionID = session.ID
+	zReport := ShiftReportData{
+		Restaurant:   goldenRestaurant(),
+		Report:       pos.NewShiftReport(session, []pos.Order{zOrder}, 4),
+		CharsPerLine: 48,
+	}
+
+	tests := []struct {
+		name  string
+		data  []byte
+		width int
+	}{
+		{"receipt_48", BuildReceipt(receipt), 48},
+		{"receipt_32", BuildReceipt(narrow), 32},
+		{"kitchen_48", BuildKitchenTicket(receipt), 48},
+		{"summary_48", BuildSummaryReceipt(summary), 48},
+		{"summary_32", BuildSummaryReceipt(narrowSummary), 32},
+		{"cash_closing_48", BuildCashClosingReceipt(closing), 48},
+		{"reducao_z_48", BuildShiftReport(zReport), 48},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ParsePreview(tc.data, tc.width).Text()
+			path := filepath.Join("testdata", tc.name+".golden")
+			if *updateGolden {
+				if err := os.MkdirAll("testdata", 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%v (run go test -update to create it)", err)
+			}
+			if got != string(want) {
+				t.Errorf("%s differs from golden file:\n--- got\n%s\n--- want\n%s", tc.name, got, want)
+			}
+		})
+	}
+}
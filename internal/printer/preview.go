@@ -0,0 +1,365 @@
+package printer
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+type previewAlign int
+
+const (
+	previewLeft previewAlign = iota
+	previewCenter
+	previewRight
+)
+
+type previewKind int
+
+const (
+	previewText previewKind = iota
+	previewImage
+	previewBarcode
+	previewQRCode
+	previewCut
+)
+
+// previewStyle is the character formatting in effect for a run of text.
+type previewStyle struct {
+	bold   bool
+	width  int // horizontal magnification, 1 = normal
+	height int // vertical magnification
+}
+
+type previewSpan struct {
+	text  string
+	style previewStyle
+}
+
+// previewLine is one printed line, or one non-text element (image,
+// barcode, QR code, cut) that occupies its own vertical space.
+type previewLine struct {
+	kind   previewKind
+	align  previewAlign
+	spans  []previewSpan
+	raster *Raster
+	code   string // barcode or QR content
+	hri    HRIPosition
+}
+
+// Preview is the result of interpreting an ESC/POS byte stream the way
+// the printer would. Render it with Text or Image.
+type Preview struct {
+	Width       int // characters per line
+	DrawerKicks int // cash drawer pulses found in the stream
+
+	lines []previewLine
+}
+
+// previewState is the printer state the interpreter tracks between bytes.
+type previewState struct {
+	align   previewAlign
+	style   previewStyle
+	codec   *charmap.Charmap
+	hri     HRIPosition
+	qrData  string
+	current previewLine
+	dirty   bool // current line has content, even if only spaces
+}
+
+// ParsePreview interprets the ESC/POS commands produced by ReceiptBuilder
+// (and BuildReceipt, BuildKitchenTicket, BuildSummaryReceipt) for a paper
+// of charsPerLine columns. Unknown commands are skipped.
+func ParsePreview(data []byte, charsPerLine int) *Preview {
+	if charsPerLine <= 0 {
+		charsPerLine = 48
+	}
+	p := &Preview{Width: charsPerLine}
+	st := &previewState{}
+	st.reset()
+
+	arg := func(i int) int {
+		if i < len(data) {
+			return int(data[i])
+		}
+		return 0
+	}
+
+	i := 0
+	for i < len(data) {
+		b := data[i]
+		switch {
+		case b == 0x0A: // LF
+			p.endLine(st, true)
+			i++
+
+		case b == 0x1B && i+1 < len(data): // ESC
+			switch data[i+1] {
+			case '@':
+				p.endLine(st, false)
+				st.reset()
+				i += 2
+			case 'a':
+				st.align = previewAlign(arg(i+2) % 48)
+				st.current.align = st.align
+				i += 3
+			case 'E':
+				st.style.bold = arg(i+2)&1 == 1
+				i += 3
+			case '!':
+				n := arg(i + 2)
+				st.style.bold = n&0x08 != 0
+				st.style.height = 1 + (n>>4)&1
+				st.style.width = 1 + (n>>5)&1
+				i += 3
+			case 'd':
+				p.endLine(st, false)
+				for n := arg(i + 2); n > 0; n-- {
+					p.lines = append(p.lines, previewLine{kind: previewText, align: st.align})
+				}
+				i += 3
+			case 't':
+				if arg(i+2) == 0x13 {
+					st.codec = charmap.CodePage858
+				} else {
+					st.codec = charmap.CodePage437
+				}
+				i += 3
+			case 'p':
+				p.DrawerKicks++
+				i += 5
+			default:
+				i += 3
+			}
+
+		case b == 0x1D && i+1 < len(data): // GS
+			switch data[i+1] {
+			case 'V':
+				p.endLine(st, false)
+				p.lines = append(p.lines, previewLine{kind: previewCut})
+				if m := arg(i + 2); m == 65 || m == 66 {
+					i += 4
+				} else {
+					i += 3
+				}
+			case '!':
+				n := arg(i + 2)
+				st.style.width = 1 + (n>>4)&7
+				st.style.height = 1 + n&7
+				i += 3
+			case 'H':
+				st.hri = HRIPosition(arg(i+2) % 48)
+				i += 3
+			case 'h', 'w':
+				i += 3
+			case 'v':
+				i = p.parseRaster(data, i, st)
+			case 'k':
+				i = p.parseBarcode(data, i, st)
+			case '(':
+				i = p.parseQRCode(data, i, st)
+			case 'r':
+				i += 3
+			default:
+				i += 3
+			}
+
+		case b == 0x10 && i+1 < len(data) && data[i+1] == 0x04: // DLE EOT
+			i += 3
+
+		case b >= 0x20:
+			st.appendRune(st.codec.DecodeByte(b))
+			i++
+
+		default:
+			i++
+		}
+	}
+	p.endLine(st, false)
+	return p
+}
+
+func (st *previewState) reset() {
+	st.align = previewLeft
+	st.style = previewStyle{width: 1, height: 1}
+	st.codec = charmap.CodePage437
+	st.hri = HRINone
+	st.current = previewLine{kind: previewText}
+	st.dirty = false
+}
+
+func (st *previewState) appendRune(r rune) {
+	spans := st.current.spans
+	if n := len(spans); n > 0 && spans[n-1].style == st.style {
+		spans[n-1].text += string(r)
+	} else {
+		st.current.spans = append(spans, previewSpan{text: string(r), style: st.style})
+	}
+	st.dirty = true
+}
+
+// endLine flushes the text line being built. A bare LF flushes even an
+// empty line, as the printer feeds paper for it.
+func (p *Preview) endLine(st *previewState, force bool) {
+	if st.dirty || force {
+		st.current.kind = previewText
+		p.lines = append(p.lines, st.current)
+	}
+	st.current = previewLine{kind: previewText, align: st.align}
+	st.dirty = false
+}
+
+func (p *Preview) parseRaster(data []byte, i int, st *previewState) int {
+	// GS v 0 m xL xH yL yH d1...dk
+	if i+7 >= len(data) || data[i+2] != '0' && data[i+2] != 0 {
+		return i + 3
+	}
+	stride := int(data[i+4]) | int(data[i+5])<<8
+	rows := int(data[i+6]) | int(data[i+7])<<8
+	start := i + 8
+	end := start + stride*rows
+	if end > len(data) {
+		return len(data)
+	}
+	p.endLine(st, false)
+	raster := &Raster{Width: stride * 8, Height: rows, Data: append([]byte(nil), data[start:end]...)}
+
+	// Consecutive bands of one image come back as a single element.
+	if n := len(p.lines); n > 0 && p.lines[n-1].kind == previewImage && p.lines[n-1].raster.Width == raster.Width {
+		prev := p.lines[n-1].raster
+		prev.Height += raster.Height
+		prev.Data = append(prev.Data, raster.Data...)
+	} else {
+		p.lines = append(p.lines, previewLine{kind: previewImage, align: st.align, raster: raster})
+	}
+	return end
+}
+
+func (p *Preview) parseBarcode(data []byte, i int, st *previewState) int {
+	// Function B: GS k m n d1...dn (m >= 65). Function A (NUL terminated)
+	// is not produced by ReceiptBuilder and is skipped.
+	if i+3 >= len(data) {
+		return len(data)
+	}
+	m := data[i+2]
+	if m < 65 {
+		end := i + 3
+		for end < len(data) && data[end] != 0 {
+			end++
+		}
+		return end + 1
+	}
+	n := int(data[i+3])
+	start := i + 4
+	if start+n > len(data) {
+		return len(data)
+	}
+	p.endLine(st, false)
+	p.lines = append(p.lines, previewLine{
+		kind:  previewBarcode,
+		align: st.align,
+		code:  barcodeText(BarcodeKind(m), data[start:start+n]),
+		hri:   st.hri,
+	})
+	return start + n
+}
+
+// barcodeText recovers the human readable content of a GS k payload.
+func barcodeText(kind BarcodeKind, payload []byte) string {
+	if kind != BarcodeCode128 || len(payload) < 2 || payload[0] != '{' {
+		return string(payload)
+	}
+	if payload[1] == 'C' {
+		var b strings.Builder
+		for _, v := range payload[2:] {
+			b.WriteByte('0' + v/10)
+			b.WriteByte('0' + v%10)
+		}
+		return b.String()
+	}
+	return strings.ReplaceAll(string(payload[2:]), "{{", "{")
+}
+
+func (p *Preview) parseQRCode(data []byte, i int, st *previewState) int {
+	// GS ( k pL pH cn fn [params]
+	if i+6 >= len(data) || data[i+2] != 'k' {
+		if i+4 < len(data) {
+			return i + 5 + (int(data[i+3]) | int(data[i+4])<<8)
+		}
+		return len(data)
+	}
+	size := int(data[i+3]) | int(data[i+4])<<8
+	end := i + 5 + size
+	if end > len(data) {
+		return len(data)
+	}
+	cn, fn := data[i+5], data[i+6]
+	if cn == '1' && size >= 2 {
+		switch {
+		case fn == 'P' && size >= 3: // store data: cn fn m d1...dk
+			st.qrData = string(data[i+8 : end])
+		case fn == 'Q': // print stored symbol
+			p.endLine(st, false)
+			p.lines = append(p.lines, previewLine{kind: previewQRCode, align: st.align, code: st.qrData})
+		}
+	}
+	return end
+}
+
+// Text renders the preview as plain text, one printed line per text line.
+// Double-width characters are followed by a space so columns line up with
+// the paper, images, barcodes and QR codes become bracketed placeholders,
+// and cuts become a dashed line.
+func (p *Preview) Text() string {
+	var b strings.Builder
+	for _, line := range p.lines {
+		var text string
+		switch line.kind {
+		case previewText:
+			text = line.plainText()
+		case previewImage:
+			text = "[IMAGEM]"
+		case previewBarcode:
+			text = "[CODIGO DE BARRAS]"
+			if line.hri != HRINone {
+				text = "[CODIGO DE BARRAS " + line.code + "]"
+			}
+		case previewQRCode:
+			text = "[QR CODE]"
+		case previewCut:
+			b.WriteString(strings.TrimSpace(strings.Repeat("- ", p.Width/2)) + "\n")
+			continue
+		}
+		b.WriteString(strings.TrimRight(alignText(text, line.align, p.Width), " "))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func (l previewLine) plainText() string {
+	var b strings.Builder
+	for _, span := range l.spans {
+		for _, r := range span.text {
+			b.WriteRune(r)
+			for k := 1; k < span.style.width; k++ {
+				b.WriteByte(' ')
+			}
+		}
+	}
+	return b.String()
+}
+
+func alignText(text string, align previewAlign, width int) string {
+	n := utf8.RuneCountInString(text)
+	if n >= width {
+		return text
+	}
+	switch align {
+	case previewCenter:
+		return strings.Repeat(" ", (width-n)/2) + text
+	case previewRight:
+		return strings.Repeat(" ", width-n) + text
+	}
+	return text
+}
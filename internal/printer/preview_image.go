@@ -0,0 +1,313 @@
+package printer
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Preview image geometry: one Font A cell is 12x24 dots, matching the
+// printer, so a 48-column receipt renders 576 pixels wide.
+const (
+	previewCellHeight    = 24
+	previewMargin        = 12
+	previewBarcodeHeight = 60
+	previewQRSize        = 150
+)
+
+var (
+	previewInk   = color.Gray{Y: 0x10}
+	previewPaper = color.White
+)
+
+var (
+	glyphOnce  sync.Once
+	glyphFaces [2]font.Face // regular, bold
+	glyphErr   error
+	glyphMu    sync.Mutex
+	glyphCache = map[glyphKey]*image.Alpha{}
+)
+
+type glyphKey struct {
+	r    rune
+	bold bool
+}
+
+// loadGlyphFaces parses Go Mono at the size whose advance is exactly one
+// cell (Go Mono advances 0.6 em, so 20px gives 12 dots).
+func loadGlyphFaces() error {
+	glyphOnce.Do(func() {
+		for i, ttf := range [][]byte{gomono.TTF, gomonobold.TTF} {
+			f, err := opentype.Parse(ttf)
+			if err != nil {
+				glyphErr = fmt.Errorf("carregar fonte da pre-visualizacao: %w", err)
+				return
+			}
+			face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: 20, DPI: 72, Hinting: font.HintingFull})
+			if err != nil {
+				glyphErr = fmt.Errorf("carregar fonte da pre-visualizacao: %w", err)
+				return
+			}
+			glyphFaces[i] = face
+		}
+	})
+	return glyphErr
+}
+
+// glyph returns the coverage mask of r drawn in one normal-size cell.
+func glyph(r rune, bold bool) *image.Alpha {
+	key := glyphKey{r, bold}
+	glyphMu.Lock()
+	defer glyphMu.Unlock()
+	if g, ok := glyphCache[key]; ok {
+		return g
+	}
+	face := glyphFaces[0]
+	if bold {
+		face = glyphFaces[1]
+	}
+	g := image.NewAlpha(image.Rect(0, 0, DotsPerChar, previewCellHeight))
+	d := font.Drawer{Dst: g, Src: image.Opaque, Face: face, Dot: fixed.P(0, 19)}
+	d.DrawString(string(r))
+	glyphCache[key] = g
+	return g
+}
+
+// Image renders the preview as it would come out of the printer: text in
+// a 12x24 monospaced cell grid, bold and magnified text, raster images
+// dot for dot, barcodes and QR codes as stand-in symbols, and cuts as a
+// dashed line across the paper.
+func (p *Preview) Image() (image.Image, error) {
+	if err := loadGlyphFaces(); err != nil {
+		return nil, err
+	}
+
+	paperW := p.Width * DotsPerChar
+	height := previewMargin * 2
+	for _, line := range p.lines {
+		height += line.pixelHeight()
+	}
+
+	img := image.NewGray(image.Rect(0, 0, paperW+previewMargin*2, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(previewPaper), image.Point{}, draw.Src)
+
+	y := previewMargin
+	for _, line := range p.lines {
+		h := line.pixelHeight()
+		switch line.kind {
+		case previewText:
+			p.drawText(img, line, y, h)
+		case previewImage:
+			x := previewMargin + alignOffset(line.align, paperW, line.raster.Width)
+			drawRaster(img, line.raster, x, y)
+		case previewBarcode:
+			p.drawBarcode(img, line, y)
+		case previewQRCode:
+			x := previewMargin + alignOffset(line.align, paperW, previewQRSize)
+			drawQRPlaceholder(img, line.code, x, y)
+		case previewCut:
+			mid := y + h/2
+			for x := 0; x < img.Bounds().Dx(); x++ {
+				if (x/6)%2 == 0 {
+					img.SetGray(x, mid, previewInk)
+				}
+			}
+		}
+		y += h
+	}
+	return img, nil
+}
+
+// PNG renders the preview with Image and encodes it as PNG.
+func (p *Preview) PNG() ([]byte, error) {
+	img, err := p.Image()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("codificar pre-visualizacao: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (l previewLine) pixelHeight() int {
+	switch l.kind {
+	case previewImage:
+		return l.raster.Height
+	case previewBarcode:
+		h := previewBarcodeHeight
+		if l.hri != HRINone {
+			h += previewCellHeight
+		}
+		return h
+	case previewQRCode:
+		return previewQRSize
+	}
+	rows := 1
+	for _, s := range l.spans {
+		if s.style.height > rows {
+			rows = s.style.height
+		}
+	}
+	return rows * previewCellHeight
+}
+
+func (l previewLine) columns() int {
+	n := 0
+	for _, s := range l.spans {
+		n += len([]rune(s.text)) * s.style.width
+	}
+	return n
+}
+
+// alignOffset returns the left offset in dots of content w dots wide on a
+// paper of paperW dots.
+func alignOffset(align previewAlign, paperW, w int) int {
+	if w >= paperW {
+		return 0
+	}
+	switch align {
+	case previewCenter:
+		return (paperW - w) / 2
+	case previewRight:
+		return paperW - w
+	}
+	return 0
+}
+
+func (p *Preview) drawText(img *image.Gray, line previewLine, top, h int) {
+	paperW := p.Width * DotsPerChar
+	x := previewMargin + alignOffset(line.align, paperW, line.columns()*DotsPerChar)
+	for _, span := range line.spans {
+		sx, sy := span.style.width, span.style.height
+		// Characters sit on the baseline of the tallest span.
+		y := top + h - sy*previewCellHeight
+		for _, r := range span.text {
+			if r != ' ' {
+				drawGlyph(img, glyph(r, span.style.bold), x, y, sx, sy)
+			}
+			x += DotsPerChar * sx
+		}
+	}
+}
+
+// drawGlyph blits the mask magnified sx by sy with nearest-neighbour
+// scaling, as the printer does for ESC ! and GS !.
+func drawGlyph(img *image.Gray, g *image.Alpha, x0, y0, sx, sy int) {
+	b := g.Bounds()
+	for gy := 0; gy < b.Dy(); gy++ {
+		for gx := 0; gx < b.Dx(); gx++ {
+			a := g.AlphaAt(gx, gy).A
+			if a < 0x60 {
+				continue
+			}
+			for dy := 0; dy < sy; dy++ {
+				for dx := 0; dx < sx; dx++ {
+					img.SetGray(x0+gx*sx+dx, y0+gy*sy+dy, previewInk)
+				}
+			}
+		}
+	}
+}
+
+func drawRaster(img *image.Gray, r *Raster, x0, y0 int) {
+	stride := r.BytesPerRow()
+	for y := 0; y < r.Height; y++ {
+		for x := 0; x < r.Width; x++ {
+			if r.Data[y*stride+x/8]&(0x80>>(x%8)) != 0 {
+				img.SetGray(x0+x, y0+y, previewInk)
+			}
+		}
+	}
+}
+
+// drawBarcode draws a stand-in pattern derived from the content, so
+// different codes look different, with the HRI text below when enabled.
+func (p *Preview) drawBarcode(img *image.Gray, line previewLine, top int) {
+	paperW := p.Width * DotsPerChar
+	const module = 2
+	bars := barcodeStripes(line.code)
+	w := len(bars) * module
+	x0 := previewMargin + alignOffset(line.align, paperW, w)
+	for i, black := range bars {
+		if !black {
+			continue
+		}
+		for dx := 0; dx < module; dx++ {
+			for y := 0; y < previewBarcodeHeight; y++ {
+				img.SetGray(x0+i*module+dx, top+y, previewInk)
+			}
+		}
+	}
+	if line.hri != HRINone {
+		text := previewLine{align: line.align, spans: []previewSpan{{text: line.code, style: previewStyle{width: 1, height: 1}}}}
+		p.drawText(img, text, top+previewBarcodeHeight, previewCellHeight)
+	}
+}
+
+func barcodeStripes(code string) []bool {
+	h := fnv.New64a()
+	h.Write([]byte(code))
+	seed := h.Sum64()
+	bars := []bool{true, false, true, true, false}
+	for i := 0; i < len(code)*11; i++ {
+		seed = seed*6364136223846793005 + 1442695040888963407
+		bars = append(bars, seed>>63 == 1)
+	}
+	return append(bars, false, true, true, false, true)
+}
+
+// drawQRPlaceholder draws the three finder squares of a QR symbol and a
+// content-derived module pattern between them.
+func drawQRPlaceholder(img *image.Gray, code string, x0, y0 int) {
+	const modules = 25
+	const size = previewQRSize / modules
+	set := func(mx, my int) {
+		for dy := 0; dy < size; dy++ {
+			for dx := 0; dx < size; dx++ {
+				img.SetGray(x0+mx*size+dx, y0+my*size+dy, previewInk)
+			}
+		}
+	}
+	finder := func(fx, fy int) {
+		for my := 0; my < 7; my++ {
+			for mx := 0; mx < 7; mx++ {
+				ring := mx == 0 || my == 0 || mx == 6 || my == 6
+				core := mx >= 2 && mx <= 4 && my >= 2 && my <= 4
+				if ring || core {
+					set(fx+mx, fy+my)
+				}
+			}
+		}
+	}
+	inFinder := func(mx, my int) bool {
+		return (mx < 8 && my < 8) || (mx >= modules-8 && my < 8) || (mx < 8 && my >= modules-8)
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(code))
+	seed := h.Sum64()
+	for my := 0; my < modules; my++ {
+		for mx := 0; mx < modules; mx++ {
+			seed = seed*6364136223846793005 + 1442695040888963407
+			if !inFinder(mx, my) && seed>>63 == 1 {
+				set(mx, my)
+			}
+		}
+	}
+	finder(0, 0)
+	finder(modules-7, 0)
+	finder(0, modules-7)
+}
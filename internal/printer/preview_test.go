@@ -0,0 +1,94 @@
+package printer
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"notinha/internal/pos"
+)
+
+func TestPreviewText(t *testing.T) {
+	rb := NewReceiptBuilder()
+	rb.AlignCenter().
+		FontDouble().Bold().Line("PIZZA").
+		FontNormal().NoBold().Line("Pão de açúcar €").
+		AlignLeft().Line("esquerda").
+		AlignRight().Line("direita").
+		Feed(1).
+		PartialCut()
+
+	got := ParsePreview(rb.Build(), 20).Text()
+	want := "" +
+		"     P I Z Z A\n" +
+		"  Pão de açúcar €\n" +
+		"esquerda\n" +
+		"             direita\n" +
+		"\n" +
+		"- - - - - - - - - -\n"
+	if got != want {
+		t.Errorf("Text() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPreviewReceiptElements(t *testing.T) {
+	data := pixTestData(pos.PaymentPix)
+	preview := ParsePreview(BuildReceipt(data), data.CharsPerLine)
+	text := preview.Text()
+
+	for _, want := range []string{"Portuguesa", "[QR CODE]", "[CODIGO DE BARRAS 000042]"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("preview should contain %q, got:\n%s", want, text)
+		}
+	}
+	if strings.ContainsRune(text, 0x1B) || strings.ContainsRune(text, 0x1D) {
+		t.Error("preview text should not contain control bytes")
+	}
+}
+
+func TestPreviewRasterBandsMerge(t *testing.T) {
+	r := &Raster{Width: 16, Height: rasterBandRows + 10}
+	r.Data = make([]byte, r.BytesPerRow()*r.Height)
+	preview := ParsePreview(NewReceiptBuilder().Raster(r).Build(), 48)
+
+	if len(preview.lines) != 1 || preview.lines[0].kind != previewImage {
+		t.Fatalf("expected a single image, got %d lines", len(preview.lines))
+	}
+	if got := preview.lines[0].raster.Height; got != r.Height {
+		t.Errorf("merged raster height = %d, want %d", got, r.Height)
+	}
+}
+
+func TestPreviewMalformedInput(t *testing.T) {
+	// A QR store command too short to carry the m byte.
+	short := []byte{0x1D, '(', 'k', 2, 0, '1', 'P', 'o', 'k'}
+	if got := ParsePreview(short, 48).Text(); got != "ok\n" {
+		t.Errorf("Text() = %q, want %q", got, "ok\n")
+	}
+
+	// Jobs cut short anywhere must not panic.
+	job := BuildReceipt(pixTestData(pos.PaymentPix))
+	for n := range job {
+		ParsePreview(job[:n], 48)
+	}
+}
+
+func TestPreviewPNG(t *testing.T) {
+	rb := NewReceiptBuilder().Line("Teste").FontDouble().Line("Grande").PartialCut()
+	data, err := ParsePreview(rb.Build(), 48).PNG()
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := img.Bounds().Dx(); w != 48*DotsPerChar+2*previewMargin {
+		t.Errorf("width = %d, want %d", w, 48*DotsPerChar+2*previewMargin)
+	}
+	// Two text lines (one double height) plus the cut line.
+	if h := img.Bounds().Dy(); h != 4*previewCellHeight+2*previewMargin {
+		t.Errorf("height = %d, want %d", h, 4*previewCellHeight+2*previewMargin)
+	}
+}
@@ -2,9 +2,10 @@ package printer
 
 import (
 	"fmt"
-	"strings"
+	"log"
 	"time"
 
+	"notinha/internal/pix"
 	"notinha/internal/pos"
 	"notinha/internal/storage"
 )
@@ -14,6 +15,9 @@ type ReceiptData struct {
 	Restaurant   storage.RestaurantInfo
 	Order        *pos.Order
 	CharsPerLine int
+
+	// Reprint, when set, marks the copy as a 2a via printed at that time.
+	Reprint time.Time
 }
 
 // BuildReceipt constructs a full receipt and returns the ESC/POS bytes.
@@ -23,50 +27,36 @@ func BuildReceipt(data ReceiptData) []byte {
 		w = 48
 	}
 
-	rb := NewReceiptBuilder()
-
-	// Header
-	rb.AlignCenter().
-		FontDouble().Bold().
-		Line(data.Restaurant.Name).
-		FontNormal().NoBold()
-
-	if data.Restaurant.Address != "" {
-		rb.Line(data.Restaurant.Address)
-	}
-	if data.Restaurant.Phone != "" {
-		rb.Line("Tel: " + data.Restaurant.Phone)
-	}
-	if data.Restaurant.CNPJ != "" {
-		rb.Line("CNPJ: " + data.Restaurant.CNPJ)
-	}
+	rb := NewReceiptBuilder().PaperWidth(w * DotsPerChar)
+	writeReceiptHeader(rb, data.Restaurant, w)
 
 	rb.Separator('-', w)
+	if writeReprintMark(rb, data.Reprint) {
+		rb.Separator('-', w)
+	}
 
 	// Order info
-	rb.AlignLeft().
-		Line(formatDateTime(data.Order.CreatedAt)).
-		Line(fmt.Sprintf("Pedido: #%d", data.Order.Number))
-
-	if data.Order.Customer != "" {
-		rb.Line("Cliente: " + data.Order.Customer)
-	}
-	if data.Order.Table != "" {
-		rb.Line("Mesa: " + data.Order.Table)
-	}
+	writeOrderInfo(rb, data.Order)
 
 	rb.Separator('-', w)
 
 	// Column header
-	rb.Bold().Line(formatItemLine("QTD", "ITEM", "VALOR", w)).NoBold()
+	items := itemTable(w)
+	rb.Bold().Row(items, "QTD", "ITEM", "VALOR").NoBold()
 
 	// Items
 	for _, oi := range data.Order.Items {
 		qty := fmt.Sprintf("%dx", oi.Quantity)
 		price := pos.FormatBRL(oi.Total())
-		rb.Line(formatItemLine(qty, oi.Item.Name, price, w))
+		rb.Row(items, qty, oi.Item.Name, price)
+		writeModifiers(rb, oi.Modifiers, w, true)
+		writeComponents(rb, oi.Components, w, true)
+		if oi.PriceRule != "" {
+			rb.Row(noteTable(w), "  $", fmt.Sprintf("%s: %s (de %s)",
+				oi.PriceRule, pos.FormatBRL(oi.Item.Price), pos.FormatBRL(oi.RegularPrice)))
+		}
 		if oi.Notes != "" {
-			rb.Line("  * " + oi.Notes)
+			rb.Row(noteTable(w), "  *", oi.Notes)
 		}
 	}
 
@@ -76,8 +66,12 @@ func BuildReceipt(data ReceiptData) []byte {
 	subtotal := data.Order.Subtotal()
 	rb.Line(formatTotalLine("Subtotal:", pos.FormatBRL(subtotal), w))
 
-	if data.Order.Discount > 0 {
-		rb.Line(formatTotalLine("Desconto:", "-"+pos.FormatBRL(data.Order.Discount), w))
+	if discount := data.Order.EffectiveDiscount(); discount > 0 {
+		rb.Line(formatTotalLine("Desconto:", "-"+pos.FormatBRL(discount), w))
+	}
+	if service := data.Order.ServiceCharge(); service > 0 {
+		label := fmt.Sprintf("Servico (%d%%):", data.Order.ServicePercent)
+		rb.Line(formatTotalLine(label, pos.FormatBRL(service), w))
 	}
 
 	rb.Bold().
@@ -102,11 +96,20 @@ func BuildReceipt(data ReceiptData) []byte {
 		rb.Line(formatTotalLine("Troco:", pos.FormatBRL(data.Order.CashChange()), w))
 	}
 
+	writePixQRCode(rb, data, data.Order.EffectivePayments(), w)
+
+	// Order number barcode, scanned by history lookup and reprint.
+	rb.Separator('-', w).
+		AlignCenter().
+		BarcodeHeight(60).BarcodeWidth(2).BarcodeHRI(HRIBelow).
+		Barcode(BarcodeCode128, OrderBarcode(data.Order.Number)).
+		AlignLeft()
+
 	// Footer
 	if data.Restaurant.Footer != "" {
 		rb.Separator('-', w).
 			AlignCenter().
-			Line(data.Restaurant.Footer)
+			Row(textTable(w), data.Restaurant.Footer)
 	}
 
 	rb.Feed(4).PartialCut()
@@ -114,52 +117,160 @@ func BuildReceipt(data ReceiptData) []byte {
 	return rb.Build()
 }
 
-func formatItemLine(qty, name, price string, width int) string {
-	qtyWidth := 5
-	priceWidth := 12
-	nameWidth := width - qtyWidth - priceWidth
+// writeReprintMark flags a copy printed again from the history, with the
+// time of the reprint. It reports whether anything was written.
+func writeReprintMark(rb *ReceiptBuilder, at time.Time) bool {
+	if at.IsZero() {
+		return false
+	}
+	rb.AlignCenter().
+		FontDouble().Bold().Line("2ª VIA").FontNormal().NoBold().
+		Line("Reimpresso em " + formatDateTime(at)).
+		AlignLeft()
+	return true
+}
 
-	qty = padRight(qty, qtyWidth)
-	name = truncate(name, nameWidth)
-	name = padRight(name, nameWidth)
-	price = padLeft(price, priceWidth)
+// writeModifiers lists the options chosen for an item under it, with
+// their price when withPrice is set.
+func writeModifiers(rb *ReceiptBuilder, mods []pos.Modifier, w int, withPrice bool) {
+	for _, m := range mods {
+		text := m.String()
+		if withPrice && m.Price != 0 {
+			text += " (" + formatSignedBRL(m.Price) + ")"
+		}
+		rb.Row(noteTable(w), "  +", text)
+	}
+}
 
-	return qty + name + price
+// writeComponents lists the items bundled in a combo under it, with their
+// own options and notes.
+func writeComponents(rb *ReceiptBuilder, components []pos.OrderItem, w int, withPrice bool) {
+	for _, c := range components {
+		rb.Row(noteTable(w), "  -", fmt.Sprintf("%dx %s", c.Quantity, c.Item.Name))
+		writeModifiers(rb, c.Modifiers, w, withPrice)
+		if c.Notes != "" {
+			rb.Row(noteTable(w), "  *", c.Notes)
+		}
+	}
 }
 
-func formatTotalLine(label, value string, width int) string {
-	valueWidth := len(value)
-	labelWidth := width - valueWidth
-	if labelWidth < 0 {
-		labelWidth = 0
+// writeReceiptHeader prints the logo, name and contact lines of the
+// restaurant.
+func writeReceiptHeader(rb *ReceiptBuilder, r storage.RestaurantInfo, w int) {
+	rb.AlignCenter()
+	if r.Logo != "" {
+		if logo, err := LoadLogo(r.Logo, w*DotsPerChar); err == nil {
+			rb.Raster(logo)
+		} else {
+			log.Printf("Logo nao impresso: %v", err)
+		}
+	}
+	writeTitle(rb, r.Name, w)
+
+	if r.Address != "" {
+		rb.Row(textTable(w), r.Address)
+	}
+	if r.Phone != "" {
+		rb.Row(textTable(w), "Tel: "+r.Phone)
+	}
+	if r.CNPJ != "" {
+		rb.Row(textTable(w), "CNPJ: "+r.CNPJ)
 	}
-	return padRight(label, labelWidth) + value
 }
 
-func formatDateTime(t time.Time) string {
-	return t.Format("02/01/2006 15:04")
+// writeTitle prints text in double size, wrapped at the half as many
+// characters that fit on the paper. The alignment is left as it is.
+func writeTitle(rb *ReceiptBuilder, text string, w int) {
+	rb.FontDouble().Bold().
+		Row(textTable(w/2), text).
+		FontNormal().NoBold()
 }
 
-func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
+// writeOrderInfo prints the order date, number, customer and table.
+func writeOrderInfo(rb *ReceiptBuilder, o *pos.Order) {
+	rb.AlignLeft().
+		Line(formatDateTime(o.CreatedAt)).
+		Line(fmt.Sprintf("Pedido: #%d", o.Number))
+
+	if o.Customer != "" {
+		rb.Line("Cliente: " + o.Customer)
 	}
-	if maxLen <= 0 {
-		return ""
+	if o.Table != "" {
+		rb.Line("Mesa: " + o.Table)
 	}
-	return s[:maxLen]
 }
 
-func padRight(s string, width int) string {
-	if len(s) >= width {
-		return s
+// writePixQRCode prints a static PIX QR code for the part of payments made
+// with PIX, when a PIX key is configured.
+func writePixQRCode(rb *ReceiptBuilder, data ReceiptData, payments []pos.PaymentSplit, w int) {
+	var amount int64
+	for _, p := range payments {
+		if p.Method == pos.PaymentPix {
+			amount += p.Amount
+		}
+	}
+	if amount <= 0 || data.Restaurant.PixKey == "" {
+		return
 	}
-	return s + strings.Repeat(" ", width-len(s))
-}
 
-func padLeft(s string, width int) string {
-	if len(s) >= width {
-		return s
+	name := data.Restaurant.PixName
+	if name == "" {
+		name = data.Restaurant.Name
 	}
-	return strings.Repeat(" ", width-len(s)) + s
+	code, err := pix.Payload{
+		Key:    data.Restaurant.PixKey,
+		Name:   name,
+		City:   data.Restaurant.PixCity,
+		Amount: amount,
+		TxID:   fmt.Sprintf("PEDIDO%d", data.Order.Number),
+	}.Encode()
+	if err != nil {
+		log.Printf("QR PIX nao gerado: %v", err)
+		return
+	}
+
+	rb.Separator('-', w).
+		AlignCenter().
+		Bold().Line("Pague com PIX: "+pos.FormatBRL(amount)).NoBold().
+		QRCode(code, 6, QRCorrectionM).
+		Feed(1).
+		Line("Chave: " + data.Restaurant.PixKey).
+		AlignLeft()
+}
+
+// itemTable is the QTD / ITEM / VALOR layout of the receipt body. Long
+// item names wrap under the ITEM column.
+func itemTable(width int) Table {
+	return Table{Width: width, Columns: []Column{
+		{Width: 5},
+		{Wrap: true},
+		{Width: 12, Align: TextRight},
+	}}
+}
+
+// noteTable indents item notes, wrapping them under their own start.
+func noteTable(width int) Table {
+	return Table{Width: width, Columns: []Column{{Width: 4}, {Wrap: true}}}
+}
+
+// textTable is a single column that wraps text at the paper width.
+func textTable(width int) Table {
+	return Table{Width: width, Columns: []Column{{Wrap: true}}}
+}
+
+// writeTotalRow is formatTotalLine for labels that can be long, such as
+// operator names: the label wraps above the value instead of being cut.
+func writeTotalRow(rb *ReceiptBuilder, label, value string, w int) {
+	rb.Row(Table{Width: w, Columns: []Column{{Wrap: true}, {Width: TextWidth(value) + 1, Align: TextRight}}}, label, value)
+}
+
+// formatTotalLine puts label on the left and value flush right, cutting
+// the label if both do not fit.
+func formatTotalLine(label, value string, width int) string {
+	t := Table{Width: width, Columns: []Column{{}, {Width: TextWidth(value), Align: TextRight}}}
+	return t.Format(label, value)[0]
+}
+
+func formatDateTime(t time.Time) string {
+	return t.Format("02/01/2006 15:04")
 }
@@ -0,0 +1,217 @@
+package printer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"notinha/internal/pos"
+	"notinha/internal/storage"
+)
+
+func pixTestData(payment pos.PaymentMethod) ReceiptData {
+	order := pos.NewOrder(42)
+	order.AddItem(pos.MenuItem{ID: 1, Name: "Portuguesa", Price: 5300}, 1, "")
+	order.Finalize(payment)
+	return ReceiptData{
+		Restaurant: storage.RestaurantInfo{
+			Name:    "Pizzaria Teste",
+			PixKey:  "pizzaria@exemplo.com",
+			PixCity: "Sao Paulo",
+		},
+		Order:        order,
+		CharsPerLine: 48,
+	}
+}
+
+func TestBuildReceiptPixQRCode(t *testing.T) {
+	receipt := BuildReceipt(pixTestData(pos.PaymentPix))
+
+	store := []byte{0x1D, 0x28, 0x6B}
+	if !bytes.Contains(receipt, store) {
+		t.Fatal("PIX receipt should contain a GS ( k QR code")
+	}
+	if !bytes.Contains(receipt, []byte("540553.00")) {
+		t.Error("QR payload should carry the order total")
+	}
+	if !bytes.Contains(receipt, []byte("PEDIDO42")) {
+		t.Error("QR payload should reference the order number")
+	}
+}
+
+func TestBuildReceiptNoQRCodeForCash(t *testing.T) {
+	receipt := BuildReceipt(pixTestData(pos.PaymentDinheiro))
+	if bytes.Contains(receipt, []byte("br.gov.bcb.pix")) {
+		t.Error("cash receipt should not contain a PIX QR code")
+	}
+}
+
+func TestBuildSubBillReceipt(t *testing.T) {
+	order := pos.NewOrder(7)
+	order.Table = "5"
+	order.AddItem(pos.MenuItem{ID: 1, Name: "Pizza Grande", Price: 6000}, 1, "")
+	order.AddItem(pos.MenuItem{ID: 2, Name: "Chopp", Price: 1200}, 2, "")
+	order.Discount = 840
+	if err := order.SplitByItems(2, [][]int{{1, 1}, {0, 2}}); err != nil {
+		t.Fatal(err)
+	}
+	bill := order.SubBills[0]
+	if err := order.PaySubBill(0, []pos.PaymentSplit{{Method: pos.PaymentDinheiro, Amount: bill.Amount}}, 3000); err != nil {
+		t.Fatal(err)
+	}
+
+	data := ReceiptData{Restaurant: storage.RestaurantInfo{Name: "Pizzaria Teste"}, Order: order, CharsPerLine: 48}
+	text := ParsePreview(BuildSubBillReceipt(data, order.SubBills[0]), 48).Text()
+	for _, want := range []string{
+		"CONTA 1/2",
+		"Pizza Grande (1/2)",
+		"Desconto (rateio):",
+		"TOTAL:                                  R$ 27,00",
+		"Troco:                                   R$ 3,00",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("sub-bill receipt missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestBuildReceiptListsComboComponents(t *testing.T) {
+	pizza := pos.MenuItem{ID: 1, Name: "Calabresa", Price: 4500, Category: "Pizzas Tradicionais"}
+	cola := pos.MenuItem{ID: 2, Name: "Coca-Cola 2L", Price: 1400, Category: "Refrigerantes"}
+	order := pos.NewOrder(7)
+	order.AddItem(pizza, 1, "")
+	order.AddItem(cola, 1, "")
+	combo := pos.Combo{Name: "Pizza + Refri", Price: 5200, Slots: []pos.ComboSlot{
+		{Categories: []string{"Pizzas *"}, Quantity: 1},
+		{ItemIDs: []int{2}, Quantity: 1},
+	}}
+	if err := order.ApplyCombo(combo); err != nil {
+		t.Fatal(err)
+	}
+	order.Finalize(pos.PaymentCartao)
+
+	text := ParsePreview(BuildReceipt(ReceiptData{Order: order, CharsPerLine: 48}), 48).Text()
+	for _, want := range []string{"1x   Pizza + Refri", "R$ 52,00", "  - 1x Calabresa", "  - 1x Coca-Cola 2L"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("receipt missing %q:\n%s", want, text)
+		}
+	}
+
+	groups := GroupByStation(order.Items, func(category string) string {
+		if category == "Refrigerantes" {
+			return storage.BarPrinter
+		}
+		return storage.KitchenPrinter
+	})
+	if len(groups) != 2 || groups[0].Items[0].Item.Name != "Calabresa" || groups[1].Items[0].Item.Name != "Coca-Cola 2L" {
+		t.Errorf("combo should be split across stations: %+v", groups)
+	}
+}
+
+func TestBuildReceiptShowsPriceRule(t *testing.T) {
+	order := pos.NewOrder(7)
+	order.Items = []pos.OrderItem{{
+		Item:         pos.MenuItem{ID: 1, Name: "Chopp 300ml", Price: 900, Category: "Chopp"},
+		Quantity:     2,
+		PriceRule:    "Happy Hour",
+		RegularPrice: 1200,
+	}}
+	order.Finalize(pos.PaymentCartao)
+
+	text := ParsePreview(BuildReceipt(ReceiptData{Order: order, CharsPerLine: 48}), 48).Text()
+	for _, want := range []string{"R$ 18,00", "  $ Happy Hour: R$ 9,00 (de R$ 12,00)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("receipt missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestBuildReceiptCapsDiscountAtSubtotal(t *testing.T) {
+	order := pos.NewOrder(7)
+	order.AddItem(pos.MenuItem{ID: 1, Name: "Refrigerante", Price: 700}, 1, "")
+	order.Discount = 1000
+	order.Finalize(pos.PaymentDinheiro)
+
+	text := ParsePreview(BuildReceipt(ReceiptData{Order: order, CharsPerLine: 48}), 48).Text()
+	for _, want := range []string{
+		"Subtotal:                                R$ 7,00",
+		"Desconto:                               -R$ 7,00",
+		"TOTAL:                                   R$ 0,00",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("receipt missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestBuildRefundReceipt(t *testing.T) {
+	order := pos.NewOrder(8)
+	order.AddItem(pos.MenuItem{ID: 1, Name: "Calabresa", Price: 4500}, 2, "")
+	order.Finalize(pos.PaymentPix)
+
+	refund, err := order.Refund(pos.Refund{
+		Items:        []pos.RefundItem{{Line: 0, Quantity: 1}},
+		Method:       pos.PaymentPix,
+		Reason:       "pizza queimada",
+		AuthorizedBy: "Gerente",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := ParsePreview(BuildRefundReceipt(ReceiptData{Order: order, CharsPerLine: 48}, refund), 48).Text()
+	for _, want := range []string{"E S T O R N O", "1x", "Calabresa", "R$ 45,00", "Devolvido em:", "pizza queimada", "Autorizado por: Gerente"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("voucher missing %q:\n%s", want, text)
+		}
+	}
+
+	refund, err = order.CancelPaid(pos.Refund{Method: pos.PaymentPix, Reason: "cliente desistiu"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text = ParsePreview(BuildRefundReceipt(ReceiptData{Order: order, CharsPerLine: 48}, refund), 48).Text()
+	if !strings.Contains(text, "V E N D A   C A N C E L A D A") {
+		t.Errorf("cancellation voucher missing title:\n%s", text)
+	}
+}
+
+func TestReprintIsMarkedAsSecondCopy(t *testing.T) {
+	order := pos.NewOrder(9)
+	order.AddItem(pos.MenuItem{ID: 1, Name: "Calabresa", Price: 4500, Category: "Pizzas"}, 1, "")
+	order.Finalize(pos.PaymentCartao)
+	data := ReceiptData{Order: order, CharsPerLine: 48}
+
+	if text := ParsePreview(BuildReceipt(data), 48).Text(); strings.Contains(text, "V I A") {
+		t.Errorf("original receipt marked as a copy:\n%s", text)
+	}
+
+	data.Reprint = time.Date(2024, 3, 9, 10, 30, 0, 0, time.Local)
+	for name, job := range map[string][]byte{
+		"receipt": BuildReceipt(data),
+		"ticket":  BuildStationTicket(data, "cozinha", order.Items),
+	} {
+		text := ParsePreview(job, 48).Text()
+		for _, want := range []string{"2 ª   V I A", "Reimpresso em 09/03/2024 10:30"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("%s missing %q:\n%s", name, want, text)
+			}
+		}
+	}
+}
+
+func TestSummaryBreaksDownByOperator(t *testing.T) {
+	s := pos.DaySummary{
+		Date: "2026-03-14",
+		ByOperator: []pos.OperatorSales{
+			{OperatorID: 1, Name: "Ana", Orders: 3, Revenue: 12000, Discounts: 500},
+			{OperatorID: 2, Name: "Bia", Refunds: 1, RefundTotal: 3000},
+		},
+	}
+	text := ParsePreview(BuildSummaryReceipt(SummaryReceiptData{Summary: s, CharsPerLine: 48}), 48).Text()
+	for _, want := range []string{"POR OPERADOR", "Ana (3):", "R$ 120,00", "Descontos:", "Bia (0):", "Estornos (1):"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("summary missing %q:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package printer
+
+import (
+	"fmt"
+
+	"notinha/internal/pos"
+)
+
+// BuildRefundReceipt prints the voucher of a refund (estorno) or of the
+// cancellation of a paid order: the units given back, the amount, how it
+// was returned and why, with signature lines.
+func BuildRefundReceipt(data ReceiptData, r pos.Refund) []byte {
+	w := data.CharsPerLine
+	if w <= 0 {
+		w = 48
+	}
+	o := data.Order
+
+	rb := NewReceiptBuilder().PaperWidth(w * DotsPerChar)
+	writeReceiptHeader(rb, data.Restaurant, w)
+	rb.Separator('-', w)
+
+	// The refund that gives back the last units cancels the sale.
+	title := "ESTORNO"
+	if last := len(o.Refunds) - 1; o.Status == pos.StatusCancelado && last >= 0 && o.Refunds[last].Time.Equal(r.Time) {
+		title = "VENDA CANCELADA"
+	}
+	rb.AlignCenter()
+	writeTitle(rb, title, w)
+	rb.Line(formatDateTime(r.Time))
+	rb.Separator('-', w)
+
+	writeOrderInfo(rb, o)
+	if !o.ClosedAt.IsZero() {
+		rb.Line("Pago em: " + formatDateTime(o.ClosedAt))
+	}
+	rb.Separator('-', w)
+
+	items := itemTable(w)
+	rb.Bold().Row(items, "QTD", "ITEM", "VALOR").NoBold()
+	for _, ri := range r.Items {
+		oi := o.Items[ri.Line]
+		rb.Row(items, fmt.Sprintf("%dx", ri.Quantity), oi.Item.Name,
+			pos.FormatBRL(oi.UnitPrice()*int64(ri.Quantity)))
+		writeModifiers(rb, oi.Modifiers, w, false)
+		writeComponents(rb, oi.Components, w, false)
+	}
+	rb.Separator('-', w)
+
+	rb.Line(formatTotalLine("Total do pedido:", pos.FormatBRL(o.Total()), w))
+	if r.Service > 0 {
+		rb.Line(formatTotalLine("Servico estornado:", pos.FormatBRL(r.Service), w))
+	}
+	rb.Bold().
+		Line(formatTotalLine("VALOR ESTORNADO:", pos.FormatBRL(r.Amount), w)).
+		NoBold()
+	rb.Line(formatTotalLine("Devolvido em:", string(r.Method), w))
+	rb.Separator('-', w)
+
+	rb.Row(Table{Width: w, Columns: []Column{{Width: 8}, {Wrap: true}}}, "Motivo:", r.Reason)
+	if r.AuthorizedBy != "" {
+		rb.Line("Autorizado por: " + r.AuthorizedBy)
+	}
+
+	rb.Feed(3).
+		AlignCenter().
+		Line("________________________________").
+		Line("Cliente").
+		Feed(2).
+		Line("________________________________").
+		Line("Responsavel").
+		AlignLeft()
+
+	rb.Feed(4).PartialCut()
+
+	return rb.Build()
+}
@@ -0,0 +1,102 @@
+package printer
+
+import (
+	"fmt"
+
+	"notinha/internal/pos"
+	"notinha/internal/storage"
+)
+
+// ShiftReportData holds an X or Z report for printing.
+type ShiftReportData struct {
+	Restaurant   storage.RestaurantInfo
+	Report       pos.ShiftReport
+	CharsPerLine int
+}
+
+// BuildShiftReport prints a leitura X (partial) or reducao Z (closing)
+// report for one shift.
+func BuildShiftReport(data ShiftReportData) []byte {
+	w := data.CharsPerLine
+	if w <= 0 {
+		w = 48
+	}
+	r := data.Report
+	s := r.Summary
+
+	rb := NewReceiptBuilder()
+
+	rb.AlignCenter()
+	writeTitle(rb, data.Restaurant.Name, w)
+	if data.Restaurant.CNPJ != "" {
+		rb.Row(textTable(w), "CNPJ: "+data.Restaurant.CNPJ)
+	}
+	rb.Separator('-', w)
+
+	title := "LEITURA X"
+	if r.Kind == pos.ReportZ {
+		title = fmt.Sprintf("REDUCAO Z #%04d", r.Number)
+	}
+	writeTitle(rb, title, w)
+	if r.Kind == pos.ReportX {
+		rb.Line("Parcial - nao encerra o caixa")
+	}
+	rb.Separator('-', w)
+
+	rb.AlignLeft()
+	rb.Line(formatTotalLine("Dia de movimento:", pos.FormatDateBR(r.BusinessDate), w))
+	rb.Line(formatTotalLine("Caixa:", fmt.Sprintf("#%d", r.SessionID), w))
+	rb.Line(formatTotalLine("Inicio:", formatDateTime(r.From), w))
+	rb.Line(formatTotalLine("Fim:", formatDateTime(r.To), w))
+	if r.Operator != "" {
+		rb.Line(formatTotalLine("Operador:", r.Operator, w))
+	}
+	rb.Separator('-', w)
+
+	rb.Line(formatTotalLine("Pedidos finalizados:", fmt.Sprintf("%d", s.FinalizedOrders), w))
+	rb.Line(formatTotalLine("Pedidos cancelados:", fmt.Sprintf("%d", s.CancelledOrders), w))
+	rb.Bold().
+		Line(formatTotalLine("VENDAS:", pos.FormatBRL(s.TotalRevenue), w)).
+		NoBold()
+	if s.Refunds > 0 {
+		rb.Line(formatTotalLine(fmt.Sprintf("Estornos (%d):", s.Refunds), "-"+pos.FormatBRL(s.RefundTotal), w))
+	}
+	rb.Line(formatTotalLine("Ticket medio:", pos.FormatBRL(s.AverageTicket), w))
+	if s.ServiceTotal > 0 {
+		rb.Line(formatTotalLine("Taxa de servico:", pos.FormatBRL(s.ServiceTotal), w))
+	}
+	rb.Separator('-', w)
+
+	rb.AlignCenter().
+		Bold().Line("POR FORMA DE PAGAMENTO").NoBold()
+	rb.AlignLeft()
+	for _, pm := range pos.PaymentMethods() {
+		count := s.OrdersByPayment[pm]
+		if count == 0 && s.ByPayment[pm] == 0 {
+			continue
+		}
+		label := fmt.Sprintf("%s (%d):", pm, count)
+		rb.Line(formatTotalLine(label, pos.FormatBRL(s.ByPayment[pm]), w))
+	}
+	rb.Separator('-', w)
+
+	rb.AlignCenter().
+		Bold().Line("DINHEIRO EM CAIXA").NoBold()
+	rb.AlignLeft()
+	rb.Line(formatTotalLine("Fundo de troco:", pos.FormatBRL(r.OpeningFloat), w))
+	rb.Line(formatTotalLine("Vendas em dinheiro:", pos.FormatBRL(s.ByPayment[pos.PaymentDinheiro]), w))
+	rb.Line(formatTotalLine("Suprimentos:", pos.FormatBRL(r.Suprimentos), w))
+	rb.Line(formatTotalLine("Sangrias:", "-"+pos.FormatBRL(r.Sangrias), w))
+	rb.Bold().
+		Line(formatTotalLine("ESPERADO:", pos.FormatBRL(r.ExpectedCash), w)).
+		NoBold()
+	if r.DrawerOpenings > 0 {
+		rb.Line(formatTotalLine("Aberturas de gaveta:", fmt.Sprintf("%d", r.DrawerOpenings), w))
+	}
+
+	writeOperatorSales(rb, s.ByOperator, w)
+
+	rb.Feed(4).PartialCut()
+
+	return rb.Build()
+}
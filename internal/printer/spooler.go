@@ -0,0 +1,353 @@
+package printer
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+type JobState string
+
+const (
+	JobPending JobState = "Pendente"
+	JobPrinted JobState = "Impresso"
+	JobFailed  JobState = "Falhou"
+)
+
+// Job is one print job waiting in (or already through) the spooler.
+type Job struct {
+	ID          int       `json:"id"`
+	Printer     string    `json:"printer"` // printer name resolved at print time (caixa, cozinha, ...)
+	Label       string    `json:"label"`
+	Data        []byte    `json:"data"`
+	State       JobState  `json:"state"`
+	Attempts    int       `json:"attempts"`
+	LastError   string    `json:"last_error,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	NextAttempt time.Time `json:"next_attempt,omitempty"`
+}
+
+// Spooler retry policy. A job is retried with exponential backoff, starting
+// at spoolBaseDelay and capped at spoolMaxDelay, and marked failed after
+// spoolMaxAttempts. Printed jobs are kept for spoolKeepPrinted so the
+// operator can still re-send a recent ticket.
+var (
+	spoolBaseDelay   = 2 * time.Second
+	spoolMaxDelay    = time.Minute
+	spoolMaxAttempts = 10
+	spoolKeepPrinted = 24 * time.Hour
+	spoolPollEvery   = time.Second
+)
+
+// Spooler is a persistent print queue. Jobs are written to disk before
+// they are printed, so a ticket survives an unplugged printer, a paper
+// change or an app restart.
+type Spooler struct {
+	path    string
+	resolve func(name string) *Printer
+
+	// OnChange, if set, is called from the spooler goroutine after any job
+	// changes state.
+	OnChange func(Job)
+
+	mu     sync.Mutex
+	jobs   []Job
+	nextID int
+	now    func() time.Time
+	wake   chan struct{}
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// NewSpooler loads the queue persisted at path. resolve maps a job's printer
+// name to the device it should be written to; it may return nil when that
+// printer is not connected, in which case the job waits.
+func NewSpooler(path string, resolve func(name string) *Printer) (*Spooler, error) {
+	s := &Spooler{
+		path:    path,
+		resolve: resolve,
+		now:     time.Now,
+		wake:    make(chan struct{}, 1),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return s, err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &s.jobs); err != nil {
+			return s, fmt.Errorf("fila de impressao corrompida: %w", err)
+		}
+	}
+	for _, j := range s.jobs {
+		if j.ID > s.nextID {
+			s.nextID = j.ID
+		}
+	}
+	return s, nil
+}
+
+// Start launches the goroutine that drains the queue.
+func (s *Spooler) Start() {
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go s.run()
+}
+
+// Stop halts the queue goroutine. Pending jobs stay on disk.
+func (s *Spooler) Stop() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	<-s.done
+	s.stop = nil
+}
+
+// Enqueue adds a job for the named printer and persists it before
+// returning.
+func (s *Spooler) Enqueue(printerName, label string, data []byte) Job {
+	s.mu.Lock()
+	s.nextID++
+	now := s.now()
+	job := Job{
+		ID:        s.nextID,
+		Printer:   printerName,
+		Label:     label,
+		Data:      data,
+		State:     JobPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.jobs = append(s.jobs, job)
+	s.saveLocked()
+	s.mu.Unlock()
+
+	s.notify(job)
+	s.Wake()
+	return job
+}
+
+// Wake asks the queue goroutine to try pending jobs now, e.g. after the
+// printer was reconnected.
+func (s *Spooler) Wake() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Jobs returns a snapshot of the queue, newest first.
+func (s *Spooler) Jobs() []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]Job, len(s.jobs))
+	copy(jobs, s.jobs)
+	sort.SliceStable(jobs, func(i, j int) bool { return jobs[i].ID > jobs[j].ID })
+	return jobs
+}
+
+// Pending returns how many jobs are still waiting to print.
+func (s *Spooler) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, j := range s.jobs {
+		if j.State == JobPending {
+			n++
+		}
+	}
+	return n
+}
+
+// Retry puts a failed or already printed job back in the queue.
+func (s *Spooler) Retry(id int) error {
+	s.mu.Lock()
+	i := s.indexLocked(id)
+	if i < 0 {
+		s.mu.Unlock()
+		return fmt.Errorf("trabalho de impressao %d nao encontrado", id)
+	}
+	s.jobs[i].State = JobPending
+	s.jobs[i].Attempts = 0
+	s.jobs[i].LastError = ""
+	s.jobs[i].NextAttempt = time.Time{}
+	s.jobs[i].UpdatedAt = s.now()
+	job := s.jobs[i]
+	s.saveLocked()
+	s.mu.Unlock()
+
+	s.notify(job)
+	s.Wake()
+	return nil
+}
+
+// Discard removes a job from the queue.
+func (s *Spooler) Discard(id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.indexLocked(id); i >= 0 {
+		s.jobs = append(s.jobs[:i], s.jobs[i+1:]...)
+		s.saveLocked()
+	}
+}
+
+func (s *Spooler) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(spoolPollEvery)
+	defer ticker.Stop()
+	for {
+		s.ProcessPending()
+		select {
+		case <-s.stop:
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessPending makes one pass over the due jobs in FIFO order. Once a
+// job for a printer fails, later jobs for that printer wait for the next
+// pass so tickets never print out of order.
+func (s *Spooler) ProcessPending() {
+	blocked := map[string]bool{}
+	for {
+		job, ok := s.nextDue(blocked)
+		if !ok {
+			return
+		}
+
+		err := s.print(job)
+
+		s.mu.Lock()
+		i := s.indexLocked(job.ID)
+		if i < 0 {
+			// Discarded while printing.
+			s.mu.Unlock()
+			continue
+		}
+		s.finishLocked(&s.jobs[i], err)
+		updated := s.jobs[i]
+		s.pruneLocked()
+		s.saveLocked()
+		s.mu.Unlock()
+
+		if err != nil {
+			blocked[job.Printer] = true
+			log.Printf("Impressao de %q falhou (tentativa %d): %v", job.Label, updated.Attempts, err)
+		}
+		s.notify(updated)
+	}
+}
+
+func (s *Spooler) nextDue(blocked map[string]bool) (Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for _, j := range s.jobs {
+		if j.State != JobPending || blocked[j.Printer] {
+			continue
+		}
+		if j.NextAttempt.After(now) {
+			blocked[j.Printer] = true
+			continue
+		}
+		return j, true
+	}
+	return Job{}, false
+}
+
+func (s *Spooler) print(job Job) error {
+	p := s.resolve(job.Printer)
+	if p == nil || !p.IsConnected() {
+		return fmt.Errorf("impressora %s nao conectada", job.Printer)
+	}
+	// Writing to a printer without paper is accepted and silently lost, so
+	// hold the job while the printer reports it cannot print.
+	if status, err := p.Status(); err == nil && !status.Ready() {
+		return fmt.Errorf("impressora %s: %s", job.Printer, status.Problem())
+	}
+	return p.Write(job.Data)
+}
+
+func (s *Spooler) finishLocked(j *Job, err error) {
+	now := s.now()
+	j.UpdatedAt = now
+	j.Attempts++
+	if err == nil {
+		j.State = JobPrinted
+		j.LastError = ""
+		j.NextAttempt = time.Time{}
+		return
+	}
+
+	j.LastError = err.Error()
+	if j.Attempts >= spoolMaxAttempts {
+		j.State = JobFailed
+		j.NextAttempt = time.Time{}
+		return
+	}
+	j.NextAttempt = now.Add(backoff(j.Attempts))
+}
+
+// backoff returns the wait before retry number attempt (1-based).
+func backoff(attempt int) time.Duration {
+	d := spoolBaseDelay
+	for i := 1; i < attempt && d < spoolMaxDelay; i++ {
+		d *= 2
+	}
+	if d > spoolMaxDelay {
+		d = spoolMaxDelay
+	}
+	return d
+}
+
+// pruneLocked drops printed jobs older than spoolKeepPrinted.
+func (s *Spooler) pruneLocked() {
+	cutoff := s.now().Add(-spoolKeepPrinted)
+	kept := s.jobs[:0]
+	for _, j := range s.jobs {
+		if j.State == JobPrinted && j.UpdatedAt.Before(cutoff) {
+			continue
+		}
+		kept = append(kept, j)
+	}
+	s.jobs = kept
+}
+
+func (s *Spooler) indexLocked(id int) int {
+	for i, j := range s.jobs {
+		if j.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Spooler) saveLocked() {
+	data, err := json.MarshalIndent(s.jobs, "", "  ")
+	if err != nil {
+		log.Printf("Erro ao serializar fila de impressao: %v", err)
+		return
+	}
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		log.Printf("Erro ao salvar fila de impressao: %v", err)
+		return
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		log.Printf("Erro ao salvar fila de impressao: %v", err)
+	}
+}
+
+func (s *Spooler) notify(j Job) {
+	if s.OnChange != nil {
+		s.OnChange(j)
+	}
+}
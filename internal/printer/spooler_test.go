@@ -0,0 +1,140 @@
+package printer
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeDevice records writes and fails while err is set.
+type fakeDevice struct {
+	writes [][]byte
+	err    error
+}
+
+func (d *fakeDevice) Write(p []byte) (int, error) {
+	if d.err != nil {
+		return 0, d.err
+	}
+	d.writes = append(d.writes, append([]byte(nil), p...))
+	return len(p), nil
+}
+
+func (d *fakeDevice) Close() error { return nil }
+
+func newTestSpooler(t *testing.T, dev *fakeDevice) (*Spooler, *time.Time) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "queue.json")
+	p := &Printer{device: dev, path: "fake"}
+	s, err := NewSpooler(path, func(string) *Printer { return p })
+	if err != nil {
+		t.Fatalf("NewSpooler: %v", err)
+	}
+	clock := time.Date(2026, 2, 5, 20, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return clock }
+	return s, &clock
+}
+
+func TestSpoolerPrintsPendingJobs(t *testing.T) {
+	dev := &fakeDevice{}
+	s, _ := newTestSpooler(t, dev)
+
+	s.Enqueue("caixa", "Recibo #1", []byte("a"))
+	s.Enqueue("caixa", "Recibo #2", []byte("b"))
+	s.ProcessPending()
+
+	if len(dev.writes) != 2 || string(dev.writes[0]) != "a" || string(dev.writes[1]) != "b" {
+		t.Fatalf("writes = %q, want [a b]", dev.writes)
+	}
+	for _, j := range s.Jobs() {
+		if j.State != JobPrinted {
+			t.Errorf("job %d state = %q, want %q", j.ID, j.State, JobPrinted)
+		}
+	}
+	if s.Pending() != 0 {
+		t.Errorf("Pending = %d, want 0", s.Pending())
+	}
+}
+
+func TestSpoolerRetriesWithBackoffAndKeepsOrder(t *testing.T) {
+	dev := &fakeDevice{err: errors.New("sem papel")}
+	s, clock := newTestSpooler(t, dev)
+
+	s.Enqueue("caixa", "Recibo #1", []byte("a"))
+	s.Enqueue("caixa", "Recibo #2", []byte("b"))
+	s.ProcessPending()
+
+	jobs := s.Jobs()
+	if jobs[1].Attempts != 1 || jobs[1].LastError == "" {
+		t.Fatalf("first job = %+v, want one failed attempt", jobs[1])
+	}
+	if jobs[0].Attempts != 0 {
+		t.Errorf("second job attempted before the first printed")
+	}
+
+	// Printer is back but the backoff has not elapsed yet.
+	dev.err = nil
+	s.ProcessPending()
+	if len(dev.writes) != 0 {
+		t.Fatalf("printed before backoff elapsed")
+	}
+
+	*clock = clock.Add(spoolBaseDelay)
+	s.ProcessPending()
+	if len(dev.writes) != 2 || string(dev.writes[0]) != "a" {
+		t.Fatalf("writes = %q, want [a b]", dev.writes)
+	}
+}
+
+func TestSpoolerMarksFailedAndRetry(t *testing.T) {
+	dev := &fakeDevice{err: errors.New("desconectada")}
+	s, clock := newTestSpooler(t, dev)
+
+	job := s.Enqueue("cozinha", "Comanda #3", []byte("x"))
+	for i := 0; i < spoolMaxAttempts; i++ {
+		s.ProcessPending()
+		*clock = clock.Add(spoolMaxDelay)
+	}
+
+	if got := s.Jobs()[0]; got.State != JobFailed {
+		t.Fatalf("state = %q after %d attempts, want %q", got.State, spoolMaxAttempts, JobFailed)
+	}
+
+	dev.err = nil
+	if err := s.Retry(job.ID); err != nil {
+		t.Fatalf("Retry: %v", err)
+	}
+	s.ProcessPending()
+	if got := s.Jobs()[0]; got.State != JobPrinted {
+		t.Errorf("state = %q after retry, want %q", got.State, JobPrinted)
+	}
+}
+
+func TestSpoolerSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	s, err := NewSpooler(path, func(string) *Printer { return nil })
+	if err != nil {
+		t.Fatalf("NewSpooler: %v", err)
+	}
+	s.Enqueue("caixa", "Recibo #9", []byte("nove"))
+	s.ProcessPending()
+
+	dev := &fakeDevice{}
+	p := &Printer{device: dev}
+	reopened, err := NewSpooler(path, func(string) *Printer { return p })
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if reopened.Pending() != 1 {
+		t.Fatalf("Pending after restart = %d, want 1", reopened.Pending())
+	}
+	reopened.now = func() time.Time { return time.Now().Add(time.Hour) }
+	reopened.ProcessPending()
+	if len(dev.writes) != 1 || string(dev.writes[0]) != "nove" {
+		t.Errorf("writes = %q, want [nove]", dev.writes)
+	}
+	if next := reopened.Enqueue("caixa", "Recibo #10", nil); next.ID != 2 {
+		t.Errorf("next job ID = %d, want 2", next.ID)
+	}
+}
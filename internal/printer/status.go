@@ -0,0 +1,195 @@
+package printer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Real-time status requests (DLE EOT n). The printer answers each with a
+// single status byte, even while it is offline or out of paper.
+const (
+	statusPrinter byte = 1 // drawer pin, online/offline
+	statusOffline byte = 2 // cover, feed button, paper-end stop, error
+	statusPaper   byte = 4 // roll paper near-end and end sensors
+)
+
+func CmdRealtimeStatus(n byte) []byte {
+	return []byte{0x10, 0x04, n}
+}
+
+// CmdPaperSensorStatus (GS r 1) asks for the paper roll sensors. Unlike
+// DLE EOT it is answered in turn after the data already sent, and not at
+// all while the printer is offline.
+var CmdPaperSensorStatus = []byte{0x1D, 0x72, 0x01}
+
+// statusTimeout bounds how long a status request waits for the answer byte.
+var statusTimeout = 500 * time.Millisecond
+
+// ErrStatusUnsupported is returned by Printer.Status when the transport
+// cannot read data back from the printer (e.g. a write-only USB handle).
+var ErrStatusUnsupported = errors.New("impressora nao informa status")
+
+// PrinterStatus is the decoded real-time state of the printer.
+type PrinterStatus struct {
+	Online       bool
+	PaperNearEnd bool
+	PaperOut     bool
+	CoverOpen    bool
+	DrawerOpen   bool
+}
+
+// Ready reports whether a job sent now would actually print.
+func (s PrinterStatus) Ready() bool {
+	return s.Online && !s.PaperOut && !s.CoverOpen
+}
+
+// Problem describes why the printer is not ready, or "" when it is.
+func (s PrinterStatus) Problem() string {
+	switch {
+	case s.CoverOpen:
+		return "tampa aberta"
+	case s.PaperOut:
+		return "sem papel"
+	case !s.Online:
+		return "offline"
+	}
+	return ""
+}
+
+// String summarizes the status for the status bar, e.g.
+// "Sem papel, Gaveta aberta".
+func (s PrinterStatus) String() string {
+	var parts []string
+	if p := s.Problem(); p != "" {
+		parts = append(parts, p)
+	} else {
+		parts = append(parts, "pronta")
+	}
+	if s.PaperNearEnd && !s.PaperOut {
+		parts = append(parts, "pouco papel")
+	}
+	if s.DrawerOpen {
+		parts = append(parts, "gaveta aberta")
+	}
+	out := strings.Join(parts, ", ")
+	return strings.ToUpper(out[:1]) + out[1:]
+}
+
+// statusQuerier is implemented by transports that can report printer state.
+type statusQuerier interface {
+	queryStatus() (PrinterStatus, error)
+}
+
+// deadlineReadWriter is a bidirectional byte stream with read timeouts,
+// such as a TCP connection or a pollable USB device file.
+type deadlineReadWriter interface {
+	io.ReadWriter
+	SetReadDeadline(t time.Time) error
+}
+
+// drainInput discards what the printer sent before a request, such as an
+// automatic status or an answer that arrived after its timeout, so it is
+// not taken for the answer to the next one. It returns nil once nothing
+// more arrives, or the read error that ended the stream.
+func drainInput(rw deadlineReadWriter) error {
+	defer rw.SetReadDeadline(time.Time{})
+	var buf [64]byte
+	for {
+		if err := rw.SetReadDeadline(time.Now().Add(time.Millisecond)); err != nil {
+			return err
+		}
+		if _, err := rw.Read(buf[:]); err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// queryRealtimeStatus sends DLE EOT 1, 2 and 4 and decodes the answers.
+// An online printer is also asked for its paper sensors with GS r 1, which
+// some models report more reliably than DLE EOT 4; when it is busy
+// printing and answers late, the DLE EOT reading stands and the late byte
+// is drained before the next query.
+func queryRealtimeStatus(rw deadlineReadWriter) (PrinterStatus, error) {
+	if err := drainInput(rw); err != nil {
+		if errors.Is(err, os.ErrNoDeadline) {
+			return PrinterStatus{}, ErrStatusUnsupported
+		}
+		return PrinterStatus{}, fmt.Errorf("ler impressora: %w", err)
+	}
+	var answers [3]byte
+	for i, n := range []byte{statusPrinter, statusOffline, statusPaper} {
+		b, err := requestStatusByte(rw, CmdRealtimeStatus(n), fmt.Sprintf("status %d", n))
+		if err != nil {
+			return PrinterStatus{}, err
+		}
+		answers[i] = b
+	}
+	status, err := parseRealtimeStatus(answers[0], answers[1], answers[2])
+	if err != nil || !status.Online {
+		return status, err
+	}
+
+	b, err := requestStatusByte(rw, CmdPaperSensorStatus, "sensores de papel")
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return status, nil
+	}
+	if err != nil {
+		return PrinterStatus{}, err
+	}
+	return status.withPaperSensors(b)
+}
+
+func requestStatusByte(rw deadlineReadWriter, cmd []byte, what string) (byte, error) {
+	if _, err := rw.Write(cmd); err != nil {
+		return 0, fmt.Errorf("pedir %s: %w", what, err)
+	}
+	if err := rw.SetReadDeadline(time.Now().Add(statusTimeout)); err != nil {
+		return 0, ErrStatusUnsupported
+	}
+	defer rw.SetReadDeadline(time.Time{})
+
+	var buf [1]byte
+	if _, err := io.ReadFull(rw, buf[:]); err != nil {
+		return 0, fmt.Errorf("ler %s: %w", what, err)
+	}
+	return buf[0], nil
+}
+
+// parseRealtimeStatus decodes the DLE EOT 1, 2 and 4 answer bytes. Every
+// answer has bit 1 and bit 4 set and bits 0 and 7 clear; anything else
+// means the byte came from somewhere else in the stream.
+func parseRealtimeStatus(printerByte, offlineByte, paperByte byte) (PrinterStatus, error) {
+	for _, b := range []byte{printerByte, offlineByte, paperByte} {
+		if b&0x93 != 0x12 {
+			return PrinterStatus{}, fmt.Errorf("resposta de status invalida: 0x%02X", b)
+		}
+	}
+	return PrinterStatus{
+		Online: printerByte&0x08 == 0,
+		// Drawer pin 3 HIGH. Whether HIGH means open depends on the drawer's
+		// switch; the GS-T80E's standard drawer reports HIGH when open.
+		DrawerOpen:   printerByte&0x04 != 0,
+		CoverOpen:    offlineByte&0x04 != 0,
+		PaperOut:     offlineByte&0x20 != 0 || paperByte&0x60 != 0,
+		PaperNearEnd: paperByte&0x0C != 0,
+	}, nil
+}
+
+// withPaperSensors adds the GS r 1 answer to s: bits 0-1 flag the roll
+// near its end and bits 2-3 the roll out. Bits 4 and 7 are always clear,
+// which tells it apart from a DLE EOT answer.
+func (s PrinterStatus) withPaperSensors(b byte) (PrinterStatus, error) {
+	if b&0x90 != 0 {
+		return PrinterStatus{}, fmt.Errorf("resposta de sensores de papel invalida: 0x%02X", b)
+	}
+	s.PaperNearEnd = s.PaperNearEnd || b&0x03 != 0
+	s.PaperOut = s.PaperOut || b&0x0C != 0
+	return s, nil
+}
@@ -0,0 +1,179 @@
+package printer
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestParseRealtimeStatus(t *testing.T) {
+	tests := []struct {
+		name                string
+		printer, off, paper byte
+		want                PrinterStatus
+	}{
+		{"ready", 0x12, 0x12, 0x12, PrinterStatus{Online: true}},
+		{"offline", 0x1A, 0x12, 0x12, PrinterStatus{}},
+		{"drawer open", 0x16, 0x12, 0x12, PrinterStatus{Online: true, DrawerOpen: true}},
+		{"cover open", 0x1A, 0x16, 0x12, PrinterStatus{CoverOpen: true}},
+		{"near end", 0x12, 0x12, 0x1E, PrinterStatus{Online: true, PaperNearEnd: true}},
+		{"paper out", 0x1A, 0x32, 0x7E, PrinterStatus{PaperOut: true, PaperNearEnd: true}},
+	}
+	for _, tc := range tests {
+		got, err := parseRealtimeStatus(tc.printer, tc.off, tc.paper)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+
+	if _, err := parseRealtimeStatus(0x41, 0x12, 0x12); err == nil {
+		t.Error("a byte without the fixed status bits should be rejected")
+	}
+}
+
+func TestPrinterStatusString(t *testing.T) {
+	s := PrinterStatus{PaperOut: true, PaperNearEnd: true, DrawerOpen: true}
+	if got := s.String(); got != "Sem papel, gaveta aberta" {
+		t.Errorf("String = %q", got)
+	}
+	s = PrinterStatus{Online: true, PaperNearEnd: true}
+	if got := s.String(); got != "Pronta, pouco papel" {
+		t.Errorf("String = %q", got)
+	}
+	if !s.Ready() {
+		t.Error("near-end printer should still be ready")
+	}
+}
+
+// fakeStatusPrinter serves one connection like a network printer: it
+// sends unprompted first, then answers each DLE EOT n with answers[n] and
+// GS r 1 with sensors.
+func fakeStatusPrinter(t *testing.T, answers map[byte]byte, sensors byte, unprompted []byte) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Write(unprompted)
+		var pending []byte
+		buf := make([]byte, 256)
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				return
+			}
+			pending = append(pending, buf[:n]...)
+			for len(pending) >= 3 {
+				switch {
+				case bytes.HasPrefix(pending, []byte{0x10, 0x04}):
+					conn.Write([]byte{answers[pending[2]]})
+					pending = pending[3:]
+				case bytes.HasPrefix(pending, CmdPaperSensorStatus):
+					conn.Write([]byte{sensors})
+					pending = pending[3:]
+				default:
+					pending = pending[1:]
+				}
+			}
+		}
+	}()
+	return NetworkScheme + ln.Addr().String()
+}
+
+func TestNetworkPrinterStatus(t *testing.T) {
+	// Printer with the paper roll at its end.
+	path := fakeStatusPrinter(t, map[byte]byte{statusPrinter: 0x1A, statusOffline: 0x32, statusPaper: 0x7E}, 0, nil)
+	p, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer p.Close()
+
+	status, err := p.Status()
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if !status.PaperOut || status.Ready() {
+		t.Errorf("status = %+v, want paper out", status)
+	}
+}
+
+func TestNetworkStatusSkipsUnpromptedData(t *testing.T) {
+	// An automatic status (ASB) sent on its own must not be taken for the
+	// answers to DLE EOT.
+	asb := []byte{0x14, 0x00, 0x00, 0x0F}
+	path := fakeStatusPrinter(t, map[byte]byte{statusPrinter: 0x12, statusOffline: 0x12, statusPaper: 0x12}, 0, asb)
+	p, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer p.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	status, err := p.Status()
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if !status.Ready() {
+		t.Errorf("status = %+v, want ready", status)
+	}
+}
+
+func TestNetworkPaperSensorStatus(t *testing.T) {
+	// DLE EOT 4 reports paper, the GS r 1 sensors the roll near its end.
+	path := fakeStatusPrinter(t, map[byte]byte{statusPrinter: 0x12, statusOffline: 0x12, statusPaper: 0x12}, 0x03, nil)
+	p, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer p.Close()
+
+	status, err := p.Status()
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if !status.PaperNearEnd || !status.Ready() {
+		t.Errorf("status = %+v, want ready with paper near end", status)
+	}
+}
+
+func TestPaperSensorStatus(t *testing.T) {
+	online := PrinterStatus{Online: true}
+	tests := []struct {
+		b    byte
+		want PrinterStatus
+	}{
+		{0x00, online},
+		{0x03, PrinterStatus{Online: true, PaperNearEnd: true}},
+		{0x0F, PrinterStatus{Online: true, PaperNearEnd: true, PaperOut: true}},
+	}
+	for _, tc := range tests {
+		got, err := online.withPaperSensors(tc.b)
+		if err != nil || got != tc.want {
+			t.Errorf("withPaperSensors(0x%02X) = %+v, %v; want %+v", tc.b, got, err, tc.want)
+		}
+	}
+	if _, err := online.withPaperSensors(0x12); err == nil {
+		t.Error("a DLE EOT answer should not pass for the paper sensors")
+	}
+}
+
+func TestStatusUnsupportedTransport(t *testing.T) {
+	p := &Printer{device: &fakeDevice{}}
+	if _, err := p.Status(); !errors.Is(err, ErrStatusUnsupported) {
+		t.Errorf("Status err = %v, want ErrStatusUnsupported", err)
+	}
+}
@@ -0,0 +1,82 @@
+package printer
+
+import (
+	"fmt"
+
+	"notinha/internal/pos"
+)
+
+// BuildSubBillReceipt prints the partial receipt of one sub-bill of a
+// split order: its share of the items (or of the total, when split
+// evenly), its part of the discount and its own payments.
+func BuildSubBillReceipt(data ReceiptData, bill pos.SubBill) []byte {
+	w := data.CharsPerLine
+	if w <= 0 {
+		w = 48
+	}
+	o := data.Order
+
+	rb := NewReceiptBuilder().PaperWidth(w * DotsPerChar)
+	writeReceiptHeader(rb, data.Restaurant, w)
+	rb.Separator('-', w)
+
+	writeOrderInfo(rb, o)
+	rb.AlignCenter().
+		Bold().Line(fmt.Sprintf("CONTA %d/%d", bill.Number, len(o.SubBills))).NoBold().
+		AlignLeft()
+	rb.Separator('-', w)
+
+	if len(bill.Items) > 0 {
+		items := itemTable(w)
+		rb.Bold().Row(items, "QTD", "ITEM", "VALOR").NoBold()
+		for _, si := range bill.Items {
+			name := si.Item.Name
+			if f := si.Fraction(); f != "" {
+				name = fmt.Sprintf("%s (%s)", name, f)
+			}
+			rb.Row(items, fmt.Sprintf("%dx", si.Quantity), name, pos.FormatBRL(si.Amount))
+			writeModifiers(rb, si.Modifiers, w, false)
+			writeComponents(rb, si.Components, w, false)
+		}
+	} else {
+		rb.Line(fmt.Sprintf("Divisao igual: 1/%d de %s", len(o.SubBills), pos.FormatBRL(o.Subtotal())))
+	}
+	rb.Separator('-', w)
+
+	rb.Line(formatTotalLine("Subtotal:", pos.FormatBRL(bill.Subtotal), w))
+	if bill.Discount > 0 {
+		rb.Line(formatTotalLine("Desconto (rateio):", "-"+pos.FormatBRL(bill.Discount), w))
+	}
+	if bill.Service > 0 {
+		rb.Line(formatTotalLine(fmt.Sprintf("Servico (%d%%):", o.ServicePercent), pos.FormatBRL(bill.Service), w))
+	}
+	rb.Bold().
+		Line(formatTotalLine("TOTAL:", pos.FormatBRL(bill.Amount), w)).
+		NoBold()
+	rb.Separator('-', w)
+
+	if len(bill.Payments) > 1 {
+		rb.Bold().Line("Pagamentos:").NoBold()
+		for _, p := range bill.Payments {
+			rb.Line(fmt.Sprintf("  %s: %s", p.Method, pos.FormatBRL(p.Amount)))
+		}
+	} else if len(bill.Payments) == 1 {
+		rb.Line(fmt.Sprintf("Pagamento: %s", bill.Payments[0].Method))
+	}
+	if bill.CashReceived > 0 {
+		rb.Line(formatTotalLine("Valor Recebido:", pos.FormatBRL(bill.CashReceived), w))
+		rb.Line(formatTotalLine("Troco:", pos.FormatBRL(bill.CashChange()), w))
+	}
+
+	writePixQRCode(rb, data, bill.Payments, w)
+
+	if data.Restaurant.Footer != "" {
+		rb.Separator('-', w).
+			AlignCenter().
+			Row(textTable(w), data.Restaurant.Footer)
+	}
+
+	rb.Feed(4).PartialCut()
+
+	return rb.Build()
+}
@@ -23,23 +23,19 @@ func BuildSummaryReceipt(data SummaryReceiptData) []byte {
 	rb := NewReceiptBuilder()
 
 	// Header
-	rb.AlignCenter().
-		FontDouble().Bold().
-		Line(data.Restaurant.Name).
-		FontNormal().NoBold()
+	rb.AlignCenter()
+	writeTitle(rb, data.Restaurant.Name, w)
 
 	if data.Restaurant.Address != "" {
-		rb.Line(data.Restaurant.Address)
+		rb.Row(textTable(w), data.Restaurant.Address)
 	}
 	if data.Restaurant.Phone != "" {
-		rb.Line("Tel: " + data.Restaurant.Phone)
+		rb.Row(textTable(w), "Tel: "+data.Restaurant.Phone)
 	}
 
 	rb.Separator('-', w)
 
-	rb.FontDouble().Bold().
-		Line("RESUMO DO DIA").
-		FontNormal().NoBold()
+	writeTitle(rb, "RESUMO DO DIA", w)
 
 	rb.Line(pos.FormatDateBR(s.Date))
 	rb.Separator('-', w)
@@ -56,6 +52,12 @@ func BuildSummaryReceipt(data SummaryReceiptData) []byte {
 	rb.Bold().
 		Line(formatTotalLine("RECEITA TOTAL:", pos.FormatBRL(s.TotalRevenue), w)).
 		NoBold()
+	if s.Refunds > 0 {
+		writeTotalRow(rb, fmt.Sprintf("Estornos (%d):", s.Refunds), "-"+pos.FormatBRL(s.RefundTotal), w)
+	}
+	if s.ServiceTotal > 0 {
+		rb.Line(formatTotalLine("Taxa de servico:", pos.FormatBRL(s.ServiceTotal), w))
+	}
 
 	rb.Separator('-', w)
 
@@ -66,12 +68,11 @@ func BuildSummaryReceipt(data SummaryReceiptData) []byte {
 
 	for _, pm := range []pos.PaymentMethod{pos.PaymentDinheiro, pos.PaymentCartao, pos.PaymentPix} {
 		count := s.OrdersByPayment[pm]
-		if count == 0 {
+		if count == 0 && s.ByPayment[pm] == 0 {
 			continue
 		}
 		revenue := s.ByPayment[pm]
-		label := fmt.Sprintf("%s (%d):", pm, count)
-		rb.Line(formatTotalLine(label, pos.FormatBRL(revenue), w))
+		writeTotalRow(rb, fmt.Sprintf("%s (%d):", pm, count), pos.FormatBRL(revenue), w)
 	}
 
 	rb.Separator('-', w)
@@ -79,14 +80,57 @@ func BuildSummaryReceipt(data SummaryReceiptData) []byte {
 	// Average ticket
 	rb.Line(formatTotalLine("Ticket medio:", pos.FormatBRL(s.AverageTicket), w))
 
+	writeOperatorSales(rb, s.ByOperator, w)
+
+	// Items sold, with combos counted by component
+	if len(s.Items) > 0 {
+		rb.Separator('-', w)
+		rb.AlignCenter().
+			Bold().Line("ITENS VENDIDOS").NoBold()
+		rb.AlignLeft()
+		items := itemTable(w)
+		for _, is := range s.Items {
+			rb.Row(items, fmt.Sprintf("%dx", is.Quantity), is.Name, pos.FormatBRL(is.Revenue))
+		}
+	}
+
 	// Footer
 	if data.Restaurant.Footer != "" {
 		rb.Separator('-', w).
 			AlignCenter().
-			Line(data.Restaurant.Footer)
+			Row(textTable(w), data.Restaurant.Footer)
 	}
 
 	rb.Feed(4).PartialCut()
 
 	return rb.Build()
 }
+
+// writeOperatorSales prints the per-operator breakdown of a summary, when
+// the orders carry operators.
+func writeOperatorSales(rb *ReceiptBuilder, sales []pos.OperatorSales, w int) {
+	if len(sales) == 0 {
+		return
+	}
+	rb.Separator('-', w)
+	rb.AlignCenter().
+		Bold().Line("POR OPERADOR").NoBold()
+	rb.AlignLeft()
+	for _, op := range sales {
+		rb.Bold()
+		writeTotalRow(rb, fmt.Sprintf("%s (%d):", op.Name, op.Orders), pos.FormatBRL(op.Revenue), w)
+		rb.NoBold()
+		if op.Service > 0 {
+			rb.Line(formatTotalLine("  Taxa de servico:", pos.FormatBRL(op.Service), w))
+		}
+		if op.Discounts > 0 {
+			rb.Line(formatTotalLine("  Descontos:", "-"+pos.FormatBRL(op.Discounts), w))
+		}
+		if op.Cancelled > 0 {
+			rb.Line(formatTotalLine("  Cancelamentos:", fmt.Sprintf("%d", op.Cancelled), w))
+		}
+		if op.Refunds > 0 {
+			writeTotalRow(rb, fmt.Sprintf("  Estornos (%d):", op.Refunds), "-"+pos.FormatBRL(op.RefundTotal), w)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"notinha/internal/pos"
+)
+
+var cashMu sync.Mutex
+
+func cashSessionsPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cash_sessions.json"), nil
+}
+
+// LoadCashSessions returns every cash session, oldest first.
+func LoadCashSessions() ([]pos.CashSession, error) {
+	cashMu.Lock()
+	defer cashMu.Unlock()
+	return loadCashSessions()
+}
+
+func loadCashSessions() ([]pos.CashSession, error) {
+	path, err := cashSessionsPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var sessions []pos.CashSession
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		return nil, fmt.Errorf("sessoes de caixa corrompidas: %w", err)
+	}
+	return sessions, nil
+}
+
+// SaveCashSession inserts or replaces the session with the same ID.
+func SaveCashSession(session *pos.CashSession) error {
+	cashMu.Lock()
+	defer cashMu.Unlock()
+
+	sessions, err := loadCashSessions()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range sessions {
+		if sessions[i].ID == session.ID {
+			sessions[i] = *session
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		sessions = append(sessions, *session)
+	}
+
+	path, err := cashSessionsPath()
+	if err != nil {
+		return err
+	}
+	return atomicWriteJSON(path, sessions)
+}
+
+// OpenCashSession starts a new session with the next sequential ID, opened
+// by the operator with operatorID (0 without accounts). It fails if
+// another session is still open.
+func OpenCashSession(openingFloat int64, operatorID int, operator string) (*pos.CashSession, error) {
+	current, err := CurrentCashSession()
+	if err != nil {
+		return nil, err
+	}
+	if current != nil {
+		return nil, fmt.Errorf("caixa %d ja esta aberto", current.ID)
+	}
+
+	sessions, err := LoadCashSessions()
+	if err != nil {
+		return nil, err
+	}
+	id := 1
+	for _, s := range sessions {
+		if s.ID >= id {
+			id = s.ID + 1
+		}
+	}
+	session := pos.NewCashSession(id, openingFloat, operator)
+	session.OpenedByID = operatorID
+	return session, SaveCashSession(session)
+}
+
+// CurrentCashSession returns the open session, or nil when the register
+// is closed.
+func CurrentCashSession() (*pos.CashSession, error) {
+	sessions, err := LoadCashSessions()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(sessions) - 1; i >= 0; i-- {
+		if sessions[i].IsOpen() {
+			return &sessions[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// LoadSessionOrders returns the orders paid during the session, reading
+// every day file between its opening and closing (or today, while open).
+func LoadSessionOrders(session *pos.CashSession) ([]pos.Order, error) {
+	end := session.ClosedAt
+	if end.IsZero() {
+		end = time.Now()
+	}
+	start := session.OpenedAt
+	first := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+
+	var orders []pos.Order
+	for day := first; !day.After(end); day = day.AddDate(0, 0, 1) {
+		dayOrders, err := LoadDayOrders(day.Format("2006-01-02"))
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range dayOrders {
+			if o.SessionID == session.ID {
+				orders = append(orders, o)
+			}
+		}
+	}
+	return orders, nil
+}
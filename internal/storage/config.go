@@ -4,6 +4,7 @@ import (
 	_ "embed"
 	"encoding/json"
 	"os"
+	"path"
 	"path/filepath"
 	"sync"
 
@@ -19,18 +20,58 @@ type RestaurantInfo struct {
 	Phone   string `json:"phone"`
 	CNPJ    string `json:"cnpj"`
 	Footer  string `json:"footer"`
+	PixKey  string `json:"pix_key"`  // printed as a QR code on PIX receipts when set
+	PixName string `json:"pix_name"` // account holder name; defaults to Name
+	PixCity string `json:"pix_city"`
+	Logo    string `json:"logo"` // image file printed at the top of receipts
 }
 
 type PrinterConfig struct {
-	DevicePath   string `json:"device_path"`
+	DevicePath   string `json:"device_path"` // device file, spooler name or tcp://host:port
 	CharsPerLine int    `json:"chars_per_line"`
+	ScanSubnet   string `json:"scan_subnet"` // CIDR probed for LAN printers, e.g. 192.168.0.0/24
+}
+
+// Well-known printer names. The cashier printer is Config.Printer; every
+// other name refers to an entry in Config.Printers.
+const (
+	CashierPrinter = "caixa"
+	KitchenPrinter = "cozinha"
+	BarPrinter     = "bar"
+)
+
+// PizzaConfig sets up pizzas of several flavors ("meio a meio").
+// Categories are path.Match patterns, like routes.
+type PizzaConfig struct {
+	Categories []string         `json:"categories"`
+	Pricing    pos.PizzaPricing `json:"pricing"`
+}
+
+// PrinterRoute sends order items whose menu category matches Category to
+// the named printer. Category is a path.Match pattern, so "Pizzas *" or
+// "Cervejas - *" cover a whole family of categories.
+type PrinterRoute struct {
+	Category string `json:"category"`
+	Printer  string `json:"printer"`
 }
 
 type Config struct {
-	Restaurant    RestaurantInfo `json:"restaurant"`
-	Printer       PrinterConfig  `json:"printer"`
-	OrderCounter  int            `json:"order_counter"`
-	KitchenTicket bool           `json:"kitchen_ticket"`
+	Restaurant    RestaurantInfo           `json:"restaurant"`
+	Printer       PrinterConfig            `json:"printer"`
+	Printers      map[string]PrinterConfig `json:"printers"` // station printers by name (cozinha, bar, ...)
+	Routes        []PrinterRoute           `json:"routes"`
+	Tables        []pos.Table              `json:"tables"` // floor layout shown in the table map
+	OrderCounter  int                      `json:"order_counter"`
+	ZCounter      int                      `json:"z_counter"` // last reducao Z number issued
+	KitchenTicket bool                     `json:"kitchen_ticket"`
+	ServiceCharge int                      `json:"service_charge"` // taxa de servico (%) on new orders; 0 = none
+	Pizza         PizzaConfig              `json:"pizza"`
+	ManagerPIN    string                   `json:"manager_pin,omitempty"` // pos.HashPIN of the PIN that authorizes refunds without operator accounts; empty = not asked
+	KDSAddress    string                   `json:"kds_address"`           // listen address of the kitchen display, e.g. ":8090"; empty = off
+
+	// BusinessDayCutoff is the hour (0-23) at which a new business day
+	// starts; orders closed earlier belong to the previous day.
+	BusinessDayCutoff int `json:"business_day_cutoff"`
 
 	mu sync.Mutex
 }
@@ -45,8 +86,63 @@ func DefaultConfig() *Config {
 			DevicePath:   defaultPrinterPath,
 			CharsPerLine: 48,
 		},
-		OrderCounter: 0,
+		Tables:        defaultTables(),
+		ServiceCharge: 10,
+		Pizza: PizzaConfig{
+			Categories: []string{"Pizzas *"},
+			Pricing:    pos.PricingHighest,
+		},
+		OrderCounter:      0,
+		BusinessDayCutoff: 4,
+	}
+}
+
+func defaultTables() []pos.Table {
+	tables := make([]pos.Table, 10)
+	for i := range tables {
+		tables[i] = pos.Table{Number: i + 1, Area: "Salao"}
 	}
+	return tables
+}
+
+// RouteFor returns the printer name that should receive items of the given
+// menu category. The first matching route wins; categories with no route
+// go to the kitchen.
+func (c *Config) RouteFor(category string) string {
+	for _, r := range c.Routes {
+		if ok, _ := path.Match(r.Category, category); ok {
+			return r.Printer
+		}
+	}
+	return KitchenPrinter
+}
+
+// IsPizzaCategory reports whether items of category can be combined into
+// a pizza of several flavors.
+func (c *Config) IsPizzaCategory(category string) bool {
+	for _, pattern := range c.Pizza.Categories {
+		if ok, _ := path.Match(pattern, category); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// StationPrinter returns the configuration of the named printer. The
+// cashier name maps to Config.Printer. A station without its own column
+// width inherits the cashier's.
+func (c *Config) StationPrinter(name string) (PrinterConfig, bool) {
+	if name == CashierPrinter {
+		return c.Printer, true
+	}
+	pc, ok := c.Printers[name]
+	if !ok || pc.DevicePath == "" {
+		return PrinterConfig{}, false
+	}
+	if pc.CharsPerLine <= 0 {
+		pc.CharsPerLine = c.Printer.CharsPerLine
+	}
+	return pc, true
 }
 
 func configDir() (string, error) {
@@ -74,6 +170,15 @@ func menuPath() (string, error) {
 	return filepath.Join(dir, "menu.json"), nil
 }
 
+// PrintQueuePath returns the file where the print spooler persists its jobs.
+func PrintQueuePath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "print_queue.json"), nil
+}
+
 func LoadConfig() (*Config, error) {
 	path, err := configPath()
 	if err != nil {
@@ -145,6 +250,15 @@ func (c *Config) NextOrderNumber() int {
 	return c.OrderCounter
 }
 
+// NextZNumber reserves the next sequential reducao Z number.
+func (c *Config) NextZNumber() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ZCounter++
+	_ = SaveConfig(c)
+	return c.ZCounter
+}
+
 func atomicWriteJSON(path string, v any) error {
 	data, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"notinha/internal/pos"
+)
+
+var openOrdersMu sync.Mutex
+
+func openOrdersPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "open_orders.json"), nil
+}
+
+// LoadOpenOrders returns the orders still open (tabs being run by table or
+// customer), oldest first.
+func LoadOpenOrders() ([]pos.Order, error) {
+	openOrdersMu.Lock()
+	defer openOrdersMu.Unlock()
+	return loadOpenOrders()
+}
+
+func loadOpenOrders() ([]pos.Order, error) {
+	path, err := openOrdersPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var orders []pos.Order
+	if err := json.Unmarshal(data, &orders); err != nil {
+		return nil, fmt.Errorf("pedidos abertos corrompidos: %w", err)
+	}
+	return orders, nil
+}
+
+func writeOpenOrders(orders []pos.Order) error {
+	path, err := openOrdersPath()
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(orders, func(i, j int) bool {
+		return orders[i].CreatedAt.Before(orders[j].CreatedAt)
+	})
+	return atomicWriteJSON(path, orders)
+}
+
+// SaveOpenOrder inserts or replaces the open order with the same number.
+func SaveOpenOrder(order *pos.Order) error {
+	openOrdersMu.Lock()
+	defer openOrdersMu.Unlock()
+
+	orders, err := loadOpenOrders()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range orders {
+		if orders[i].Number == order.Number {
+			orders[i] = *order
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		orders = append(orders, *order)
+	}
+	return writeOpenOrders(orders)
+}
+
+// DeleteOpenOrder drops an order from the open store, once it has been
+// finalized or discarded. Unknown numbers are ignored.
+func DeleteOpenOrder(number int) error {
+	openOrdersMu.Lock()
+	defer openOrdersMu.Unlock()
+
+	orders, err := loadOpenOrders()
+	if err != nil {
+		return err
+	}
+	kept := orders[:0]
+	for _, o := range orders {
+		if o.Number != number {
+			kept = append(kept, o)
+		}
+	}
+	if len(kept) == len(orders) {
+		return nil
+	}
+	return writeOpenOrders(kept)
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"notinha/internal/pos"
+)
+
+func operatorsPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "operators.json"), nil
+}
+
+// LoadOperators returns the operator accounts, or none when the file does
+// not exist yet and the app runs without login.
+func LoadOperators() ([]pos.Operator, error) {
+	path, err := operatorsPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var ops []pos.Operator
+	if err := json.Unmarshal(data, &ops); err != nil {
+		return nil, fmt.Errorf("operadores corrompidos: %w", err)
+	}
+	return ops, nil
+}
+
+// SaveOperators replaces the operator accounts.
+func SaveOperators(ops []pos.Operator) error {
+	path, err := operatorsPath()
+	if err != nil {
+		return err
+	}
+	return atomicWriteJSON(path, ops)
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
@@ -50,6 +51,30 @@ func SaveOrder(order *pos.Order) error {
 	return atomicWriteJSON(path, orders)
 }
 
+// UpdateOrder rewrites a saved order in place, as after a refund. The
+// order is found by number and creation time in the file of the day it
+// closed.
+func UpdateOrder(order *pos.Order) error {
+	ordersMu.Lock()
+	defer ordersMu.Unlock()
+
+	path, err := ordersFilePath(order.ClosedAt.Format("2006-01-02"))
+	if err != nil {
+		return err
+	}
+	orders, err := loadOrdersFromFile(path)
+	if err != nil {
+		return err
+	}
+	for i, o := range orders {
+		if o.Number == order.Number && o.CreatedAt.Equal(order.CreatedAt) {
+			orders[i] = *order
+			return atomicWriteJSON(path, orders)
+		}
+	}
+	return fmt.Errorf("pedido #%d nao encontrado em %s", order.Number, filepath.Base(path))
+}
+
 func LoadDayOrders(date string) ([]pos.Order, error) {
 	ordersMu.Lock()
 	defer ordersMu.Unlock()
@@ -105,3 +130,56 @@ func loadOrdersFromFile(path string) ([]pos.Order, error) {
 	}
 	return orders, nil
 }
+
+// LoadBusinessDayOrders returns the orders closed during the business day
+// starting at cutoffHour on date, which spans two order files when the
+// cutoff is after midnight.
+func LoadBusinessDayOrders(date string, cutoffHour int) ([]pos.Order, error) {
+	start, end, err := pos.BusinessDayBounds(date, cutoffHour, time.Local)
+	if err != nil {
+		return nil, err
+	}
+
+	first := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	var orders []pos.Order
+	for day := first; day.Before(end); day = day.AddDate(0, 0, 1) {
+		dayOrders, err := LoadDayOrders(day.Format("2006-01-02"))
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range dayOrders {
+			if !o.ClosedAt.Before(start) && o.ClosedAt.Before(end) {
+				orders = append(orders, o)
+			}
+		}
+	}
+	return orders, nil
+}
+
+// ListBusinessDates returns the business days that have orders, newest
+// first.
+func ListBusinessDates(cutoffHour int) ([]string, error) {
+	files, err := ListOrderDates()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	var dates []string
+	for _, file := range files {
+		orders, err := LoadDayOrders(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range orders {
+			date := pos.BusinessDate(o.ClosedAt, cutoffHour)
+			if !seen[date] {
+				seen[date] = true
+				dates = append(dates, date)
+			}
+		}
+	}
+
+	sort.Sort(sort.Reverse(sort.StringSlice(dates)))
+	return dates, nil
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 	"strconv"
 	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -12,6 +13,7 @@ import (
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/widget"
 
+	"notinha/internal/kds"
 	"notinha/internal/pos"
 	"notinha/internal/printer"
 	"notinha/internal/storage"
@@ -23,6 +25,18 @@ func (a *App) buildActionPanel() fyne.CanvasObject {
 	a.customerEntry.SetPlaceHolder("Nome do cliente")
 	a.tableEntry = widget.NewEntry()
 	a.tableEntry.SetPlaceHolder("Mesa")
+	a.customerEntry.OnChanged = func(text string) {
+		a.order.Customer = text
+		a.refreshOpenOrdersSelect()
+	}
+	a.tableEntry.OnChanged = func(text string) {
+		a.order.Table = text
+		a.refreshOpenOrdersSelect()
+	}
+
+	tableMapBtn := widget.NewButton("Mapa", func() {
+		a.showTableMapDialog()
+	})
 
 	// Payment section: cash entry, radio, split button
 	a.cashReceivedEntry = widget.NewEntry()
@@ -52,14 +66,31 @@ func (a *App) buildActionPanel() fyne.CanvasObject {
 	splitPaymentBtn := widget.NewButton("Dividir Pagamento", func() {
 		a.showSplitPaymentDialog()
 	})
+	splitBillBtn := widget.NewButton("Dividir Conta", func() {
+		a.showSplitBillDialog()
+	})
 
 	// Discount and action buttons
 	a.discountEntry = widget.NewEntry()
 	a.discountEntry.SetPlaceHolder("Desconto (R$)")
+
+	// Service charge: on by default with the configured rate, per order
+	// it can be turned off or changed.
+	a.serviceEntry = widget.NewEntry()
+	a.serviceEntry.SetPlaceHolder("%")
+	a.serviceEntry.OnChanged = func(string) {
+		a.updateServicePercent()
+	}
+	a.serviceCheck = widget.NewCheck("Taxa de servico (%)", func(bool) {
+		a.updateServicePercent()
+	})
 	finalizeBtn := widget.NewButton("Finalizar Pedido", func() {
 		a.finalizeOrder()
 	})
 	finalizeBtn.Importance = widget.HighImportance
+	previewBtn := widget.NewButton("Visualizar Cupom", func() {
+		a.previewReceipt()
+	})
 	newOrderBtn := widget.NewButton("Novo Pedido", func() {
 		a.newOrder()
 	})
@@ -68,6 +99,9 @@ func (a *App) buildActionPanel() fyne.CanvasObject {
 		_ = storage.SaveConfig(a.config)
 	})
 	a.kitchenCheck.SetChecked(a.config.KitchenTicket)
+	sendKitchenBtn := widget.NewButton("Enviar para cozinha", func() {
+		a.sendToKitchen()
+	})
 
 	// Printer controls
 	printTestBtn := widget.NewButton("Teste Impressao", func() {
@@ -84,17 +118,19 @@ func (a *App) buildActionPanel() fyne.CanvasObject {
 		widget.NewLabel("Cliente:"),
 		a.customerEntry,
 		widget.NewLabel("Mesa:"),
-		a.tableEntry,
+		container.NewBorder(nil, nil, nil, tableMapBtn, a.tableEntry),
 		widget.NewSeparator(),
 		widget.NewLabel("Pagamento:"),
 		a.paymentRadio,
-		splitPaymentBtn,
+		container.NewGridWithColumns(2, splitPaymentBtn, splitBillBtn),
 		a.cashSection,
 		widget.NewSeparator(),
 		widget.NewLabel("Desconto:"),
 		a.discountEntry,
+		container.NewBorder(nil, nil, nil, a.serviceEntry, a.serviceCheck),
 		widget.NewSeparator(),
-		a.kitchenCheck,
+		container.NewGridWithColumns(2, a.kitchenCheck, sendKitchenBtn),
+		previewBtn,
 		finalizeBtn,
 		newOrderBtn,
 		layout.NewSpacer(),
@@ -134,85 +170,266 @@ func (a *App) hasCashPayment() bool {
 	return a.order.Payment == pos.PaymentDinheiro
 }
 
+// finalizeOrder previews the receipt of the current order and closes it
+// once the preview is confirmed.
 func (a *App) finalizeOrder() {
 	if len(a.order.Items) == 0 {
 		dialog.ShowInformation("Aviso", "Adicione itens ao pedido.", a.mainWindow)
 		return
 	}
-
-	a.order.Customer = a.customerEntry.Text
-	a.order.Table = a.tableEntry.Text
-
-	if cents, ok := parseCurrencyInput(a.discountEntry.Text); ok {
-		a.order.Discount = cents
+	if !a.requireCharge() {
+		return
 	}
-
-	if cents, ok := parseCurrencyInput(a.cashReceivedEntry.Text); ok {
-		a.order.CashReceived = cents
+	if !a.requireCashSession() {
+		return
 	}
-
-	if len(a.splitPayments) > 0 {
-		a.order.FinalizeSplit(a.splitPayments)
-	} else {
-		a.order.Finalize(a.order.Payment)
+	if a.order.HasPaidSubBills() {
+		dialog.ShowInformation("Conta Dividida",
+			"Este pedido tem contas pagas. Pague as contas restantes em Dividir Conta.", a.mainWindow)
+		return
 	}
 
-	if a.order.CashReceived > 0 && a.hasCashPayment() {
-		change := a.order.CashChange()
+	a.showReceiptPreview(fmt.Sprintf("Finalizar Pedido #%d", a.order.Number), a.confirmFinalizeOrder)
+}
+
+// confirmFinalizeOrder closes the order, asking to confirm the change first
+// when it is paid in cash. The change is worked out on a finalized copy, so
+// the order, split included, is untouched until the operator confirms.
+func (a *App) confirmFinalizeOrder() {
+	draft := a.order.Clone()
+	a.applyOrderInputs(draft)
+
+	if draft.CashReceived > 0 && a.hasCashPayment() {
 		msg := fmt.Sprintf("Total: %s\nRecebido: %s\nTroco: %s",
-			pos.FormatBRL(a.order.Total()),
-			pos.FormatBRL(a.order.CashReceived),
-			pos.FormatBRL(change))
+			pos.FormatBRL(draft.Total()),
+			pos.FormatBRL(draft.CashReceived),
+			pos.FormatBRL(draft.CashChange()))
 		dialog.ShowConfirm("Confirmar Troco", msg, func(ok bool) {
 			if ok {
+				a.applyOrderInputs(a.order)
 				a.executeFinalizeOrder()
 			}
 		}, a.mainWindow)
 		return
 	}
 
+	a.applyOrderInputs(a.order)
 	a.executeFinalizeOrder()
 }
 
-func (a *App) executeFinalizeOrder() {
+// storeOrderInputs copies the customer, table, discount and cash entries
+// into o, leaving it open. A changed discount is credited to the current
+// operator. The discount and service charge of an order with paid
+// sub-bills are kept as they were split.
+func (a *App) storeOrderInputs(o *pos.Order) {
+	o.Customer = a.customerEntry.Text
+	o.Table = a.tableEntry.Text
+	o.CashReceived, _ = parseCurrencyInput(a.cashReceivedEntry.Text)
+	if o.HasPaidSubBills() {
+		return
+	}
+	if discount, _ := parseCurrencyInput(a.discountEntry.Text); discount != o.Discount {
+		o.Discount = discount
+		o.DiscountByID = a.operatorID()
+	}
+	o.ServicePercent = a.servicePercentInput()
+}
+
+// updateServicePercent applies the service charge inputs to the current
+// order as they change.
+func (a *App) updateServicePercent() {
+	if !a.order.HasPaidSubBills() {
+		a.order.ServicePercent = a.servicePercentInput()
+	}
+	a.totalLabel.SetText(pos.FormatBRL(a.order.Total()))
+}
+
+// servicePercentInput is the service charge chosen for the order, zero
+// when unchecked.
+func (a *App) servicePercentInput() int {
+	if !a.serviceCheck.Checked {
+		return 0
+	}
+	pct, err := strconv.Atoi(strings.TrimSpace(a.serviceEntry.Text))
+	if err != nil || pct < 0 || pct > 100 {
+		return 0
+	}
+	return pct
+}
+
+// loadOrderInputs fills the entries from o when switching to it.
+func (a *App) loadOrderInputs(o *pos.Order) {
+	a.customerEntry.SetText(o.Customer)
+	a.tableEntry.SetText(o.Table)
+	a.discountEntry.SetText(formatCurrencyInput(o.Discount))
+	a.cashReceivedEntry.SetText(formatCurrencyInput(o.CashReceived))
+	// Both widgets write back to the order on change, so read it first.
+	enabled, pct := o.ServicePercent > 0, o.ServicePercent
+	if !enabled {
+		pct = a.config.ServiceCharge
+	}
+	a.serviceEntry.SetText(strconv.Itoa(pct))
+	a.serviceCheck.SetChecked(enabled)
+	a.lockPaidOrderInputs()
+	a.changeLabel.SetText("")
+	a.paymentRadio.Enable()
+	payment := o.Payment
+	if payment == "" {
+		payment = pos.PaymentDinheiro
+	}
+	a.paymentRadio.SetSelected(string(payment))
+	if payment == pos.PaymentDinheiro {
+		a.cashSection.Show()
+	} else {
+		a.cashSection.Hide()
+	}
+}
+
+// applyOrderInputs copies the entries into o and finalizes it. Paying the
+// order as a whole drops a split nobody has paid yet.
+func (a *App) applyOrderInputs(o *pos.Order) {
+	a.storeOrderInputs(o)
+	_ = o.ClearSplit()
+
+	if len(a.splitPayments) > 0 {
+		o.FinalizeSplit(a.splitPayments)
+	} else {
+		o.Finalize(o.Payment)
+	}
+}
+
+// previewReceipt shows the receipt the current order would print, without
+// finalizing it.
+func (a *App) previewReceipt() {
+	if len(a.order.Items) == 0 {
+		dialog.ShowInformation("Aviso", "Adicione itens ao pedido.", a.mainWindow)
+		return
+	}
+	a.showReceiptPreview(fmt.Sprintf("Cupom #%d", a.order.Number), nil)
+}
+
+// showReceiptPreview renders the receipt of a finalized copy of the current
+// order; the order itself is left untouched. onPrint, when set, is offered
+// as the dialog's print button.
+func (a *App) showReceiptPreview(title string, onPrint func()) {
+	draft := a.order.Clone()
+	a.applyOrderInputs(draft)
+
+	data := printer.ReceiptData{
+		Restaurant:   a.config.Restaurant,
+		Order:        draft,
+		CharsPerLine: a.config.Printer.CharsPerLine,
+	}
+	a.showPreviewDialog(title, printer.BuildReceipt(data), data.CharsPerLine, onPrint)
+}
+
+// saveFinalizedOrder books the finalized current order in the open
+// register and saves it, already marked as sent to the kitchen so a reload
+// does not send it again. It returns the order as it was before, with what
+// the stations still have to get. When saving fails the current order is
+// put back that way, open, and nothing should be printed.
+func (a *App) saveFinalizedOrder() (*pos.Order, error) {
+	pending := a.order.Clone()
+	if a.session != nil {
+		a.order.SessionID = a.session.ID
+	}
+	a.order.ClosedByID = a.operatorID()
+	if a.config.KitchenTicket {
+		a.order.MarkSentToKitchen()
+	}
 	if err := storage.SaveOrder(a.order); err != nil {
 		log.Printf("Erro ao salvar pedido: %v", err)
+		*a.order = *pending
+		a.order.Status = pos.StatusAberto
+		a.order.ClosedAt = time.Time{}
+		return nil, fmt.Errorf("erro ao salvar pedido: %w", err)
+	}
+	return pending, nil
+}
+
+func (a *App) executeFinalizeOrder() {
+	pending, err := a.saveFinalizedOrder()
+	if err != nil {
+		dialog.ShowError(err, a.mainWindow)
+		return
+	}
+
+	data := printer.ReceiptData{
+		Restaurant:   a.config.Restaurant,
+		Order:        a.order,
+		CharsPerLine: a.config.Printer.CharsPerLine,
+	}
+	a.spooler.Enqueue(storage.CashierPrinter,
+		fmt.Sprintf("Recibo #%d", a.order.Number), printer.BuildReceipt(data))
+	if a.config.KitchenTicket {
+		kitchen := data
+		kitchen.Order = pending
+		a.enqueueStationTickets(kitchen)
 	}
 
 	if a.printer != nil && a.printer.IsConnected() {
-		printKitchen := a.config.KitchenTicket
-		go func() {
-			data := printer.ReceiptData{
-				Restaurant:   a.config.Restaurant,
-				Order:        a.order,
-				CharsPerLine: a.config.Printer.CharsPerLine,
-			}
-			receipt := printer.BuildReceipt(data)
-			if err := a.printer.Write(receipt); err != nil {
-				log.Printf("Erro ao imprimir: %v", err)
-				fyne.Do(func() {
-					dialog.ShowError(fmt.Errorf("erro ao imprimir: %w", err), a.mainWindow)
-				})
-				return
-			}
-			if printKitchen {
-				ticket := printer.BuildKitchenTicket(data)
-				if err := a.printer.Write(ticket); err != nil {
-					log.Printf("Erro ao imprimir comanda de cozinha: %v", err)
-				}
-			}
-			fyne.Do(func() {
-				dialog.ShowInformation("Sucesso", "Pedido impresso!", a.mainWindow)
-			})
-		}()
+		dialog.ShowInformation("Sucesso",
+			fmt.Sprintf("Pedido #%d enviado para impressao.", a.order.Number), a.mainWindow)
 	} else {
 		dialog.ShowInformation("Pedido Finalizado",
-			fmt.Sprintf("Pedido #%d finalizado.\nTotal: %s\n(Impressora nao conectada)",
+			fmt.Sprintf("Pedido #%d finalizado.\nTotal: %s\n(Impressora nao conectada, impressao na fila)",
 				a.order.Number, pos.FormatBRL(a.order.Total())),
 			a.mainWindow)
 	}
 
-	a.newOrder()
+	a.finishCurrentOrder()
+}
+
+// enqueueStationTickets queues one production ticket per station with
+// the items the stations have not received yet, and records them as sent.
+// Items added to an order already sent go out marked as an addition.
+func (a *App) enqueueStationTickets(data printer.ReceiptData) {
+	items := data.Order.PendingKitchenItems()
+	build, title, kind := printer.BuildStationTicket, "Comanda", kds.KindNew
+	if data.Order.SentToKitchen() {
+		build, title, kind = printer.BuildAdditionTicket, "Acrescimo", kds.KindAdd
+	}
+	a.enqueueProductionTickets(data, items, build, title)
+	a.sendToDisplay(data.Order, kind, items)
+	data.Order.MarkSentToKitchen()
+}
+
+// enqueueCancellationTickets tells the stations about sent items taken
+// off the order.
+func (a *App) enqueueCancellationTickets(o *pos.Order) {
+	items := o.TakeKitchenCancellations()
+	data := printer.ReceiptData{Restaurant: a.config.Restaurant, Order: o}
+	a.enqueueProductionTickets(data, items, printer.BuildCancellationTicket, "Cancelamento")
+	a.sendToDisplay(o, kds.KindCancel, items)
+}
+
+func (a *App) enqueueProductionTickets(data printer.ReceiptData, items []pos.OrderItem,
+	build func(printer.ReceiptData, string, []pos.OrderItem) []byte, title string) {
+	for _, group := range printer.GroupByStation(items, a.config.RouteFor) {
+		stationData := data
+		stationData.CharsPerLine = a.config.Printer.CharsPerLine
+		if pc, ok := a.config.StationPrinter(group.Station); ok {
+			stationData.CharsPerLine = pc.CharsPerLine
+		}
+		a.spooler.Enqueue(group.Station,
+			fmt.Sprintf("%s %s #%d", title, group.Station, data.Order.Number),
+			build(stationData, group.Station, group.Items))
+	}
+}
+
+// sendToKitchen sends the new items of an open order to the stations
+// without closing it, as in table service.
+func (a *App) sendToKitchen() {
+	if len(a.order.PendingKitchenItems()) == 0 {
+		dialog.ShowInformation("Cozinha", "Nenhum item novo para enviar.", a.mainWindow)
+		return
+	}
+	a.enqueueStationTickets(printer.ReceiptData{
+		Restaurant:   a.config.Restaurant,
+		Order:        a.order,
+		CharsPerLine: a.config.Printer.CharsPerLine,
+	})
+	a.refreshOrderDisplay()
 }
 
 func (a *App) showSplitPaymentDialog() {
@@ -221,6 +438,9 @@ func (a *App) showSplitPaymentDialog() {
 		dialog.ShowInformation("Aviso", "Adicione itens ao pedido.", a.mainWindow)
 		return
 	}
+	if !a.requireCharge() {
+		return
+	}
 
 	// State setup
 	splits := make([]pos.PaymentSplit, 0)
@@ -323,8 +543,9 @@ func (a *App) printTest() {
 	if !a.requirePrinterConnected() {
 		return
 	}
+	p := a.printer
 	go func() {
-		if err := a.printer.PrintTest(); err != nil {
+		if err := p.PrintTest(); err != nil {
 			log.Printf("Erro no teste: %v", err)
 			fyne.Do(func() {
 				dialog.ShowError(fmt.Errorf("erro no teste: %w", err), a.mainWindow)
@@ -333,12 +554,21 @@ func (a *App) printTest() {
 	}()
 }
 
+// openDrawer opens the cash drawer outside of a sale, recording who did
+// it in the open session.
 func (a *App) openDrawer() {
-	if !a.requirePrinterConnected() {
+	if !a.requireCharge() || !a.requirePrinterConnected() {
 		return
 	}
+	if a.session != nil && a.session.IsOpen() {
+		a.session.RecordDrawerOpening(a.operatorID(), a.operatorName)
+		if err := storage.SaveCashSession(a.session); err != nil {
+			log.Printf("Erro ao salvar caixa: %v", err)
+		}
+	}
+	p := a.printer
 	go func() {
-		if err := a.printer.OpenDrawer(); err != nil {
+		if err := p.OpenDrawer(); err != nil {
 			log.Printf("Erro ao abrir gaveta: %v", err)
 			fyne.Do(func() {
 				dialog.ShowError(fmt.Errorf("erro ao abrir gaveta: %w", err), a.mainWindow)
@@ -350,10 +580,15 @@ func (a *App) openDrawer() {
 func (a *App) reconnectPrinter() {
 	if a.printer != nil {
 		a.printer.Close()
-		a.printer = nil
+		a.setPrinter(nil)
+	}
+	for _, p := range a.stations {
+		p.Close()
 	}
 	a.connectPrinter()
+	a.connectStations()
 	a.updatePrinterStatus()
+	a.spooler.Wake()
 }
 
 func sanitizeDecimal(s string) string {
@@ -369,6 +604,15 @@ func sanitizeDecimal(s string) string {
 	return string(result)
 }
 
+// formatCurrencyInput renders centavos the way parseCurrencyInput reads
+// them back ("10,50"), or blank for zero.
+func formatCurrencyInput(cents int64) string {
+	if cents <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d,%02d", cents/100, cents%100)
+}
+
 func parseCurrencyInput(text string) (int64, bool) {
 	text = sanitizeDecimal(text)
 	if text == "" {
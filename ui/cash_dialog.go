@@ -0,0 +1,336 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"notinha/internal/pos"
+	"notinha/internal/printer"
+	"notinha/internal/storage"
+)
+
+// loadCashSession restores the register left open by a previous run.
+func (a *App) loadCashSession() {
+	session, err := storage.CurrentCashSession()
+	if err != nil {
+		log.Printf("Aviso: erro ao carregar caixa: %v", err)
+		return
+	}
+	a.session = session
+}
+
+// requireCashSession reports whether the register is open, offering to
+// open it when it is not.
+func (a *App) requireCashSession() bool {
+	if a.session != nil && a.session.IsOpen() {
+		return true
+	}
+	dialog.ShowConfirm("Caixa fechado", "Abra o caixa antes de finalizar pedidos. Abrir agora?",
+		func(ok bool) {
+			if ok {
+				a.showOpenCashDialog()
+			}
+		}, a.mainWindow)
+	return false
+}
+
+// operatorEntry offers the last operator name typed. With someone logged
+// in it shows their name and cannot be changed.
+func (a *App) operatorEntry() *widget.Entry {
+	e := widget.NewEntry()
+	e.SetText(a.operatorName)
+	e.SetPlaceHolder("Nome do operador")
+	if a.operator != nil {
+		e.SetText(a.operator.Name)
+		e.Disable()
+	}
+	return e
+}
+
+// addCashMovement records a sangria or suprimento in the open session on
+// behalf of the current operator.
+func (a *App) addCashMovement(kind pos.CashMovementKind, amount int64, reason string, orders []pos.Order) error {
+	return a.session.AddMovement(kind, amount, reason, a.operatorID(), a.operatorName, orders)
+}
+
+func (a *App) showOpenCashDialog() {
+	if !a.requireCharge() {
+		return
+	}
+	if a.session != nil && a.session.IsOpen() {
+		dialog.ShowInformation("Caixa", fmt.Sprintf("Caixa #%d ja esta aberto.", a.session.ID), a.mainWindow)
+		return
+	}
+
+	floatEntry := widget.NewEntry()
+	floatEntry.SetPlaceHolder("Fundo de troco (R$)")
+	operator := a.operatorEntry()
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Fundo de troco", floatEntry),
+		widget.NewFormItem("Operador", operator),
+	}
+	dialog.ShowForm("Abrir Caixa", "Abrir", "Cancelar", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		openingFloat, valid := parseCountInput(floatEntry.Text)
+		if !valid {
+			dialog.ShowInformation("Aviso", "Valor invalido.", a.mainWindow)
+			return
+		}
+		a.operatorName = strings.TrimSpace(operator.Text)
+		session, err := storage.OpenCashSession(openingFloat, a.operatorID(), a.operatorName)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("erro ao abrir caixa: %w", err), a.mainWindow)
+			return
+		}
+		a.session = session
+		a.updatePrinterStatus()
+	}, a.mainWindow)
+}
+
+func (a *App) showCashMovementDialog(kind pos.CashMovementKind) {
+	if !a.requireCharge() {
+		return
+	}
+	if a.session == nil || !a.session.IsOpen() {
+		dialog.ShowInformation("Caixa", "Nenhum caixa aberto.", a.mainWindow)
+		return
+	}
+
+	amountEntry := widget.NewEntry()
+	amountEntry.SetPlaceHolder("Valor (R$)")
+	reasonEntry := widget.NewEntry()
+	reasonEntry.SetPlaceHolder("Motivo")
+	operator := a.operatorEntry()
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Valor", amountEntry),
+		widget.NewFormItem("Motivo", reasonEntry),
+		widget.NewFormItem("Operador", operator),
+	}
+	dialog.ShowForm(string(kind), "Registrar", "Cancelar", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		amount, valid := parseCurrencyInput(amountEntry.Text)
+		if !valid {
+			dialog.ShowInformation("Aviso", "Valor invalido.", a.mainWindow)
+			return
+		}
+		orders, err := storage.LoadSessionOrders(a.session)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("erro ao carregar pedidos do caixa: %w", err), a.mainWindow)
+			return
+		}
+		a.operatorName = strings.TrimSpace(operator.Text)
+		if err := a.addCashMovement(kind, amount, reasonEntry.Text, orders); err != nil {
+			dialog.ShowError(err, a.mainWindow)
+			return
+		}
+		if err := storage.SaveCashSession(a.session); err != nil {
+			log.Printf("Erro ao salvar caixa: %v", err)
+			dialog.ShowError(fmt.Errorf("erro ao salvar caixa: %w", err), a.mainWindow)
+			return
+		}
+		dialog.ShowInformation(string(kind),
+			fmt.Sprintf("%s de %s registrada.", kind, pos.FormatBRL(amount)), a.mainWindow)
+	}, a.mainWindow)
+}
+
+func (a *App) showCloseCashDialog() {
+	if !a.requireCharge() {
+		return
+	}
+	if a.session == nil || !a.session.IsOpen() {
+		dialog.ShowInformation("Caixa", "Nenhum caixa aberto.", a.mainWindow)
+		return
+	}
+
+	// Blind count: the operator types what is in the drawer and in the
+	// card/PIX reports without seeing the expected amounts first.
+	entries := make(map[pos.PaymentMethod]*widget.Entry)
+	var items []*widget.FormItem
+	for _, m := range pos.PaymentMethods() {
+		e := widget.NewEntry()
+		e.SetPlaceHolder("Valor contado (R$)")
+		entries[m] = e
+		items = append(items, widget.NewFormItem(string(m), e))
+	}
+	operator := a.operatorEntry()
+	items = append(items, widget.NewFormItem("Operador", operator))
+
+	d := dialog.NewForm(fmt.Sprintf("Fechar Caixa #%d", a.session.ID), "Fechar Caixa", "Cancelar", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		counted := make(map[pos.PaymentMethod]int64)
+		for m, e := range entries {
+			v, valid := parseCountInput(e.Text)
+			if !valid {
+				dialog.ShowInformation("Aviso", fmt.Sprintf("Valor invalido em %s.", m), a.mainWindow)
+				return
+			}
+			counted[m] = v
+		}
+		a.closeCashSession(counted, strings.TrimSpace(operator.Text))
+	}, a.mainWindow)
+	d.Resize(fyne.NewSize(400, 300))
+	d.Show()
+}
+
+func (a *App) closeCashSession(counted map[pos.PaymentMethod]int64, operator string) {
+	orders, err := storage.LoadSessionOrders(a.session)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("erro ao carregar pedidos do caixa: %w", err), a.mainWindow)
+		return
+	}
+
+	session := *a.session
+	if err := session.Close(counted, operator, orders); err != nil {
+		dialog.ShowError(err, a.mainWindow)
+		return
+	}
+	session.ClosedByID = a.operatorID()
+	session.ZNumber = a.config.NextZNumber()
+	if err := storage.SaveCashSession(&session); err != nil {
+		log.Printf("Erro ao salvar caixa: %v", err)
+		dialog.ShowError(fmt.Errorf("erro ao salvar caixa: %w", err), a.mainWindow)
+		return
+	}
+	a.operatorName = operator
+	a.session = nil
+	a.updatePrinterStatus()
+
+	receipt := printer.BuildCashClosingReceipt(printer.CashClosingData{
+		Restaurant:   a.config.Restaurant,
+		Session:      &session,
+		CharsPerLine: a.config.Printer.CharsPerLine,
+	})
+	a.spooler.Enqueue(storage.CashierPrinter, fmt.Sprintf("Fechamento caixa #%d", session.ID), receipt)
+	a.spooler.Enqueue(storage.CashierPrinter, fmt.Sprintf("Reducao Z #%d", session.ZNumber),
+		a.shiftReport(&session, orders))
+
+	dialog.ShowInformation("Caixa Fechado", formatCashClosing(&session), a.mainWindow)
+}
+
+func (a *App) shiftReport(session *pos.CashSession, orders []pos.Order) []byte {
+	report := pos.NewShiftReport(session, orders, a.config.BusinessDayCutoff)
+	report.Summary.NameOperators(a.operators)
+	return printer.BuildShiftReport(printer.ShiftReportData{
+		Restaurant:   a.config.Restaurant,
+		Report:       report,
+		CharsPerLine: a.config.Printer.CharsPerLine,
+	})
+}
+
+// showXReport previews the leitura X of the open session, offering to
+// print it. Nothing is reset.
+func (a *App) showXReport() {
+	if !a.requireCharge() {
+		return
+	}
+	if a.session == nil || !a.session.IsOpen() {
+		dialog.ShowInformation("Caixa", "Nenhum caixa aberto.", a.mainWindow)
+		return
+	}
+	orders, err := storage.LoadSessionOrders(a.session)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("erro ao carregar pedidos do caixa: %w", err), a.mainWindow)
+		return
+	}
+	report := a.shiftReport(a.session, orders)
+	a.showPreviewDialog("Leitura X", report, a.config.Printer.CharsPerLine, func() {
+		a.spooler.Enqueue(storage.CashierPrinter, fmt.Sprintf("Leitura X caixa #%d", a.session.ID), report)
+	})
+}
+
+// showZReportsDialog lists the issued reducoes Z for reprinting.
+func (a *App) showZReportsDialog() {
+	sessions, err := storage.LoadCashSessions()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("erro ao carregar caixas: %w", err), a.mainWindow)
+		return
+	}
+	var closed []pos.CashSession
+	for i := len(sessions) - 1; i >= 0; i-- {
+		if !sessions[i].IsOpen() && sessions[i].ZNumber > 0 {
+			closed = append(closed, sessions[i])
+		}
+	}
+	if len(closed) == 0 {
+		dialog.ShowInformation("Reducoes Z", "Nenhuma reducao Z emitida.", a.mainWindow)
+		return
+	}
+
+	list := widget.NewList(
+		func() int { return len(closed) },
+		func() fyne.CanvasObject {
+			return widget.NewLabel("Z #0000 - Caixa #000 - 00/00/0000 00:00")
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			s := closed[id]
+			obj.(*widget.Label).SetText(fmt.Sprintf("Z #%04d - Caixa #%d - %s",
+				s.ZNumber, s.ID, s.ClosedAt.Format("02/01/2006 15:04")))
+		},
+	)
+
+	d := dialog.NewCustom("Reducoes Z", "Fechar", list, a.mainWindow)
+	list.OnSelected = func(id widget.ListItemID) {
+		list.UnselectAll()
+		session := closed[id]
+		orders, err := storage.LoadSessionOrders(&session)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("erro ao carregar pedidos do caixa: %w", err), a.mainWindow)
+			return
+		}
+		report := a.shiftReport(&session, orders)
+		a.showPreviewDialog(fmt.Sprintf("Reducao Z #%04d", session.ZNumber), report, a.config.Printer.CharsPerLine, func() {
+			a.spooler.Enqueue(storage.CashierPrinter, fmt.Sprintf("Reducao Z #%d", session.ZNumber), report)
+		})
+	}
+	d.Resize(fyne.NewSize(450, 400))
+	d.Show()
+}
+
+func formatCashClosing(s *pos.CashSession) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Caixa #%d fechado.\n\n", s.ID)
+	for _, m := range pos.PaymentMethods() {
+		if s.Expected[m] == 0 && s.Counted[m] == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "%s: esperado %s, contado %s\n", m,
+			pos.FormatBRL(s.Expected[m]), pos.FormatBRL(s.Counted[m]))
+	}
+	diff := s.TotalDifference()
+	switch {
+	case diff > 0:
+		fmt.Fprintf(&b, "\nSobra: %s", pos.FormatBRL(diff))
+	case diff < 0:
+		fmt.Fprintf(&b, "\nFalta: %s", pos.FormatBRL(-diff))
+	default:
+		b.WriteString("\nCaixa conferido sem diferencas.")
+	}
+	return b.String()
+}
+
+// parseCountInput parses a counted amount, where blank or zero is valid.
+func parseCountInput(text string) (int64, bool) {
+	clean := sanitizeDecimal(text)
+	if clean == "" {
+		return 0, true
+	}
+	val, err := strconv.ParseFloat(clean, 64)
+	if err != nil || val < 0 {
+		return 0, false
+	}
+	return int64(val*100 + 0.5), true
+}
@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"notinha/internal/pos"
+	"notinha/internal/storage"
+)
+
+// comboSuggestionCount is how many combos the current order could form,
+// taken before adding an item to tell whether a new one became possible.
+func (a *App) comboSuggestionCount() int {
+	return len(a.order.SuggestCombos(a.menu.Combos))
+}
+
+// offerCombos shows the combo suggestions when adding an item made a new
+// one possible; before is comboSuggestionCount from before the change.
+func (a *App) offerCombos(before int) {
+	if a.comboSuggestionCount() > before {
+		a.showComboSuggestions()
+	}
+}
+
+// showComboSuggestions lists the combos the order's items can be bundled
+// into, with the savings of each.
+func (a *App) showComboSuggestions() {
+	suggestions := a.order.SuggestCombos(a.menu.Combos)
+	if len(suggestions) == 0 {
+		dialog.ShowInformation("Combos", "Nenhum combo disponivel para os itens do pedido.", a.mainWindow)
+		return
+	}
+
+	var d *dialog.CustomDialog
+	content := container.NewVBox()
+	for _, s := range suggestions {
+		combo := s.Combo
+		label := widget.NewLabel(fmt.Sprintf("%s - %s (economia de %s)",
+			combo.Name, pos.FormatBRL(combo.Price), pos.FormatBRL(s.Savings)))
+		applyBtn := widget.NewButton("Aplicar", func() {
+			d.Hide()
+			if !a.requireEditableOrder() {
+				return
+			}
+			if err := a.order.ApplyCombo(combo); err != nil {
+				dialog.ShowError(err, a.mainWindow)
+				return
+			}
+			a.refreshOrderDisplay()
+		})
+		applyBtn.Importance = widget.HighImportance
+		content.Add(container.NewBorder(nil, nil, nil, applyBtn, label))
+	}
+	d = dialog.NewCustom("Combos disponiveis", "Agora nao", content, a.mainWindow)
+	d.Resize(fyne.NewSize(500, 0))
+	d.Show()
+}
+
+// breakCombo returns the items of a combo line to the order at their own
+// prices.
+func (a *App) breakCombo(index int) {
+	combo := a.order.Items[index]
+	msg := fmt.Sprintf("Separar o combo %s? Os itens voltam ao preco normal.", combo.Item.Name)
+	dialog.ShowConfirm("Separar Combo", msg, func(ok bool) {
+		if !ok {
+			return
+		}
+		if err := a.order.BreakCombo(index); err != nil {
+			dialog.ShowError(err, a.mainWindow)
+			return
+		}
+		a.refreshOrderDisplay()
+	}, a.mainWindow)
+}
+
+// showCombosEditor edits the menu's combos, one per line in the form
+// "Pizza + Refri = 52,00: Pizzas *; Coca-Cola 2L | Guarana 2L".
+func (a *App) showCombosEditor() {
+	lines := make([]string, len(a.menu.Combos))
+	for i, c := range a.menu.Combos {
+		lines[i] = a.menu.FormatCombo(c)
+	}
+	entry := widget.NewMultiLineEntry()
+	entry.SetText(strings.Join(lines, "\n"))
+	entry.SetPlaceHolder("Pizza + Refri = 52,00: Pizzas *; Coca-Cola 2L | Guarana 2L\nBalde = 45,00: 5x Cervejas - Long Neck")
+	entry.SetMinRowsVisible(10)
+
+	help := widget.NewLabel("Um combo por linha: Nome = preco: item; item\n" +
+		"Cada item e um nome do cardapio ou uma categoria (aceita *); use | para alternativas e 5x para quantidade")
+
+	d := dialog.NewCustomConfirm("Combos", "Salvar", "Cancelar",
+		container.NewBorder(help, nil, nil, nil, entry), func(ok bool) {
+			if !ok {
+				return
+			}
+			var combos []pos.Combo
+			for _, line := range strings.Split(entry.Text, "\n") {
+				if strings.TrimSpace(line) == "" {
+					continue
+				}
+				c, err := a.menu.ParseCombo(line)
+				if err != nil {
+					dialog.ShowError(err, a.mainWindow)
+					return
+				}
+				combos = append(combos, c)
+			}
+			a.menu.Combos = combos
+			if err := storage.SaveMenu(a.menu); err != nil {
+				dialog.ShowError(fmt.Errorf("erro ao salvar cardapio: %w", err), a.mainWindow)
+			}
+		}, a.mainWindow)
+	d.Resize(fyne.NewSize(700, 450))
+	d.Show()
+}
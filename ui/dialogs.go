@@ -3,14 +3,18 @@ package ui
 import (
 	"fmt"
 	"log"
+	"sort"
 	"strconv"
+	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	fynestorage "fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/widget"
 
 	"notinha/internal/pos"
+	"notinha/internal/printer"
 	"notinha/internal/storage"
 )
 
@@ -30,12 +34,94 @@ func (a *App) showConfigDialog() {
 	footerEntry := widget.NewEntry()
 	footerEntry.SetText(a.config.Restaurant.Footer)
 
+	logoEntry := widget.NewEntry()
+	logoEntry.SetText(a.config.Restaurant.Logo)
+	logoEntry.SetPlaceHolder("Arquivo PNG/JPG do logo (opcional)")
+	logoBtn := widget.NewButton("...", func() {
+		fd := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+			if err != nil || r == nil {
+				return
+			}
+			logoEntry.SetText(r.URI().Path())
+			r.Close()
+		}, a.mainWindow)
+		fd.SetFilter(fynestorage.NewExtensionFileFilter([]string{".png", ".jpg", ".jpeg", ".gif"}))
+		fd.Show()
+	})
+
+	pixKeyEntry := widget.NewEntry()
+	pixKeyEntry.SetText(a.config.Restaurant.PixKey)
+	pixKeyEntry.SetPlaceHolder("CNPJ, telefone, e-mail ou chave aleatoria")
+
+	pixNameEntry := widget.NewEntry()
+	pixNameEntry.SetText(a.config.Restaurant.PixName)
+	pixNameEntry.SetPlaceHolder("Titular da conta (padrao: Nome)")
+
+	pixCityEntry := widget.NewEntry()
+	pixCityEntry.SetText(a.config.Restaurant.PixCity)
+
 	printerEntry := widget.NewEntry()
 	printerEntry.SetText(a.config.Printer.DevicePath)
 
+	printerEntry.SetPlaceHolder("/dev/usb/lp0, tcp://192.168.0.50:9100 ou file:///pasta")
+	virtualBtn := widget.NewButton("Virtual...", func() {
+		fd := dialog.NewFolderOpen(func(dir fyne.ListableURI, err error) {
+			if err != nil || dir == nil {
+				return
+			}
+			printerEntry.SetText(printer.FileScheme + dir.Path())
+		}, a.mainWindow)
+		fd.Show()
+	})
+
 	charsEntry := widget.NewEntry()
 	charsEntry.SetText(fmt.Sprintf("%d", a.config.Printer.CharsPerLine))
 
+	serviceEntry := widget.NewEntry()
+	serviceEntry.SetText(strconv.Itoa(a.config.ServiceCharge))
+
+	pizzaEntry := widget.NewEntry()
+	pizzaEntry.SetText(strings.Join(a.config.Pizza.Categories, ", "))
+	pizzaEntry.SetPlaceHolder("Pizzas *")
+
+	var pricingLabels []string
+	for _, p := range pos.PizzaPricings() {
+		pricingLabels = append(pricingLabels, p.Label())
+	}
+	pricingSelect := widget.NewSelect(pricingLabels, nil)
+	pricingSelect.SetSelected(a.config.Pizza.Pricing.Label())
+
+	pinEntry := widget.NewPasswordEntry()
+	pinEntry.SetPlaceHolder("novo PIN")
+	pinCheck := widget.NewCheck("Exigir PIN do gerente nos estornos", nil)
+	pinCheck.SetChecked(a.config.ManagerPIN != "")
+
+	kdsEntry := widget.NewEntry()
+	kdsEntry.SetText(a.config.KDSAddress)
+	kdsEntry.SetPlaceHolder(":8090")
+
+	cutoffEntry := widget.NewEntry()
+	cutoffEntry.SetText(strconv.Itoa(a.config.BusinessDayCutoff))
+
+	subnetEntry := widget.NewEntry()
+	subnetEntry.SetText(a.config.Printer.ScanSubnet)
+	subnetEntry.SetPlaceHolder("192.168.0.0/24")
+
+	stationsEntry := widget.NewMultiLineEntry()
+	stationsEntry.SetText(formatStationPrinters(a.config.Printers))
+	stationsEntry.SetPlaceHolder("cozinha = tcp://192.168.0.51\nbar = /dev/usb/lp1")
+	stationsEntry.SetMinRowsVisible(3)
+
+	routesEntry := widget.NewMultiLineEntry()
+	routesEntry.SetText(formatRoutes(a.config.Routes))
+	routesEntry.SetPlaceHolder("Chopp = bar\nPizzas * = cozinha")
+	routesEntry.SetMinRowsVisible(4)
+
+	tablesEntry := widget.NewMultiLineEntry()
+	tablesEntry.SetText(formatTables(a.config.Tables))
+	tablesEntry.SetPlaceHolder("Salao = 1-10\nVaranda = 11-16")
+	tablesEntry.SetMinRowsVisible(3)
+
 	form := &widget.Form{
 		Items: []*widget.FormItem{
 			{Text: "Nome", Widget: nameEntry},
@@ -43,8 +129,24 @@ func (a *App) showConfigDialog() {
 			{Text: "Telefone", Widget: phoneEntry},
 			{Text: "CNPJ", Widget: cnpjEntry},
 			{Text: "Rodape", Widget: footerEntry},
-			{Text: "Impressora", Widget: printerEntry},
+			{Text: "Logo", Widget: container.NewBorder(nil, nil, nil, logoBtn, logoEntry)},
+			{Text: "Chave PIX", Widget: pixKeyEntry},
+			{Text: "Titular PIX", Widget: pixNameEntry},
+			{Text: "Cidade PIX", Widget: pixCityEntry},
+			{Text: "Impressora", Widget: container.NewBorder(nil, nil, nil, virtualBtn, printerEntry),
+				HintText: "Virtual: salva cada impressao em .bin e .txt na pasta"},
 			{Text: "Colunas", Widget: charsEntry},
+			{Text: "Rede (busca)", Widget: subnetEntry},
+			{Text: "Impressoras", Widget: stationsEntry, HintText: "nome = caminho, uma por linha"},
+			{Text: "Rotas", Widget: routesEntry, HintText: "categoria = impressora, uma por linha"},
+			{Text: "Mesas", Widget: tablesEntry, HintText: "area = numeros (ex: 1-10, 15), uma por linha"},
+			{Text: "Taxa de servico", Widget: serviceEntry, HintText: "% sugerido em novos pedidos (0 = sem taxa)"},
+			{Text: "Pizzas", Widget: pizzaEntry, HintText: "categorias que aceitam varios sabores, separadas por virgula"},
+			{Text: "Preco meio a meio", Widget: pricingSelect},
+			{Text: "PIN do gerente", Widget: container.NewVBox(pinCheck, pinEntry),
+				HintText: "em branco mantem o PIN atual; com operadores vale o PIN de um gerente"},
+			{Text: "Tela da cozinha", Widget: kdsEntry, HintText: "endereco do servidor (ex: :8090, vazio = desligada); vale ao reiniciar"},
+			{Text: "Virada do dia", Widget: cutoffEntry, HintText: "hora (0-23) em que comeca um novo dia de movimento"},
 		},
 		OnSubmit: func() {},
 	}
@@ -54,16 +156,54 @@ func (a *App) showConfigDialog() {
 			if !save {
 				return
 			}
+			tables, err := parseTables(tablesEntry.Text)
+			if err != nil {
+				dialog.ShowError(err, a.mainWindow)
+				return
+			}
+			managerPIN := a.config.ManagerPIN
+			switch {
+			case !pinCheck.Checked:
+				managerPIN = ""
+			case strings.TrimSpace(pinEntry.Text) != "":
+				if managerPIN, err = pos.HashPIN(strings.TrimSpace(pinEntry.Text)); err != nil {
+					dialog.ShowError(err, a.mainWindow)
+					return
+				}
+			case managerPIN == "":
+				dialog.ShowInformation("Aviso", "Informe o PIN do gerente.", a.mainWindow)
+				return
+			}
 			a.config.Restaurant.Name = nameEntry.Text
 			a.config.Restaurant.Address = addressEntry.Text
 			a.config.Restaurant.Phone = phoneEntry.Text
 			a.config.Restaurant.CNPJ = cnpjEntry.Text
 			a.config.Restaurant.Footer = footerEntry.Text
+			a.config.Restaurant.Logo = strings.TrimSpace(logoEntry.Text)
+			a.config.Restaurant.PixKey = strings.TrimSpace(pixKeyEntry.Text)
+			a.config.Restaurant.PixName = pixNameEntry.Text
+			a.config.Restaurant.PixCity = pixCityEntry.Text
 			a.config.Printer.DevicePath = printerEntry.Text
+			a.config.Printer.ScanSubnet = strings.TrimSpace(subnetEntry.Text)
+			a.config.Printers = parseStationPrinters(stationsEntry.Text, a.config.Printers)
+			a.config.Routes = parseRoutes(routesEntry.Text)
+			a.config.Tables = tables
+			a.config.Pizza.Categories = parseCommaList(pizzaEntry.Text)
+			a.config.KDSAddress = strings.TrimSpace(kdsEntry.Text)
+			a.config.ManagerPIN = managerPIN
+			if i := pricingSelect.SelectedIndex(); i >= 0 {
+				a.config.Pizza.Pricing = pos.PizzaPricings()[i]
+			}
 
 			if chars, err := strconv.Atoi(charsEntry.Text); err == nil && chars > 0 {
 				a.config.Printer.CharsPerLine = chars
 			}
+			if pct, err := strconv.Atoi(strings.TrimSpace(serviceEntry.Text)); err == nil && pct >= 0 && pct <= 100 {
+				a.config.ServiceCharge = pct
+			}
+			if hour, err := strconv.Atoi(strings.TrimSpace(cutoffEntry.Text)); err == nil && hour >= 0 && hour < 24 {
+				a.config.BusinessDayCutoff = hour
+			}
 
 			if err := storage.SaveConfig(a.config); err != nil {
 				log.Printf("Erro ao salvar config: %v", err)
@@ -72,12 +212,103 @@ func (a *App) showConfigDialog() {
 			}
 
 			a.reconnectPrinter()
+			a.refreshMenuTabs()
 		}, a.mainWindow)
 
-	d.Resize(fyne.NewSize(500, 450))
+	d.Resize(fyne.NewSize(550, 600))
 	d.Show()
 }
 
+// parseKeyValueLines splits "key = value" lines, skipping blank or
+// malformed ones.
+func parseKeyValueLines(text string) [][2]string {
+	var pairs [][2]string
+	for _, line := range strings.Split(text, "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" || value == "" {
+			continue
+		}
+		pairs = append(pairs, [2]string{key, value})
+	}
+	return pairs
+}
+
+func formatStationPrinters(printers map[string]storage.PrinterConfig) string {
+	names := make([]string, 0, len(printers))
+	for name := range printers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var lines []string
+	for _, name := range names {
+		lines = append(lines, name+" = "+printers[name].DevicePath)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseStationPrinters rebuilds the station registry from the dialog text,
+// keeping per-station settings (such as column width) of existing entries.
+func parseStationPrinters(text string, current map[string]storage.PrinterConfig) map[string]storage.PrinterConfig {
+	printers := make(map[string]storage.PrinterConfig)
+	for _, kv := range parseKeyValueLines(text) {
+		name := strings.ToLower(kv[0])
+		pc := current[name]
+		pc.DevicePath = kv[1]
+		printers[name] = pc
+	}
+	return printers
+}
+
+func formatRoutes(routes []storage.PrinterRoute) string {
+	var lines []string
+	for _, r := range routes {
+		lines = append(lines, r.Category+" = "+r.Printer)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func parseRoutes(text string) []storage.PrinterRoute {
+	var routes []storage.PrinterRoute
+	for _, kv := range parseKeyValueLines(text) {
+		routes = append(routes, storage.PrinterRoute{Category: kv[0], Printer: strings.ToLower(kv[1])})
+	}
+	return routes
+}
+
+func formatTables(tables []pos.Table) string {
+	byArea := make(map[string][]int)
+	for _, t := range tables {
+		byArea[t.Area] = append(byArea[t.Area], t.Number)
+	}
+	var lines []string
+	for _, area := range pos.TableAreas(tables) {
+		lines = append(lines, area+" = "+pos.FormatTableNumbers(byArea[area]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseTables reads the "area = 1-10" lines of the table layout. A table
+// number may appear only once.
+func parseTables(text string) ([]pos.Table, error) {
+	var tables []pos.Table
+	seen := map[int]string{}
+	for _, kv := range parseKeyValueLines(text) {
+		numbers, err := pos.ParseTableNumbers(kv[1])
+		if err != nil {
+			return nil, fmt.Errorf("area %s: %w", kv[0], err)
+		}
+		for _, n := range numbers {
+			if area, dup := seen[n]; dup {
+				return nil, fmt.Errorf("mesa %d repetida em %s e %s", n, area, kv[0])
+			}
+			seen[n] = kv[0]
+			tables = append(tables, pos.Table{Number: n, Area: kv[0]})
+		}
+	}
+	return tables, nil
+}
+
 func (a *App) showMenuEditorDialog() {
 	var itemList *widget.List
 	var selectedIndex int = -1
@@ -91,6 +322,9 @@ func (a *App) showMenuEditorDialog() {
 	categoryEntry := widget.NewEntry()
 	categoryEntry.SetPlaceHolder("Categoria")
 
+	optionsEntry := widget.NewEntry()
+	optionsEntry.SetPlaceHolder("Grupos de opcoes (ex: Tamanho, Borda)")
+
 	activeItems := a.activeMenuItems()
 
 	itemList = widget.NewList(
@@ -115,6 +349,7 @@ func (a *App) showMenuEditorDialog() {
 			nameEntry.SetText(item.Name)
 			priceEntry.SetText(formatPriceForEdit(item.Price))
 			categoryEntry.SetText(item.Category)
+			optionsEntry.SetText(strings.Join(item.OptionGroups, ", "))
 		}
 	}
 
@@ -125,11 +360,12 @@ func (a *App) showMenuEditorDialog() {
 			return
 		}
 		a.menu.AddItem(pos.MenuItem{
-			Name:     nameEntry.Text,
-			Price:    price,
-			Category: categoryEntry.Text,
+			Name:         nameEntry.Text,
+			Price:        price,
+			Category:     categoryEntry.Text,
+			OptionGroups: parseCommaList(optionsEntry.Text),
 		})
-		a.saveMenuAndRefresh(&activeItems, itemList, nameEntry, priceEntry, categoryEntry)
+		a.saveMenuAndRefresh(&activeItems, itemList, nameEntry, priceEntry, categoryEntry, optionsEntry)
 	})
 
 	updateBtn := widget.NewButton("Atualizar", func() {
@@ -140,8 +376,9 @@ func (a *App) showMenuEditorDialog() {
 		item.Name = nameEntry.Text
 		item.Price = parsePrice(priceEntry.Text)
 		item.Category = categoryEntry.Text
+		item.OptionGroups = parseCommaList(optionsEntry.Text)
 		a.menu.UpdateItem(item)
-		a.saveMenuAndRefresh(&activeItems, itemList, nameEntry, priceEntry, categoryEntry)
+		a.saveMenuAndRefresh(&activeItems, itemList, nameEntry, priceEntry, categoryEntry, optionsEntry)
 	})
 
 	removeBtn := widget.NewButton("Remover", func() {
@@ -150,14 +387,27 @@ func (a *App) showMenuEditorDialog() {
 		}
 		a.menu.RemoveItem(activeItems[selectedIndex].ID)
 		selectedIndex = -1
-		a.saveMenuAndRefresh(&activeItems, itemList, nameEntry, priceEntry, categoryEntry)
+		a.saveMenuAndRefresh(&activeItems, itemList, nameEntry, priceEntry, categoryEntry, optionsEntry)
+	})
+
+	groupsBtn := widget.NewButton("Grupos...", func() {
+		a.showOptionGroupsDialog()
+	})
+
+	combosBtn := widget.NewButton("Combos...", func() {
+		a.showCombosEditor()
+	})
+
+	pricesBtn := widget.NewButton("Precos...", func() {
+		a.showPriceRulesEditor()
 	})
 
 	formPanel := container.NewVBox(
 		widget.NewLabel("Nome:"), nameEntry,
 		widget.NewLabel("Preco:"), priceEntry,
 		widget.NewLabel("Categoria:"), categoryEntry,
-		container.NewHBox(addBtn, updateBtn, removeBtn),
+		widget.NewLabel("Opcoes:"), container.NewBorder(nil, nil, nil, groupsBtn, optionsEntry),
+		container.NewHBox(addBtn, updateBtn, removeBtn, combosBtn, pricesBtn),
 	)
 
 	content := container.NewBorder(nil, formPanel, nil, nil, itemList)
@@ -168,7 +418,7 @@ func (a *App) showMenuEditorDialog() {
 }
 
 func (a *App) saveMenuAndRefresh(activeItems *[]pos.MenuItem, list *widget.List,
-	nameEntry, priceEntry, categoryEntry *widget.Entry) {
+	nameEntry, priceEntry, categoryEntry, optionsEntry *widget.Entry) {
 
 	if err := storage.SaveMenu(a.menu); err != nil {
 		log.Printf("Erro ao salvar cardapio: %v", err)
@@ -178,6 +428,7 @@ func (a *App) saveMenuAndRefresh(activeItems *[]pos.MenuItem, list *widget.List,
 	nameEntry.SetText("")
 	priceEntry.SetText("")
 	categoryEntry.SetText("")
+	optionsEntry.SetText("")
 	a.refreshMenuTabs()
 }
 
@@ -191,6 +442,18 @@ func (a *App) activeMenuItems() []pos.MenuItem {
 	return items
 }
 
+// parseCommaList splits a comma-separated list, such as the option group
+// names of a menu item, dropping blank entries.
+func parseCommaList(text string) []string {
+	var names []string
+	for _, name := range strings.Split(text, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 func parsePrice(text string) int64 {
 	cents, _ := parseCurrencyInput(text)
 	return cents
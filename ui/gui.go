@@ -1,8 +1,10 @@
 package ui
 
 import (
+	"fmt"
 	"log"
 	"strings"
+	"sync"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
@@ -10,6 +12,7 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 
+	"notinha/internal/kds"
 	"notinha/internal/pos"
 	"notinha/internal/printer"
 	"notinha/internal/storage"
@@ -21,25 +24,49 @@ type App struct {
 	mainWindow fyne.Window
 	config     *storage.Config
 	menu       *pos.Menu
-	order      *pos.Order
+	order      *pos.Order   // order being edited, one of openOrders
+	openOrders []*pos.Order // tabs still open, by table or customer
 	printer    *printer.Printer
+	stations   map[string]*printer.Printer // production printers by name (cozinha, bar)
+	printerMu  sync.RWMutex                // guards printer and stations for the background goroutines
+	spooler    *printer.Spooler
+	kds        *kds.Server // kitchen display; nil when not configured
+
+	// Last real-time printer status summary; empty when the transport
+	// cannot report it.
+	printerState string
 
 	// Split payment state
 	splitPayments []pos.PaymentSplit
 
+	// Open cash register session; nil while the register is closed.
+	session *pos.CashSession
+	// Operator name last typed in a cash dialog, offered as the default;
+	// the logged in operator's name when there are accounts.
+	operatorName string
+
+	// Operator accounts and who is logged in; operator is nil when there
+	// are no accounts.
+	operators []pos.Operator
+	operator  *pos.Operator
+
 	// UI widget references
-	orderList        *widget.List
-	totalLabel       *widget.Label
-	customerEntry    *widget.Entry
-	tableEntry       *widget.Entry
-	discountEntry    *widget.Entry
-	paymentRadio     *widget.RadioGroup
-	kitchenCheck     *widget.Check
+	orderList         *widget.List
+	totalLabel        *widget.Label
+	customerEntry     *widget.Entry
+	tableEntry        *widget.Entry
+	discountEntry     *widget.Entry
+	serviceCheck      *widget.Check
+	serviceEntry      *widget.Entry
+	paymentRadio      *widget.RadioGroup
+	kitchenCheck      *widget.Check
 	cashReceivedEntry *widget.Entry
-	changeLabel      *widget.Label
-	cashSection      *fyne.Container
-	statusLabel      *widget.Label
-	menuTabs         *container.AppTabs
+	changeLabel       *widget.Label
+	cashSection       *fyne.Container
+	statusLabel       *widget.Label
+	orderHeader       *widget.Label
+	openOrdersSelect  *widget.Select
+	menuTabs          *container.AppTabs
 }
 
 // NewApp creates and initializes the application.
@@ -58,43 +85,153 @@ func NewApp() *App {
 	}
 	a.menu = menu
 
-	a.order = pos.NewOrder(cfg.NextOrderNumber())
+	a.loadOperators()
+	a.loadOpenOrders()
 
 	a.fyneApp = app.New()
 	a.fyneApp.SetIcon(appIcon)
 	a.mainWindow = a.fyneApp.NewWindow("GoldenSky POS")
 	a.mainWindow.Resize(fyne.NewSize(1280, 768))
 
+	a.loadCashSession()
 	a.connectPrinter()
+	a.connectStations()
+	a.startSpooler()
+	a.startKDS()
 	a.buildLayout()
+	a.showOrder(a.order)
 
 	return a
 }
 
-// Run starts the application event loop.
+// Run starts the application event loop, asking for a login first when
+// there are operator accounts.
 func (a *App) Run() {
+	a.showLoginDialog(false)
 	a.mainWindow.ShowAndRun()
+	a.saveCurrentOrder()
 }
 
+// connectPrinter opens the configured cashier printer. When it cannot be
+// opened, the local and LAN printers are searched in the background so a
+// slow scan does not hold up the window.
 func (a *App) connectPrinter() {
 	p, err := printer.Open(a.config.Printer.DevicePath)
 	if err == nil {
-		a.printer = p
+		p.SetCharsPerLine(a.config.Printer.CharsPerLine)
+		a.setPrinter(p)
 		return
 	}
 	log.Printf("Impressora nao encontrada em %s: %v", a.config.Printer.DevicePath, err)
+	go a.discoverPrinter(a.config.Printer.ScanSubnet, a.config.Printer.CharsPerLine)
+}
 
-	for _, path := range printer.DetectPrinters() {
-		p, err = printer.Open(path)
-		if err == nil {
-			a.printer = p
+// discoverPrinter attaches the first detected printer that opens, unless
+// another one was connected during the scan.
+func (a *App) discoverPrinter(subnet string, charsPerLine int) {
+	for _, path := range printer.DetectPrinters(subnet) {
+		p, err := printer.Open(path)
+		if err != nil {
+			continue
+		}
+		p.SetCharsPerLine(charsPerLine)
+		fyne.Do(func() {
+			if a.printer != nil {
+				p.Close()
+				return
+			}
+			a.setPrinter(p)
 			a.config.Printer.DevicePath = path
 			_ = storage.SaveConfig(a.config)
 			log.Printf("Impressora detectada em %s", path)
-			return
+			a.updatePrinterStatus()
+			a.spooler.Wake()
+		})
+		return
+	}
+	log.Println("Nenhuma impressora detectada (use file:///pasta para uma impressora virtual)")
+}
+
+// connectStations opens every configured production printer. Stations that
+// fail to open are left out and their tickets go to the cashier printer.
+func (a *App) connectStations() {
+	stations := make(map[string]*printer.Printer)
+	for name, pc := range a.config.Printers {
+		if pc.DevicePath == "" {
+			continue
+		}
+		p, err := printer.Open(pc.DevicePath)
+		if err != nil {
+			log.Printf("Impressora %s nao encontrada em %s: %v", name, pc.DevicePath, err)
+			continue
+		}
+		if station, ok := a.config.StationPrinter(name); ok {
+			p.SetCharsPerLine(station.CharsPerLine)
 		}
+		stations[name] = p
+	}
+	a.printerMu.Lock()
+	a.stations = stations
+	a.printerMu.Unlock()
+}
+
+// setPrinter replaces the cashier printer. It is only called on the UI
+// thread, which may read the field directly.
+func (a *App) setPrinter(p *printer.Printer) {
+	a.printerMu.Lock()
+	a.printer = p
+	a.printerMu.Unlock()
+}
+
+// startSpooler loads the persisted print queue and starts draining it.
+func (a *App) startSpooler() {
+	path, err := storage.PrintQueuePath()
+	if err != nil {
+		log.Printf("Aviso: erro ao localizar fila de impressao: %v", err)
+	}
+	spooler, err := printer.NewSpooler(path, a.printerByName)
+	if err != nil {
+		log.Printf("Aviso: erro ao carregar fila de impressao: %v", err)
+	}
+	spooler.OnChange = func(job printer.Job) {
+		fyne.Do(func() {
+			a.updatePrinterStatus()
+			if job.State == printer.JobFailed {
+				dialog.ShowError(fmt.Errorf("falha ao imprimir %s: %s\nReenvie em Opcoes > Fila de Impressao",
+					job.Label, job.LastError), a.mainWindow)
+			}
+		})
+	}
+	a.spooler = spooler
+	a.spooler.Start()
+}
+
+// printerByName resolves a spooler job's printer name to a device. The
+// spooler calls it from its own goroutine.
+func (a *App) printerByName(name string) *printer.Printer {
+	if name == storage.CashierPrinter {
+		return a.cashierPrinter()
 	}
-	log.Println("Nenhuma impressora detectada")
+	return a.stationPrinter(name)
+}
+
+// cashierPrinter returns the cashier printer, safe to call off the UI
+// thread.
+func (a *App) cashierPrinter() *printer.Printer {
+	a.printerMu.RLock()
+	defer a.printerMu.RUnlock()
+	return a.printer
+}
+
+// stationPrinter returns the printer for a production station, falling back
+// to the cashier printer when the station has none connected.
+func (a *App) stationPrinter(name string) *printer.Printer {
+	a.printerMu.RLock()
+	defer a.printerMu.RUnlock()
+	if p := a.stations[name]; p != nil && p.IsConnected() {
+		return p
+	}
+	return a.printer
 }
 
 func (a *App) buildLayout() {
@@ -108,9 +245,9 @@ func (a *App) buildLayout() {
 
 	// Use Border layout: left=menu, right=actions, center=order, bottom=status
 	centerContent := container.NewBorder(
-		nil,       // top
-		statusBar, // bottom
-		leftPanel, // left
+		nil,        // top
+		statusBar,  // bottom
+		leftPanel,  // left
 		rightPanel, // right
 		orderPanel, // center
 	)
@@ -123,10 +260,14 @@ func (a *App) buildLayout() {
 
 func (a *App) buildToolbar() *fyne.MainMenu {
 	configItem := fyne.NewMenuItem("Configuracoes", func() {
-		a.showConfigDialog()
+		if a.requireManager() {
+			a.showConfigDialog()
+		}
 	})
 	menuEditorItem := fyne.NewMenuItem("Editar Cardapio", func() {
-		a.showMenuEditorDialog()
+		if a.requireManager() {
+			a.showMenuEditorDialog()
+		}
 	})
 	historyItem := fyne.NewMenuItem("Historico de Pedidos", func() {
 		a.showOrderHistoryDialog()
@@ -134,34 +275,67 @@ func (a *App) buildToolbar() *fyne.MainMenu {
 	summaryItem := fyne.NewMenuItem("Resumo do Dia", func() {
 		a.showDaySummaryDialog()
 	})
+	tableMapItem := fyne.NewMenuItem("Mapa de Mesas", func() {
+		a.showTableMapDialog()
+	})
+	combosItem := fyne.NewMenuItem("Combos do Pedido", func() {
+		a.showComboSuggestions()
+	})
+	printQueueItem := fyne.NewMenuItem("Fila de Impressao", func() {
+		a.showPrintQueueDialog()
+	})
 	settingsMenu := fyne.NewMenu("Opcoes", configItem, menuEditorItem,
-		fyne.NewMenuItemSeparator(), historyItem, summaryItem)
-	return fyne.NewMainMenu(settingsMenu)
+		fyne.NewMenuItemSeparator(), tableMapItem, combosItem, historyItem, summaryItem,
+		fyne.NewMenuItemSeparator(), printQueueItem)
+
+	cashMenu := fyne.NewMenu("Caixa",
+		fyne.NewMenuItem("Abrir Caixa", a.showOpenCashDialog),
+		fyne.NewMenuItem("Sangria", func() {
+			a.showCashMovementDialog(pos.MovementSangria)
+		}),
+		fyne.NewMenuItem("Suprimento", func() {
+			a.showCashMovementDialog(pos.MovementSuprimento)
+		}),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Leitura X", a.showXReport),
+		fyne.NewMenuItem("Fechar Caixa (Reducao Z)", a.showCloseCashDialog),
+		fyne.NewMenuItem("Reducoes Z", a.showZReportsDialog),
+	)
+
+	userMenu := fyne.NewMenu("Usuario",
+		fyne.NewMenuItem("Trocar Usuario", func() {
+			if len(pos.ActiveOperators(a.operators)) == 0 {
+				dialog.ShowInformation("Usuario", "Nenhum operador cadastrado. Cadastre em Usuario > Operadores.", a.mainWindow)
+				return
+			}
+			a.showLoginDialog(true)
+		}),
+		fyne.NewMenuItem("Operadores", a.showOperatorsDialog),
+	)
+	return fyne.NewMainMenu(settingsMenu, cashMenu, userMenu)
 }
 
+// newOrder starts another order, keeping the current one open.
 func (a *App) newOrder() {
-	a.order = pos.NewOrder(a.config.NextOrderNumber())
-	a.splitPayments = nil
-	a.customerEntry.SetText("")
-	a.tableEntry.SetText("")
-	a.discountEntry.SetText("")
-	a.paymentRadio.SetSelected(string(pos.PaymentDinheiro))
-	a.paymentRadio.Enable()
-	if a.cashReceivedEntry != nil {
-		a.cashReceivedEntry.SetText("")
-	}
-	if a.changeLabel != nil {
-		a.changeLabel.SetText("")
-	}
-	if a.cashSection != nil {
-		a.cashSection.Show()
-	}
-	a.refreshOrderDisplay()
+	a.switchToOrder(a.createOrder())
 }
 
 func (a *App) addItemToOrder(item pos.MenuItem) {
+	if !a.requireEditableOrder() {
+		return
+	}
+	combos := a.comboSuggestionCount()
+	if groups := a.menu.OptionGroupsFor(item); len(groups) > 0 {
+		a.showOptionsDialog(item.Name, groups, func(mods []pos.Modifier) {
+			a.order.AddItemWithModifiers(item, 1, "", mods)
+			a.refreshOrderDisplay()
+			a.offerCombos(combos)
+		})
+		return
+	}
 	a.order.AddItem(item, 1, "")
 	a.refreshOrderDisplay()
+	a.offerCombos(combos)
 }
 
 func (a *App) showEditNotesDialog(index int) {
@@ -190,6 +364,12 @@ func (a *App) showEditNotesDialog(index int) {
 }
 
 func (a *App) refreshOrderDisplay() {
+	if len(a.order.KitchenCancels) > 0 {
+		a.enqueueCancellationTickets(a.order)
+	}
 	a.orderList.Refresh()
 	a.totalLabel.SetText(pos.FormatBRL(a.order.Total()))
+	a.orderHeader.SetText(fmt.Sprintf("Pedido #%d", a.order.Number))
+	a.refreshOpenOrdersSelect()
+	a.saveCurrentOrder()
 }
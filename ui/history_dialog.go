@@ -11,6 +11,7 @@ import (
 	"fyne.io/fyne/v2/widget"
 
 	"notinha/internal/pos"
+	"notinha/internal/printer"
 	"notinha/internal/storage"
 )
 
@@ -49,8 +50,11 @@ func (a *App) showOrderHistoryDialog() {
 			o := orders[id]
 			timeStr := o.ClosedAt.Format("15:04")
 			status := ""
-			if o.Status == pos.StatusCancelado {
+			switch {
+			case o.Status == pos.StatusCancelado:
 				status = " [CANCELADO]"
+			case len(o.Refunds) > 0:
+				status = " [ESTORNO]"
 			}
 			paymentDisplay := string(o.Payment)
 			if o.IsSplitPayment() {
@@ -63,10 +67,60 @@ func (a *App) showOrderHistoryDialog() {
 		},
 	)
 
+	selected := -1
+	refundBtn := widget.NewButton("Estornar Itens...", nil)
+	cancelBtn := widget.NewButton("Cancelar Venda...", nil)
+	receiptBtn := widget.NewButton("Reimprimir Recibo", nil)
+	ticketBtn := widget.NewButton("Reimprimir Comanda", nil)
+	updateActions := func() {
+		if selected >= 0 && selected < len(orders) {
+			receiptBtn.Enable()
+			ticketBtn.Enable()
+		} else {
+			receiptBtn.Disable()
+			ticketBtn.Disable()
+		}
+		if selected >= 0 && selected < len(orders) && orders[selected].Status == pos.StatusFinalizado {
+			refundBtn.Enable()
+			cancelBtn.Enable()
+			return
+		}
+		refundBtn.Disable()
+		cancelBtn.Disable()
+	}
+	refund := func(cancel bool) {
+		if selected < 0 || selected >= len(orders) {
+			return
+		}
+		o := &orders[selected]
+		a.showRefundDialog(o, cancel, func() {
+			detailLabel.SetText(formatOrderDetail(o, a.operators))
+			orderList.Refresh()
+			updateActions()
+		})
+	}
+	reprint := func(kind pos.ReprintKind) {
+		if selected < 0 || selected >= len(orders) {
+			return
+		}
+		o := &orders[selected]
+		if err := a.reprintOrder(o, kind); err != nil {
+			dialog.ShowError(err, a.mainWindow)
+			return
+		}
+		detailLabel.SetText(formatOrderDetail(o, a.operators))
+	}
+	receiptBtn.OnTapped = func() { reprint(pos.ReprintReceipt) }
+	ticketBtn.OnTapped = func() { reprint(pos.ReprintKitchen) }
+	refundBtn.OnTapped = func() { refund(false) }
+	cancelBtn.OnTapped = func() { refund(true) }
+
 	orderList.OnSelected = func(id widget.ListItemID) {
+		selected = id
 		if id < len(orders) {
-			detailLabel.SetText(formatOrderDetail(&orders[id]))
+			detailLabel.SetText(formatOrderDetail(&orders[id], a.operators))
 		}
+		updateActions()
 	}
 
 	loadOrders := func(isoDate string) {
@@ -80,6 +134,8 @@ func (a *App) showOrderHistoryDialog() {
 		detailLabel.SetText("Selecione um pedido.")
 		orderList.UnselectAll()
 		orderList.Refresh()
+		selected = -1
+		updateActions()
 	}
 
 	dateSelect := widget.NewSelect(displayDates, func(selected string) {
@@ -94,7 +150,8 @@ func (a *App) showOrderHistoryDialog() {
 	loadOrders(dates[0])
 
 	leftPanel := container.NewBorder(dateSelect, nil, nil, nil, orderList)
-	content := container.NewHSplit(leftPanel, detailScroll)
+	rightPanel := container.NewBorder(nil, container.NewGridWithColumns(2, receiptBtn, ticketBtn, refundBtn, cancelBtn), nil, nil, detailScroll)
+	content := container.NewHSplit(leftPanel, rightPanel)
 	content.SetOffset(0.4)
 
 	d := dialog.NewCustom("Historico de Pedidos", "Fechar", content, a.mainWindow)
@@ -102,7 +159,7 @@ func (a *App) showOrderHistoryDialog() {
 	d.Show()
 }
 
-func formatOrderDetail(o *pos.Order) string {
+func formatOrderDetail(o *pos.Order, ops []pos.Operator) string {
 	var b strings.Builder
 
 	fmt.Fprintf(&b, "Pedido #%d\n", o.Number)
@@ -117,6 +174,12 @@ func formatOrderDetail(o *pos.Order) string {
 	if o.Table != "" {
 		fmt.Fprintf(&b, "Mesa: %s\n", o.Table)
 	}
+	if name := pos.OperatorName(ops, o.OperatorID); name != "" {
+		fmt.Fprintf(&b, "Atendido por: %s\n", name)
+	}
+	if name := pos.OperatorName(ops, o.ClosedByID); name != "" {
+		fmt.Fprintf(&b, "Fechado por: %s\n", name)
+	}
 	if o.IsSplitPayment() {
 		b.WriteString("Pagamentos:\n")
 		for _, p := range o.Payments {
@@ -137,7 +200,11 @@ func formatOrderDetail(o *pos.Order) string {
 	b.WriteString("\n")
 	fmt.Fprintf(&b, "Subtotal: %s\n", pos.FormatBRL(o.Subtotal()))
 	if o.Discount > 0 {
-		fmt.Fprintf(&b, "Desconto: -%s\n", pos.FormatBRL(o.Discount))
+		fmt.Fprintf(&b, "Desconto: -%s", pos.FormatBRL(o.Discount))
+		if name := pos.OperatorName(ops, o.DiscountByID); name != "" {
+			fmt.Fprintf(&b, " (%s)", name)
+		}
+		b.WriteString("\n")
 	}
 	fmt.Fprintf(&b, "Total: %s\n", pos.FormatBRL(o.Total()))
 
@@ -146,5 +213,61 @@ func formatOrderDetail(o *pos.Order) string {
 		fmt.Fprintf(&b, "Troco: %s\n", pos.FormatBRL(o.CashChange()))
 	}
 
+	if len(o.Refunds) > 0 {
+		b.WriteString("\n--- Estornos ---\n")
+		for _, r := range o.Refunds {
+			fmt.Fprintf(&b, "%s  %s em %s\n", r.Time.Format("02/01 15:04"), pos.FormatBRL(r.Amount), r.Method)
+			for _, ri := range r.Items {
+				fmt.Fprintf(&b, "   %dx %s\n", ri.Quantity, o.Items[ri.Line].Item.Name)
+			}
+			fmt.Fprintf(&b, "   Motivo: %s\n", r.Reason)
+			if name := pos.OperatorName(ops, r.OperatorID); name != "" {
+				fmt.Fprintf(&b, "   Feito por: %s\n", name)
+			}
+			if r.AuthorizedBy != "" {
+				fmt.Fprintf(&b, "   Autorizado por: %s\n", r.AuthorizedBy)
+			}
+		}
+		fmt.Fprintf(&b, "Valor liquido: %s\n", pos.FormatBRL(o.NetTotal()))
+	}
+
+	if len(o.Reprints) > 0 {
+		b.WriteString("\n--- Reimpressoes ---\n")
+		for _, r := range o.Reprints {
+			fmt.Fprintf(&b, "%s  %s", r.Time.Format("02/01 15:04"), r.Kind)
+			if name := pos.OperatorName(ops, r.OperatorID); name != "" {
+				fmt.Fprintf(&b, " (%s)", name)
+			}
+			b.WriteString("\n")
+		}
+	}
+
 	return b.String()
 }
+
+// reprintOrder prints a 2a via of a stored order's receipt or production
+// tickets, recording the reprint on the order first so every copy can be
+// audited.
+func (a *App) reprintOrder(o *pos.Order, kind pos.ReprintKind) error {
+	updated := *o
+	updated.Reprints = append([]pos.Reprint(nil), o.Reprints...)
+	r := updated.RecordReprint(kind, a.operatorID())
+	if err := storage.UpdateOrder(&updated); err != nil {
+		log.Printf("Erro ao registrar reimpressao do pedido #%d: %v", o.Number, err)
+		return fmt.Errorf("erro ao registrar reimpressao: %w", err)
+	}
+	*o = updated
+
+	data := printer.ReceiptData{
+		Restaurant:   a.config.Restaurant,
+		Order:        o,
+		CharsPerLine: a.config.Printer.CharsPerLine,
+		Reprint:      r.Time,
+	}
+	if kind == pos.ReprintKitchen {
+		a.enqueueProductionTickets(data, o.Items, printer.BuildStationTicket, "2a via comanda")
+		return nil
+	}
+	a.spooler.Enqueue(storage.CashierPrinter, fmt.Sprintf("2a via recibo #%d", o.Number), printer.BuildReceipt(data))
+	return nil
+}
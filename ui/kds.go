@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"fyne.io/fyne/v2"
+
+	"notinha/internal/kds"
+	"notinha/internal/pos"
+	"notinha/internal/printer"
+)
+
+// startKDS serves the kitchen display when an address is configured and
+// puts back the items the open orders are still waiting for.
+func (a *App) startKDS() {
+	if a.config.KDSAddress == "" {
+		return
+	}
+	server := kds.NewServer()
+	server.OnReady = func(r kds.Ready) {
+		fyne.Do(func() { a.kitchenReady(r) })
+	}
+	if err := server.Start(a.config.KDSAddress); err != nil {
+		log.Printf("Aviso: %v", err)
+		return
+	}
+	log.Printf("Tela da cozinha em %s", strings.Join(server.URLs(), ", "))
+	a.kds = server
+	for _, o := range a.openOrders {
+		a.sendToDisplay(o, kds.KindNew, o.AwaitingKitchen())
+	}
+}
+
+// sendToDisplay shows items sent to the stations on the kitchen display,
+// grouped like the printed tickets.
+func (a *App) sendToDisplay(o *pos.Order, kind string, items []pos.OrderItem) {
+	if a.kds == nil {
+		return
+	}
+	for _, group := range printer.GroupByStation(items, a.config.RouteFor) {
+		a.kds.Send(o.Number, o.Label(), group.Station, kind, group.Items)
+	}
+}
+
+// kitchenReady records items bumped on the kitchen display and tells the
+// waiter they can be served.
+func (a *App) kitchenReady(r kds.Ready) {
+	for _, o := range a.openOrders {
+		if o.Number != r.Order {
+			continue
+		}
+		if o.MarkReady(r.Item, r.Item.Quantity) > 0 {
+			if o == a.order {
+				a.refreshOrderDisplay()
+			} else {
+				a.persistOpenOrder(o)
+			}
+		}
+		break
+	}
+	a.fyneApp.SendNotification(fyne.NewNotification("Pedido pronto",
+		fmt.Sprintf("%s: %dx %s (%s)", r.Label, r.Item.Quantity, r.Item.Item.Name, r.Station)))
+}
@@ -32,15 +32,22 @@ func (a *App) refreshMenuTabs() {
 
 	for _, category := range categories {
 		items := a.menu.ItemsByCategory(category)
-		grid := a.buildCategoryGrid(items)
+		grid := a.buildCategoryGrid(category, items)
 		tab := container.NewTabItem(category, grid)
 		a.menuTabs.Append(tab)
 	}
 	a.menuTabs.Refresh()
 }
 
-func (a *App) buildCategoryGrid(items []pos.MenuItem) fyne.CanvasObject {
+func (a *App) buildCategoryGrid(category string, items []pos.MenuItem) fyne.CanvasObject {
 	var buttons []fyne.CanvasObject
+	if len(items) > 0 && a.config.IsPizzaCategory(category) {
+		btn := widget.NewButton("Varios Sabores\n(meio a meio)", func() {
+			a.showPizzaDialog(category)
+		})
+		btn.Importance = widget.HighImportance
+		buttons = append(buttons, btn)
+	}
 	for _, item := range items {
 		item := item // capture loop variable
 		label := item.Name + "\n" + pos.FormatBRL(item.Price)
@@ -0,0 +1,149 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+
+	"fyne.io/fyne/v2/dialog"
+
+	"notinha/internal/pos"
+	"notinha/internal/storage"
+)
+
+// createOrder starts an order taken by the current operator, with the
+// next number and the configured service charge.
+func (a *App) createOrder() *pos.Order {
+	o := pos.NewOrder(a.config.NextOrderNumber())
+	o.OperatorID = a.operatorID()
+	o.ServicePercent = a.config.ServiceCharge
+	o.PriceRules = a.menu.PriceRules
+	return o
+}
+
+// loadOpenOrders restores the orders left open by a previous run and makes
+// the oldest one current, starting a fresh order when there are none.
+func (a *App) loadOpenOrders() {
+	orders, err := storage.LoadOpenOrders()
+	if err != nil {
+		log.Printf("Aviso: erro ao carregar pedidos abertos: %v", err)
+	}
+	for i := range orders {
+		orders[i].PriceRules = a.menu.PriceRules
+		a.openOrders = append(a.openOrders, &orders[i])
+	}
+	if len(a.openOrders) == 0 {
+		a.openOrders = append(a.openOrders, a.createOrder())
+	}
+	a.order = a.openOrders[0]
+}
+
+// saveCurrentOrder copies the entries into the current order and persists
+// it, so open tabs survive a restart. Empty orders are not kept.
+func (a *App) saveCurrentOrder() {
+	a.storeOrderInputs(a.order)
+	var err error
+	if a.order.IsEmpty() {
+		err = storage.DeleteOpenOrder(a.order.Number)
+	} else {
+		err = storage.SaveOpenOrder(a.order)
+	}
+	if err != nil {
+		log.Printf("Erro ao salvar pedido aberto #%d: %v", a.order.Number, err)
+	}
+}
+
+// switchToOrder makes o the order being edited, keeping the previous one
+// open unless it is empty.
+func (a *App) switchToOrder(o *pos.Order) {
+	if o == a.order {
+		return
+	}
+	a.saveCurrentOrder()
+	if a.order.IsEmpty() {
+		a.removeOpenOrder(a.order)
+	}
+	if !a.isOpenOrder(o) {
+		a.openOrders = append(a.openOrders, o)
+	}
+	a.showOrder(o)
+}
+
+// finishCurrentOrder drops the finalized (or discarded) current order and
+// moves on to the most recent remaining open order, or a new one.
+func (a *App) finishCurrentOrder() {
+	a.removeOpenOrder(a.order)
+	if err := storage.DeleteOpenOrder(a.order.Number); err != nil {
+		log.Printf("Erro ao remover pedido aberto #%d: %v", a.order.Number, err)
+	}
+	if len(a.openOrders) == 0 {
+		a.openOrders = append(a.openOrders, a.createOrder())
+	}
+	a.showOrder(a.openOrders[len(a.openOrders)-1])
+}
+
+func (a *App) showOrder(o *pos.Order) {
+	a.order = o
+	a.splitPayments = nil
+	a.loadOrderInputs(o)
+	a.refreshOrderDisplay()
+}
+
+func (a *App) isOpenOrder(o *pos.Order) bool {
+	for _, open := range a.openOrders {
+		if open == o {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *App) removeOpenOrder(o *pos.Order) {
+	for i, open := range a.openOrders {
+		if open == o {
+			a.openOrders = append(a.openOrders[:i], a.openOrders[i+1:]...)
+			return
+		}
+	}
+}
+
+// refreshOpenOrdersSelect lists the open orders by table/customer with the
+// current one selected.
+func (a *App) refreshOpenOrdersSelect() {
+	if a.openOrdersSelect == nil {
+		return
+	}
+	labels := make([]string, len(a.openOrders))
+	for i, o := range a.openOrders {
+		labels[i] = o.Label()
+	}
+	a.openOrdersSelect.SetOptions(labels)
+	a.openOrdersSelect.SetSelected(a.order.Label())
+}
+
+func (a *App) onOpenOrderSelected(label string) {
+	for _, o := range a.openOrders {
+		if o.Label() == label {
+			a.switchToOrder(o)
+			return
+		}
+	}
+}
+
+// confirmDiscardOrder drops the current open order without saving it to
+// the history.
+func (a *App) confirmDiscardOrder() {
+	if a.order.IsEmpty() {
+		return
+	}
+	msg := fmt.Sprintf("Descartar %s com %d item(ns)?", a.order.Label(), len(a.order.Items))
+	dialog.ShowConfirm("Descartar Pedido", msg, func(ok bool) {
+		if !ok {
+			return
+		}
+		a.order.CancelKitchen()
+		if len(a.order.KitchenCancels) > 0 {
+			a.enqueueCancellationTickets(a.order)
+		}
+		a.finishCurrentOrder()
+	}, a.mainWindow)
+}
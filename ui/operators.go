@@ -0,0 +1,294 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"notinha/internal/pos"
+	"notinha/internal/storage"
+)
+
+// loadOperators reads the operator accounts. Without accounts nobody logs
+// in and every feature stays available, as before. An accounts file that
+// exists but cannot be read stops the app: running without a login would
+// give everyone manager rights.
+func (a *App) loadOperators() {
+	ops, err := storage.LoadOperators()
+	if err != nil {
+		log.Fatalf("Erro ao carregar operadores: %v (corrija ou restaure operators.json)", err)
+	}
+	a.operators = ops
+}
+
+// operatorID is the logged in operator, or 0 without accounts.
+func (a *App) operatorID() int {
+	if a.operator == nil {
+		return 0
+	}
+	return a.operator.ID
+}
+
+// requireOperator reports whether the logged in operator passes allowed,
+// telling them otherwise. Everything is allowed without accounts.
+func (a *App) requireOperator(allowed func(pos.Operator) bool, who string) bool {
+	if a.operator == nil || allowed(*a.operator) {
+		return true
+	}
+	dialog.ShowInformation("Acesso restrito",
+		fmt.Sprintf("%s nao tem permissao para esta operacao.\nPermitido para: %s.", a.operator.Name, who),
+		a.mainWindow)
+	return false
+}
+
+func (a *App) requireCharge() bool {
+	return a.requireOperator(pos.Operator.CanCharge, "Caixa e Gerente")
+}
+
+func (a *App) requireManager() bool {
+	return a.requireOperator(pos.Operator.IsManager, "Gerente")
+}
+
+// setOperator logs op in. Empty open orders, such as the one started at
+// launch, are taken by op.
+func (a *App) setOperator(op pos.Operator) {
+	a.operator = &op
+	a.operatorName = op.Name
+	for _, o := range a.openOrders {
+		if o.IsEmpty() {
+			o.OperatorID = op.ID
+		}
+	}
+	a.updatePrinterStatus()
+}
+
+// showLoginDialog asks for an operator and PIN. At launch it cannot be
+// dismissed; when switching users cancelable keeps the current one.
+func (a *App) showLoginDialog(cancelable bool) {
+	active := pos.ActiveOperators(a.operators)
+	if len(active) == 0 {
+		return
+	}
+	names := make([]string, len(active))
+	for i, op := range active {
+		names[i] = op.Name
+	}
+	nameSelect := widget.NewSelect(names, nil)
+	if a.operator != nil {
+		nameSelect.SetSelected(a.operator.Name)
+	}
+	pinEntry := widget.NewPasswordEntry()
+	errorLabel := widget.NewLabel("")
+
+	form := widget.NewForm(
+		widget.NewFormItem("Operador", nameSelect),
+		widget.NewFormItem("PIN", pinEntry),
+	)
+	form.SubmitText = "Entrar"
+	d := dialog.NewCustomWithoutButtons("Entrar", container.NewVBox(form, errorLabel), a.mainWindow)
+	form.OnSubmit = func() {
+		op, err := pos.Authenticate(active, nameSelect.Selected, pinEntry.Text)
+		if err != nil {
+			pinEntry.SetText("")
+			errorLabel.SetText(err.Error())
+			return
+		}
+		d.Hide()
+		a.setOperator(op)
+	}
+	if cancelable {
+		form.CancelText = "Cancelar"
+		form.OnCancel = d.Hide
+	}
+	pinEntry.OnSubmitted = func(string) { form.OnSubmit() }
+	d.Resize(fyne.NewSize(360, 200))
+	d.Show()
+	a.mainWindow.Canvas().Focus(pinEntry)
+}
+
+// showOperatorsDialog manages the operator accounts. The first account
+// must be a manager; accounts are deactivated rather than deleted so
+// their history keeps a name.
+func (a *App) showOperatorsDialog() {
+	if !a.requireManager() {
+		return
+	}
+	ops := append([]pos.Operator(nil), a.operators...)
+
+	selected := -1
+	var list *widget.List
+	list = widget.NewList(
+		func() int { return len(ops) },
+		func() fyne.CanvasObject { return widget.NewLabel("Operador - Gerente (inativo)") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			op := ops[id]
+			text := op.Name + " - " + op.Role.Label()
+			if !op.Active {
+				text += " (inativo)"
+			}
+			obj.(*widget.Label).SetText(text)
+		},
+	)
+
+	save := func(updated []pos.Operator) {
+		if err := pos.ValidateOperators(updated); err != nil {
+			dialog.ShowError(err, a.mainWindow)
+			return
+		}
+		if err := storage.SaveOperators(updated); err != nil {
+			log.Printf("Erro ao salvar operadores: %v", err)
+			dialog.ShowError(fmt.Errorf("erro ao salvar operadores: %w", err), a.mainWindow)
+			return
+		}
+		ops = updated
+		a.operators = append([]pos.Operator(nil), updated...)
+		a.afterOperatorsChanged()
+		list.Refresh()
+	}
+
+	editBtn := widget.NewButton("Editar...", func() {
+		if selected < 0 || selected >= len(ops) {
+			return
+		}
+		i := selected
+		a.showOperatorForm(ops[i], func(op pos.Operator) {
+			updated := append([]pos.Operator(nil), ops...)
+			updated[i] = op
+			save(updated)
+		})
+	})
+	toggleBtn := widget.NewButton("Ativar/Desativar", func() {
+		if selected < 0 || selected >= len(ops) {
+			return
+		}
+		updated := append([]pos.Operator(nil), ops...)
+		updated[selected].Active = !updated[selected].Active
+		save(updated)
+	})
+	addBtn := widget.NewButton("Novo...", func() {
+		op := pos.Operator{ID: pos.NextOperatorID(ops), Role: pos.RoleCaixa, Active: true}
+		if len(pos.ActiveOperators(ops)) == 0 {
+			op.Role = pos.RoleGerente
+		}
+		a.showOperatorForm(op, func(op pos.Operator) {
+			save(append(append([]pos.Operator(nil), ops...), op))
+		})
+	})
+	list.OnSelected = func(id widget.ListItemID) { selected = id }
+
+	help := widget.NewLabel("Com operadores cadastrados, o sistema pede login ao abrir.\n" +
+		"O primeiro operador deve ser Gerente.")
+	buttons := container.NewGridWithColumns(3, addBtn, editBtn, toggleBtn)
+	d := dialog.NewCustom("Operadores", "Fechar", container.NewBorder(help, buttons, nil, nil, list), a.mainWindow)
+	d.Resize(fyne.NewSize(480, 420))
+	d.Show()
+}
+
+// showOperatorForm edits the name, role and PIN of op, then hands it to
+// save. The PIN of an existing account may be left blank to keep it.
+func (a *App) showOperatorForm(op pos.Operator, save func(pos.Operator)) {
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText(op.Name)
+	labels := make([]string, 0, len(pos.Roles()))
+	for _, r := range pos.Roles() {
+		labels = append(labels, r.Label())
+	}
+	roleSelect := widget.NewSelect(labels, nil)
+	roleSelect.SetSelected(op.Role.Label())
+	pinEntry := widget.NewPasswordEntry()
+	if op.PINHash != "" {
+		pinEntry.SetPlaceHolder("Em branco mantem o atual")
+	}
+	confirmEntry := widget.NewPasswordEntry()
+
+	title := "Novo Operador"
+	if op.Name != "" {
+		title = "Editar " + op.Name
+	}
+	items := []*widget.FormItem{
+		widget.NewFormItem("Nome", nameEntry),
+		widget.NewFormItem("Funcao", roleSelect),
+		widget.NewFormItem("PIN", pinEntry),
+		widget.NewFormItem("Confirmar PIN", confirmEntry),
+	}
+	dialog.ShowForm(title, "Salvar", "Cancelar", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		edited := op
+		role, _ := pos.RoleFromLabel(roleSelect.Selected)
+		var err error
+		switch {
+		case pinEntry.Text != confirmEntry.Text:
+			err = errors.New("os PINs nao conferem")
+		case op.PINHash == "":
+			edited, err = pos.NewOperator(op.ID, nameEntry.Text, role, pinEntry.Text)
+		default:
+			edited.Name, edited.Role = nameEntry.Text, role
+			if pinEntry.Text != "" {
+				err = edited.SetPIN(pinEntry.Text)
+			}
+		}
+		if err != nil {
+			dialog.ShowError(err, a.mainWindow)
+			return
+		}
+		save(edited)
+	}, a.mainWindow)
+}
+
+// afterOperatorsChanged keeps the session consistent with the saved
+// accounts: the first account logs in, and an operator who was edited or
+// deactivated is refreshed or logged out.
+func (a *App) afterOperatorsChanged() {
+	if a.operator == nil {
+		if active := pos.ActiveOperators(a.operators); len(active) > 0 {
+			a.setOperator(active[0])
+		}
+		return
+	}
+	op, ok := pos.FindOperator(a.operators, a.operator.ID)
+	if ok && op.Active {
+		a.setOperator(op)
+		return
+	}
+	a.operator = nil
+	a.updatePrinterStatus()
+	a.showLoginDialog(false)
+}
+
+// refundPINNeeded reports whether a refund needs a manager PIN: from any
+// operator but a manager, or without accounts when the settings have one.
+func (a *App) refundPINNeeded() bool {
+	if a.operator != nil {
+		return !a.operator.IsManager()
+	}
+	return a.config.ManagerPIN != ""
+}
+
+// authorizeRefund returns who authorizes a refund: the manager logged in,
+// the manager whose PIN was typed, or "Gerente" for the PIN in the
+// settings when there are no accounts.
+func (a *App) authorizeRefund(pin string) (string, error) {
+	switch {
+	case !a.refundPINNeeded():
+		if a.operator != nil {
+			return a.operator.Name, nil
+		}
+		return "", nil
+	case a.operator != nil:
+		manager, err := pos.AuthorizeManager(a.operators, pin)
+		if err != nil {
+			return "", err
+		}
+		return manager.Name, nil
+	case pos.CheckPIN(a.config.ManagerPIN, pin):
+		return "Gerente", nil
+	}
+	return "", errors.New("PIN do gerente incorreto")
+}
@@ -0,0 +1,169 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"notinha/internal/pos"
+	"notinha/internal/storage"
+)
+
+// showOptionsDialog asks for the options of an item (size, border,
+// extras) and passes the chosen modifiers to onChosen.
+func (a *App) showOptionsDialog(title string, groups []pos.OptionGroup, onChosen func([]pos.Modifier)) {
+	// Each widget reports the option names picked in its group.
+	pickers := make([]func() []string, len(groups))
+	content := container.NewVBox()
+	for i, g := range groups {
+		labels := make([]string, len(g.Options))
+		names := make(map[string]string, len(g.Options))
+		for j, opt := range g.Options {
+			labels[j] = optionLabel(opt)
+			names[labels[j]] = opt.Name
+		}
+
+		content.Add(widget.NewLabelWithStyle(optionGroupTitle(g), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+		if g.Max == 1 {
+			radio := widget.NewRadioGroup(labels, nil)
+			radio.Required = g.Required()
+			if g.Required() && len(labels) == 1 {
+				radio.SetSelected(labels[0])
+			}
+			content.Add(radio)
+			pickers[i] = func() []string {
+				if radio.Selected == "" {
+					return nil
+				}
+				return []string{names[radio.Selected]}
+			}
+		} else {
+			checks := widget.NewCheckGroup(labels, nil)
+			content.Add(checks)
+			pickers[i] = func() []string {
+				var picked []string
+				for _, label := range checks.Selected {
+					picked = append(picked, names[label])
+				}
+				return picked
+			}
+		}
+	}
+
+	d := dialog.NewCustomConfirm(title, "Adicionar", "Cancelar", container.NewVScroll(content), func(ok bool) {
+		if !ok {
+			return
+		}
+		picked := make(map[string][]string, len(groups))
+		for i, g := range groups {
+			picked[g.Name] = pickers[i]()
+		}
+		mods, err := pos.Choose(groups, picked)
+		if err != nil {
+			dialog.ShowError(err, a.mainWindow)
+			return
+		}
+		onChosen(mods)
+	}, a.mainWindow)
+	d.Resize(fyne.NewSize(420, 500))
+	d.Show()
+}
+
+func optionLabel(opt pos.Option) string {
+	switch {
+	case opt.Price > 0:
+		return fmt.Sprintf("%s (+%s)", opt.Name, pos.FormatBRL(opt.Price))
+	case opt.Price < 0:
+		return fmt.Sprintf("%s (-%s)", opt.Name, pos.FormatBRL(-opt.Price))
+	}
+	return opt.Name
+}
+
+func optionGroupTitle(g pos.OptionGroup) string {
+	var rule string
+	switch {
+	case g.Max == 1 && g.Required():
+		rule = "obrigatorio"
+	case g.Max == 1:
+		rule = "opcional"
+	case g.Max == 0 && g.Min == 0:
+		rule = "opcional, quantos quiser"
+	case g.Max == 0:
+		rule = fmt.Sprintf("escolha pelo menos %d", g.Min)
+	case g.Min == 0:
+		rule = fmt.Sprintf("opcional, ate %d", g.Max)
+	default:
+		rule = fmt.Sprintf("escolha de %d a %d", g.Min, g.Max)
+	}
+	return fmt.Sprintf("%s (%s)", g.Name, rule)
+}
+
+// formatItemDetails joins an order item's combo components, modifiers and
+// notes for the order panel.
+func formatItemDetails(oi pos.OrderItem) string {
+	var parts []string
+	for _, c := range oi.Components {
+		parts = append(parts, fmt.Sprintf("%dx %s", c.Quantity, c.Item.Name))
+	}
+	for _, m := range oi.Modifiers {
+		parts = append(parts, m.String())
+	}
+	if oi.Sent > 0 {
+		parts = append(parts, fmt.Sprintf("na cozinha: %d", oi.Sent))
+	}
+	if oi.Ready > 0 {
+		parts = append(parts, fmt.Sprintf("pronto: %d", oi.Ready))
+	}
+	if oi.PriceRule != "" {
+		parts = append(parts, fmt.Sprintf("%s (de %s)", oi.PriceRule, pos.FormatBRL(oi.RegularPrice)))
+	}
+	if oi.Notes != "" {
+		parts = append(parts, oi.Notes)
+	}
+	return strings.Join(parts, " | ")
+}
+
+// showOptionGroupsDialog edits the menu's option groups, one per line in
+// the form "Borda (0-1): Catupiry +8,00; Cheddar +8,00".
+func (a *App) showOptionGroupsDialog() {
+	lines := make([]string, len(a.menu.OptionGroups))
+	for i, g := range a.menu.OptionGroups {
+		lines[i] = g.String()
+	}
+	entry := widget.NewMultiLineEntry()
+	entry.SetText(strings.Join(lines, "\n"))
+	entry.SetPlaceHolder("Tamanho (1): Media; Grande +15,00\nBorda (0-1): Catupiry +8,00; Cheddar +8,00\nExtras (0-): Bacon +5,00; Sem cebola")
+	entry.SetMinRowsVisible(10)
+
+	help := widget.NewLabel("Um grupo por linha: Nome (min-max): opcao +preco; opcao\n" +
+		"(1) = obrigatorio, uma escolha; (0-1) = opcional; (0-) = sem limite")
+
+	d := dialog.NewCustomConfirm("Grupos de Opcoes", "Salvar", "Cancelar",
+		container.NewBorder(help, nil, nil, nil, entry), func(ok bool) {
+			if !ok {
+				return
+			}
+			var groups []pos.OptionGroup
+			for _, line := range strings.Split(entry.Text, "\n") {
+				if strings.TrimSpace(line) == "" {
+					continue
+				}
+				g, err := pos.ParseOptionGroup(line)
+				if err != nil {
+					dialog.ShowError(err, a.mainWindow)
+					return
+				}
+				groups = append(groups, g)
+			}
+			a.menu.OptionGroups = groups
+			if err := storage.SaveMenu(a.menu); err != nil {
+				dialog.ShowError(fmt.Errorf("erro ao salvar cardapio: %w", err), a.mainWindow)
+			}
+		}, a.mainWindow)
+	d.Resize(fyne.NewSize(650, 450))
+	d.Show()
+}
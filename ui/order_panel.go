@@ -66,8 +66,8 @@ func (a *App) buildOrderPanel() fyne.CanvasObject {
 			nameLabel.SetText(fmt.Sprintf("%dx %s", orderItem.Quantity, orderItem.Item.Name))
 			priceLabel.SetText(pos.FormatBRL(orderItem.Total()))
 
-			if orderItem.Notes != "" {
-				notesLabel.SetText("* " + orderItem.Notes)
+			if details := formatItemDetails(orderItem); details != "" {
+				notesLabel.SetText("* " + details)
 				notesLabel.Show()
 			} else {
 				notesLabel.SetText("")
@@ -76,37 +76,63 @@ func (a *App) buildOrderPanel() fyne.CanvasObject {
 
 			idx := id
 			plusBtn.OnTapped = func() {
-				if idx < len(a.order.Items) {
-					a.order.UpdateQuantity(idx, a.order.Items[idx].Quantity+1)
+				if idx < len(a.order.Items) && a.requireEditableOrder() {
+					a.order.IncrementItem(idx)
 					a.refreshOrderDisplay()
 				}
 			}
 			minusBtn.OnTapped = func() {
-				if idx < len(a.order.Items) {
+				if idx < len(a.order.Items) && a.requireEditableOrder() {
 					a.order.UpdateQuantity(idx, a.order.Items[idx].Quantity-1)
 					a.refreshOrderDisplay()
 				}
 			}
 			removeBtn.OnTapped = func() {
-				if idx < len(a.order.Items) {
+				if idx < len(a.order.Items) && a.requireEditableOrder() {
 					a.order.RemoveItem(idx)
 					a.refreshOrderDisplay()
 				}
 			}
+			// A combo has no notes of its own; the button separates it.
+			if orderItem.IsCombo() {
+				editNotesBtn.SetText("Separar")
+			} else {
+				editNotesBtn.SetText("Obs")
+			}
 			editNotesBtn.OnTapped = func() {
-				if idx < len(a.order.Items) {
-					a.showEditNotesDialog(idx)
+				if idx >= len(a.order.Items) {
+					return
+				}
+				if a.order.Items[idx].IsCombo() {
+					if a.requireEditableOrder() {
+						a.breakCombo(idx)
+					}
+					return
 				}
+				a.showEditNotesDialog(idx)
 			}
 		},
 	)
 
-	header := widget.NewLabelWithStyle(
+	a.orderHeader = widget.NewLabelWithStyle(
 		fmt.Sprintf("Pedido #%d", a.order.Number),
 		fyne.TextAlignCenter,
 		fyne.TextStyle{Bold: true},
 	)
 
+	// Open orders: switch between tables/customers being served
+	a.openOrdersSelect = widget.NewSelect(nil, a.onOpenOrderSelected)
+	a.openOrdersSelect.PlaceHolder = "Pedidos abertos"
+	discardBtn := widget.NewButton("Descartar", func() {
+		a.confirmDiscardOrder()
+	})
+	openOrdersRow := container.NewBorder(nil, nil,
+		widget.NewLabel("Abertos:"),
+		discardBtn,
+		a.openOrdersSelect,
+	)
+	header := container.NewVBox(openOrdersRow, a.orderHeader)
+
 	totalRow := container.New(
 		layout.NewHBoxLayout(),
 		layout.NewSpacer(),
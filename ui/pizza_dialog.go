@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"notinha/internal/pos"
+)
+
+// pizzaFlavors lists the items that can be combined into a pizza of
+// several flavors: those of category first, then the other pizza
+// categories.
+func (a *App) pizzaFlavors(category string) []pos.MenuItem {
+	items := a.menu.ItemsByCategory(category)
+	for _, c := range a.menu.Categories() {
+		if c != category && a.config.IsPizzaCategory(c) {
+			items = append(items, a.menu.ItemsByCategory(c)...)
+		}
+	}
+	return items
+}
+
+// showPizzaDialog builds a pizza of 2 to 4 flavors in equal fractions,
+// priced by the configured rule. The options of the first flavor (size,
+// border) are asked next.
+func (a *App) showPizzaDialog(category string) {
+	if !a.requireEditableOrder() {
+		return
+	}
+	items := a.pizzaFlavors(category)
+	labels := make([]string, len(items))
+	for i, item := range items {
+		labels[i] = item.Name + " - " + pos.FormatBRL(item.Price)
+	}
+	rule := a.config.Pizza.Pricing
+
+	var selects []*widget.Select
+	priceLabel := widget.NewLabel("")
+	chosen := func() []pos.Flavor {
+		var flavors []pos.Flavor
+		for _, sel := range selects {
+			if i := sel.SelectedIndex(); i >= 0 {
+				flavors = append(flavors, pos.Flavor{Item: items[i], Parts: 1})
+			}
+		}
+		return flavors
+	}
+	updatePrice := func() {
+		flavors := chosen()
+		if len(flavors) < len(selects) {
+			priceLabel.SetText("Escolha os sabores")
+			return
+		}
+		priceLabel.SetText(pos.FormatBRL(pos.PriceFlavors(flavors, rule)) + " (" + rule.Label() + ")")
+	}
+
+	flavorsBox := container.NewVBox()
+	setCount := func(n int) {
+		kept := selects
+		selects = make([]*widget.Select, n)
+		flavorsBox.RemoveAll()
+		for i := range selects {
+			selects[i] = widget.NewSelect(labels, func(string) { updatePrice() })
+			selects[i].PlaceHolder = "Sabor " + strconv.Itoa(i+1)
+			if i < len(kept) && kept[i].SelectedIndex() >= 0 {
+				selects[i].SetSelectedIndex(kept[i].SelectedIndex())
+			}
+			flavorsBox.Add(selects[i])
+		}
+		updatePrice()
+	}
+
+	var counts []string
+	for n := pos.MinFlavors; n <= pos.MaxFlavors; n++ {
+		counts = append(counts, strconv.Itoa(n))
+	}
+	countRadio := widget.NewRadioGroup(counts, func(selected string) {
+		if n, err := strconv.Atoi(selected); err == nil {
+			setCount(n)
+		}
+	})
+	countRadio.Horizontal = true
+	countRadio.Required = true
+	countRadio.SetSelected(counts[0])
+
+	content := container.NewVBox(
+		widget.NewLabel("Sabores"),
+		countRadio,
+		flavorsBox,
+		widget.NewSeparator(),
+		priceLabel,
+	)
+	d := dialog.NewCustomConfirm("Pizza Meio a Meio", "Continuar", "Cancelar", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		flavors := chosen()
+		if len(flavors) < len(selects) {
+			dialog.ShowInformation("Aviso", "Escolha todos os sabores.", a.mainWindow)
+			return
+		}
+		combos := a.comboSuggestionCount()
+		add := func(mods []pos.Modifier) {
+			if err := a.order.AddPizza(flavors, rule, 1, "", mods); err != nil {
+				dialog.ShowError(err, a.mainWindow)
+				return
+			}
+			a.refreshOrderDisplay()
+			a.offerCombos(combos)
+		}
+		if groups := a.menu.OptionGroupsFor(flavors[0].Item); len(groups) > 0 {
+			a.showOptionsDialog("Pizza Meio a Meio", groups, add)
+			return
+		}
+		add(nil)
+	}, a.mainWindow)
+	d.Resize(fyne.NewSize(420, 0))
+	d.Show()
+}
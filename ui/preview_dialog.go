@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"notinha/internal/printer"
+)
+
+// showPreviewDialog renders ESC/POS bytes as they would come out of the
+// printer, as an image and as plain text. When onPrint is set the dialog
+// offers an "Imprimir" button that calls it.
+func (a *App) showPreviewDialog(title string, data []byte, charsPerLine int, onPrint func()) {
+	preview := printer.ParsePreview(data, charsPerLine)
+
+	textLabel := widget.NewLabel(preview.Text())
+	textLabel.TextStyle = fyne.TextStyle{Monospace: true}
+	var items []*container.TabItem
+	if img, err := preview.Image(); err == nil {
+		paper := canvas.NewImageFromImage(img)
+		paper.FillMode = canvas.ImageFillOriginal
+		paper.ScaleMode = canvas.ImageScalePixels
+		items = append(items, container.NewTabItem("Cupom", container.NewScroll(paper)))
+	} else {
+		log.Printf("Erro ao renderizar pre-visualizacao: %v", err)
+	}
+	items = append(items, container.NewTabItem("Texto", container.NewScroll(textLabel)))
+	tabs := container.NewAppTabs(items...)
+
+	var d dialog.Dialog
+	if onPrint != nil {
+		d = dialog.NewCustomConfirm(title, "Imprimir", "Fechar", tabs, func(ok bool) {
+			if ok {
+				onPrint()
+			}
+		}, a.mainWindow)
+	} else {
+		d = dialog.NewCustom(title, "Fechar", tabs, a.mainWindow)
+	}
+	d.Resize(fyne.NewSize(680, 650))
+	d.Show()
+}
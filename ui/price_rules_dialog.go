@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"notinha/internal/pos"
+	"notinha/internal/storage"
+)
+
+// showPriceRulesEditor edits the scheduled prices, one rule per line in
+// the form "Happy Hour | seg-sex | 17:00-19:00 | Chopp | 25%".
+func (a *App) showPriceRulesEditor() {
+	lines := make([]string, len(a.menu.PriceRules))
+	for i, r := range a.menu.PriceRules {
+		lines[i] = a.menu.FormatPriceRule(r)
+	}
+	entry := widget.NewMultiLineEntry()
+	entry.SetText(strings.Join(lines, "\n"))
+	entry.SetPlaceHolder("Happy Hour | seg-sex | 17:00-19:00 | Chopp | 25%\nDomingo | dom | 11:00-15:00 | Pizza Calabresa | 39,90")
+	entry.SetMinRowsVisible(10)
+
+	help := widget.NewLabel("Uma regra por linha: nome | dias | horario | item ou categoria | preco ou %\n" +
+		"Dias: seg-sex, sab,dom ou todos. Vale para itens adicionados dentro do horario.")
+
+	d := dialog.NewCustomConfirm("Precos Programados", "Salvar", "Cancelar",
+		container.NewBorder(help, nil, nil, nil, entry), func(ok bool) {
+			if !ok {
+				return
+			}
+			var rules []pos.PriceRule
+			for _, line := range strings.Split(entry.Text, "\n") {
+				if strings.TrimSpace(line) == "" {
+					continue
+				}
+				r, err := a.menu.ParsePriceRule(line)
+				if err != nil {
+					dialog.ShowError(err, a.mainWindow)
+					return
+				}
+				rules = append(rules, r)
+			}
+			a.menu.PriceRules = rules
+			if err := storage.SaveMenu(a.menu); err != nil {
+				dialog.ShowError(fmt.Errorf("erro ao salvar cardapio: %w", err), a.mainWindow)
+			}
+			for _, o := range a.openOrders {
+				o.PriceRules = rules
+			}
+		}, a.mainWindow)
+	d.Resize(fyne.NewSize(700, 450))
+	d.Show()
+}
@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"notinha/internal/printer"
+)
+
+func (a *App) showPrintQueueDialog() {
+	jobs := a.spooler.Jobs()
+	selected := -1
+
+	detailLabel := widget.NewLabel("Selecione um trabalho.")
+	detailLabel.Wrapping = fyne.TextWrapWord
+
+	jobList := widget.NewList(
+		func() int { return len(jobs) },
+		func() fyne.CanvasObject {
+			return widget.NewLabel("00:00  Comanda cozinha #000  [Pendente]")
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id >= len(jobs) {
+				return
+			}
+			j := jobs[id]
+			obj.(*widget.Label).SetText(
+				fmt.Sprintf("%s  %s  [%s]", j.CreatedAt.Format("15:04"), j.Label, j.State),
+			)
+		},
+	)
+
+	jobList.OnSelected = func(id widget.ListItemID) {
+		selected = id
+		if id < len(jobs) {
+			detailLabel.SetText(formatJobDetail(jobs[id]))
+		}
+	}
+
+	refresh := func() {
+		jobs = a.spooler.Jobs()
+		selected = -1
+		jobList.UnselectAll()
+		jobList.Refresh()
+		detailLabel.SetText("Selecione um trabalho.")
+		a.updatePrinterStatus()
+	}
+
+	resendBtn := widget.NewButton("Reenviar", func() {
+		if selected < 0 || selected >= len(jobs) {
+			return
+		}
+		if err := a.spooler.Retry(jobs[selected].ID); err != nil {
+			dialog.ShowError(err, a.mainWindow)
+		}
+		refresh()
+	})
+	discardBtn := widget.NewButton("Descartar", func() {
+		if selected < 0 || selected >= len(jobs) {
+			return
+		}
+		job := jobs[selected]
+		dialog.ShowConfirm("Descartar",
+			fmt.Sprintf("Descartar %s? Ele nao sera impresso.", job.Label),
+			func(ok bool) {
+				if ok {
+					a.spooler.Discard(job.ID)
+					refresh()
+				}
+			}, a.mainWindow)
+	})
+	previewBtn := widget.NewButton("Visualizar", func() {
+		if selected < 0 || selected >= len(jobs) {
+			return
+		}
+		job := jobs[selected]
+		width := a.config.Printer.CharsPerLine
+		if pc, ok := a.config.StationPrinter(job.Printer); ok {
+			width = pc.CharsPerLine
+		}
+		a.showPreviewDialog(job.Label, job.Data, width, nil)
+	})
+	refreshBtn := widget.NewButton("Atualizar", refresh)
+
+	buttons := container.NewHBox(previewBtn, resendBtn, discardBtn, refreshBtn)
+	content := container.NewHSplit(jobList, container.NewVScroll(detailLabel))
+	content.SetOffset(0.55)
+
+	d := dialog.NewCustom("Fila de Impressao", "Fechar",
+		container.NewBorder(nil, buttons, nil, nil, content), a.mainWindow)
+	d.Resize(fyne.NewSize(750, 450))
+	d.Show()
+}
+
+func formatJobDetail(j printer.Job) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s\n", j.Label)
+	fmt.Fprintf(&b, "Impressora: %s\n", j.Printer)
+	fmt.Fprintf(&b, "Status: %s\n", j.State)
+	fmt.Fprintf(&b, "Criado: %s\n", j.CreatedAt.Format("02/01/2006 15:04:05"))
+	fmt.Fprintf(&b, "Atualizado: %s\n", j.UpdatedAt.Format("02/01/2006 15:04:05"))
+	fmt.Fprintf(&b, "Tentativas: %d\n", j.Attempts)
+	if j.State == printer.JobPending && !j.NextAttempt.IsZero() {
+		fmt.Fprintf(&b, "Proxima tentativa: %s\n", j.NextAttempt.Format("15:04:05"))
+	}
+	if j.LastError != "" {
+		fmt.Fprintf(&b, "\nUltimo erro:\n%s\n", j.LastError)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,167 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"notinha/internal/pos"
+	"notinha/internal/printer"
+	"notinha/internal/storage"
+)
+
+// showRefundDialog gives back units of a paid order from the history, or
+// the whole order when cancel is set. The reason is mandatory and a
+// manager PIN is asked unless a manager is logged in. done runs after the
+// saved order was updated.
+func (a *App) showRefundDialog(o *pos.Order, cancel bool, done func()) {
+	if o.Status != pos.StatusFinalizado {
+		dialog.ShowInformation("Estorno", "Somente pedidos finalizados podem ser estornados.", a.mainWindow)
+		return
+	}
+
+	remaining := o.RemainingItems()
+	amountLabel := widget.NewLabel("")
+	chosen := func() []pos.RefundItem { return remaining }
+	itemsBox := container.NewVBox()
+	if !cancel {
+		selects := make([]*widget.Select, len(remaining))
+		chosen = func() []pos.RefundItem {
+			var items []pos.RefundItem
+			for i, sel := range selects {
+				if n, _ := strconv.Atoi(sel.Selected); n > 0 {
+					items = append(items, pos.RefundItem{Line: remaining[i].Line, Quantity: n})
+				}
+			}
+			return items
+		}
+		for i, ri := range remaining {
+			options := make([]string, ri.Quantity+1)
+			for n := range options {
+				options[n] = strconv.Itoa(n)
+			}
+			selects[i] = widget.NewSelect(options, func(string) {
+				amount, _ := o.RefundAmount(chosen())
+				amountLabel.SetText("Valor a estornar: " + pos.FormatBRL(amount))
+			})
+			selects[i].SetSelected("0")
+			itemsBox.Add(container.NewBorder(nil, nil, nil, selects[i],
+				widget.NewLabel(fmt.Sprintf("%dx %s", ri.Quantity, o.Items[ri.Line].Item.Name))))
+		}
+	}
+	amount, _ := o.RefundAmount(chosen())
+	amountLabel.SetText("Valor a estornar: " + pos.FormatBRL(amount))
+
+	methodSelect := widget.NewSelect(pos.PaymentMethodLabels(), nil)
+	methodSelect.SetSelected(string(o.Payment))
+	reasonEntry := widget.NewEntry()
+	reasonEntry.SetPlaceHolder("Motivo (obrigatorio)")
+	pinEntry := widget.NewPasswordEntry()
+	pinEntry.SetPlaceHolder("PIN do gerente")
+
+	form := widget.NewForm(
+		widget.NewFormItem("Devolver em", methodSelect),
+		widget.NewFormItem("Motivo", reasonEntry),
+	)
+	if a.refundPINNeeded() {
+		form.Append("PIN", pinEntry)
+	}
+	content := container.NewVBox(itemsBox, amountLabel, form)
+
+	title, confirm := fmt.Sprintf("Estornar Pedido #%d", o.Number), "Estornar"
+	if cancel {
+		title, confirm = fmt.Sprintf("Cancelar Venda #%d", o.Number), "Cancelar Venda"
+	}
+	d := dialog.NewCustomConfirm(title, confirm, "Voltar", container.NewVScroll(content), func(ok bool) {
+		if !ok {
+			return
+		}
+		authorizedBy, err := a.authorizeRefund(pinEntry.Text)
+		if err != nil {
+			dialog.ShowError(err, a.mainWindow)
+			return
+		}
+		r := pos.Refund{
+			Items:        chosen(),
+			Method:       pos.PaymentMethod(methodSelect.Selected),
+			Reason:       reasonEntry.Text,
+			AuthorizedBy: authorizedBy,
+			OperatorID:   a.operatorID(),
+		}
+		if err := a.refundOrder(o, r, cancel); err != nil {
+			dialog.ShowError(err, a.mainWindow)
+			return
+		}
+		done()
+	}, a.mainWindow)
+	d.Resize(fyne.NewSize(460, 480))
+	d.Show()
+}
+
+// refundOrder applies the refund, pays it out of the open register when
+// the sale belongs to an earlier one, saves the order in place and prints
+// the voucher.
+func (a *App) refundOrder(o *pos.Order, r pos.Refund, cancel bool) error {
+	sessionOpen := a.session != nil && a.session.IsOpen()
+	if r.Method == pos.PaymentDinheiro && !sessionOpen {
+		return errors.New("abra o caixa para estornar em dinheiro")
+	}
+	if sessionOpen {
+		r.SessionID = a.session.ID
+	}
+
+	updated := *o
+	updated.Refunds = append([]pos.Refund(nil), o.Refunds...)
+	var err error
+	if cancel {
+		r, err = updated.CancelPaid(r)
+	} else {
+		r, err = updated.Refund(r)
+	}
+	if err != nil {
+		return err
+	}
+
+	sangria := a.session.PaysOutRefund(&updated, r)
+	var movements int
+	if sangria {
+		movements = len(a.session.Movements)
+		orders, err := storage.LoadSessionOrders(a.session)
+		if err != nil {
+			return fmt.Errorf("erro ao carregar pedidos do caixa: %w", err)
+		}
+		reason := fmt.Sprintf("Estorno pedido #%d", updated.Number)
+		if err := a.addCashMovement(pos.MovementSangria, r.Amount, reason, orders); err != nil {
+			return err
+		}
+	}
+
+	if err := storage.UpdateOrder(&updated); err != nil {
+		log.Printf("Erro ao salvar estorno do pedido #%d: %v", updated.Number, err)
+		if sangria {
+			a.session.Movements = a.session.Movements[:movements]
+		}
+		return fmt.Errorf("erro ao salvar estorno: %w", err)
+	}
+	if sangria {
+		if err := storage.SaveCashSession(a.session); err != nil {
+			log.Printf("Erro ao salvar caixa: %v", err)
+			dialog.ShowError(fmt.Errorf("erro ao salvar caixa: %w", err), a.mainWindow)
+		}
+	}
+	*o = updated
+
+	a.spooler.Enqueue(storage.CashierPrinter, fmt.Sprintf("Estorno #%d", o.Number),
+		printer.BuildRefundReceipt(printer.ReceiptData{
+			Restaurant:   a.config.Restaurant,
+			Order:        o,
+			CharsPerLine: a.config.Printer.CharsPerLine,
+		}, r))
+	return nil
+}
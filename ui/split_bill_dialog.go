@@ -0,0 +1,283 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"notinha/internal/pos"
+	"notinha/internal/printer"
+	"notinha/internal/storage"
+)
+
+const (
+	splitEvenly  = "Igual para todos"
+	splitByItems = "Por itens"
+)
+
+// requireEditableOrder reports whether the items, discount and service
+// charge of the current order may change, telling the operator otherwise.
+// Once a sub-bill is paid they are fixed: the bills left would no longer
+// add up to the total and the order could never be closed.
+func (a *App) requireEditableOrder() bool {
+	if !a.order.HasPaidSubBills() {
+		return true
+	}
+	dialog.ShowInformation("Conta Dividida",
+		"Este pedido tem contas pagas e nao pode mais ser alterado.\nPague as contas restantes em Dividir Conta.", a.mainWindow)
+	return false
+}
+
+// lockPaidOrderInputs disables the discount and service charge entries
+// while the current order has paid sub-bills.
+func (a *App) lockPaidOrderInputs() {
+	for _, w := range []fyne.Disableable{a.discountEntry, a.serviceCheck, a.serviceEntry} {
+		if a.order.HasPaidSubBills() {
+			w.Disable()
+		} else {
+			w.Enable()
+		}
+	}
+}
+
+// showSplitBillDialog divides the current order among several people, or
+// shows the sub-bills of an order already divided.
+func (a *App) showSplitBillDialog() {
+	if len(a.order.Items) == 0 {
+		dialog.ShowInformation("Aviso", "Adicione itens ao pedido.", a.mainWindow)
+		return
+	}
+	a.storeOrderInputs(a.order)
+	if len(a.order.SubBills) > 0 {
+		if !a.order.SplitOutdated() || a.order.HasPaidSubBills() {
+			a.showSubBillsDialog()
+			return
+		}
+		// Items changed before anyone paid: divide again.
+		_ = a.order.ClearSplit()
+	}
+
+	modeRadio := widget.NewRadioGroup([]string{splitEvenly, splitByItems}, nil)
+	modeRadio.SetSelected(splitEvenly)
+	peopleEntry := widget.NewEntry()
+	peopleEntry.SetText("2")
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Total", widget.NewLabel(pos.FormatBRL(a.order.Total()))),
+		widget.NewFormItem("Pessoas", peopleEntry),
+		widget.NewFormItem("Divisao", modeRadio),
+	}
+	dialog.ShowForm("Dividir Conta", "Continuar", "Cancelar", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(peopleEntry.Text))
+		if err != nil || n < 2 {
+			dialog.ShowInformation("Aviso", "Informe pelo menos 2 pessoas.", a.mainWindow)
+			return
+		}
+		if modeRadio.Selected == splitByItems {
+			a.showItemSplitDialog(n)
+			return
+		}
+		if err := a.order.SplitEvenly(n); err != nil {
+			dialog.ShowError(err, a.mainWindow)
+			return
+		}
+		a.saveCurrentOrder()
+		a.showSubBillsDialog()
+	}, a.mainWindow)
+}
+
+// showItemSplitDialog asks how much of each line every person takes:
+// units when they add up to the line quantity, or shares of the line
+// otherwise, 1 for each person for an even split.
+func (a *App) showItemSplitDialog(n int) {
+	entries := make([][]*widget.Entry, len(a.order.Items))
+	rows := container.NewVBox(widget.NewLabel("Informe as unidades de cada pessoa (ex.: 2 e 1 de 3 chopps)\n" +
+		"ou partes do item (ex.: 1 para cada pessoa que divide a pizza)"))
+	for i, oi := range a.order.Items {
+		entries[i] = make([]*widget.Entry, n)
+		cells := container.NewGridWithColumns(min(n, 6))
+		for p := range entries[i] {
+			e := widget.NewEntry()
+			e.SetPlaceHolder("0")
+			entries[i][p] = e
+			cells.Add(container.NewBorder(nil, nil, widget.NewLabel(fmt.Sprintf("%d:", p+1)), nil, e))
+		}
+		rows.Add(widget.NewLabel(fmt.Sprintf("%dx %s - %s", oi.Quantity, oi.Item.Name, pos.FormatBRL(oi.Total()))))
+		rows.Add(cells)
+	}
+
+	d := dialog.NewCustomConfirm("Quem paga cada item?", "Dividir", "Cancelar",
+		container.NewVScroll(rows), func(ok bool) {
+			if !ok {
+				return
+			}
+			shares := make([][]int, len(entries))
+			for i, line := range entries {
+				shares[i] = make([]int, n)
+				for p, e := range line {
+					text := strings.TrimSpace(e.Text)
+					if text == "" {
+						continue
+					}
+					v, err := strconv.Atoi(text)
+					if err != nil || v < 0 {
+						dialog.ShowError(fmt.Errorf("quantidade invalida para %s, pessoa %d: %q",
+							a.order.Items[i].Item.Name, p+1, text), a.mainWindow)
+						return
+					}
+					shares[i][p] = v
+				}
+			}
+			if err := a.order.SplitByItems(n, shares); err != nil {
+				dialog.ShowError(err, a.mainWindow)
+				return
+			}
+			a.saveCurrentOrder()
+			a.showSubBillsDialog()
+		}, a.mainWindow)
+	d.Resize(fyne.NewSize(700, 500))
+	d.Show()
+}
+
+// showSubBillsDialog lists the sub-bills with their state; each is paid
+// separately and prints its own partial receipt.
+func (a *App) showSubBillsDialog() {
+	var d *dialog.CustomDialog
+	content := container.NewVBox()
+
+	var refresh func()
+	refresh = func() {
+		content.RemoveAll()
+		if a.order.SplitOutdated() {
+			content.Add(widget.NewLabel("Pedido alterado depois da divisao: os valores nao conferem."))
+		}
+		for i, b := range a.order.SubBills {
+			idx := i
+			status := "Pendente"
+			if b.IsPaid() {
+				status = "Pago - " + formatPayments(b.Payments)
+			}
+			payBtn := widget.NewButton("Pagar", func() {
+				a.showPaySubBillDialog(idx, refresh)
+			})
+			if b.IsPaid() {
+				payBtn.Disable()
+			}
+			label := widget.NewLabel(fmt.Sprintf("Conta %d: %s (%s)", b.Number, pos.FormatBRL(b.Amount), status))
+			content.Add(container.NewBorder(nil, nil, nil, payBtn, label))
+		}
+		if !a.order.HasPaidSubBills() {
+			content.Add(widget.NewSeparator())
+			content.Add(widget.NewButton("Desfazer Divisao", func() {
+				_ = a.order.ClearSplit()
+				a.saveCurrentOrder()
+				d.Hide()
+			}))
+		}
+		if a.order.AllSubBillsPaid() {
+			d.Hide()
+			a.completeSplitOrder()
+		}
+	}
+
+	d = dialog.NewCustom(fmt.Sprintf("Conta dividida - Pedido #%d", a.order.Number), "Fechar", content, a.mainWindow)
+	refresh()
+	d.Resize(fyne.NewSize(500, 0))
+	d.Show()
+}
+
+func (a *App) showPaySubBillDialog(index int, onPaid func()) {
+	if !a.requireCharge() || !a.requireCashSession() {
+		return
+	}
+	bill := a.order.SubBills[index]
+
+	cashEntry := widget.NewEntry()
+	cashEntry.SetPlaceHolder("Valor recebido (R$)")
+	methodRadio := widget.NewRadioGroup(pos.PaymentMethodLabels(), func(selected string) {
+		if selected == string(pos.PaymentDinheiro) {
+			cashEntry.Enable()
+		} else {
+			cashEntry.Disable()
+		}
+	})
+	methodRadio.SetSelected(string(pos.PaymentDinheiro))
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Valor", widget.NewLabel(pos.FormatBRL(bill.Amount))),
+		widget.NewFormItem("Pagamento", methodRadio),
+		widget.NewFormItem("Recebido", cashEntry),
+	}
+	dialog.ShowForm(fmt.Sprintf("Pagar Conta %d/%d", bill.Number, len(a.order.SubBills)), "Pagar", "Cancelar", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		method := pos.PaymentMethod(methodRadio.Selected)
+		var received int64
+		if method == pos.PaymentDinheiro {
+			received, _ = parseCurrencyInput(cashEntry.Text)
+		}
+		payments := []pos.PaymentSplit{{Method: method, Amount: bill.Amount}}
+		if err := a.order.PaySubBill(index, payments, received); err != nil {
+			dialog.ShowError(err, a.mainWindow)
+			return
+		}
+		a.saveCurrentOrder()
+		a.lockPaidOrderInputs()
+
+		paid := a.order.SubBills[index]
+		data := printer.ReceiptData{
+			Restaurant:   a.config.Restaurant,
+			Order:        a.order,
+			CharsPerLine: a.config.Printer.CharsPerLine,
+		}
+		a.spooler.Enqueue(storage.CashierPrinter,
+			fmt.Sprintf("Conta %d/%d #%d", paid.Number, len(a.order.SubBills), a.order.Number),
+			printer.BuildSubBillReceipt(data, paid))
+		if change := paid.CashChange(); change > 0 {
+			dialog.ShowInformation("Troco", fmt.Sprintf("Troco da conta %d: %s", paid.Number, pos.FormatBRL(change)), a.mainWindow)
+		}
+		onPaid()
+	}, a.mainWindow)
+}
+
+// completeSplitOrder finalizes an order once all its sub-bills are paid.
+// The partial receipts were already printed, so no full receipt follows.
+func (a *App) completeSplitOrder() {
+	if err := a.order.FinalizeSubBills(); err != nil {
+		dialog.ShowError(err, a.mainWindow)
+		return
+	}
+	pending, err := a.saveFinalizedOrder()
+	if err != nil {
+		dialog.ShowError(err, a.mainWindow)
+		return
+	}
+	if a.config.KitchenTicket {
+		a.enqueueStationTickets(printer.ReceiptData{
+			Restaurant:   a.config.Restaurant,
+			Order:        pending,
+			CharsPerLine: a.config.Printer.CharsPerLine,
+		})
+	}
+	dialog.ShowInformation("Pedido Finalizado",
+		fmt.Sprintf("Pedido #%d finalizado.\nTodas as %d contas foram pagas.", a.order.Number, len(a.order.SubBills)),
+		a.mainWindow)
+	a.finishCurrentOrder()
+}
+
+func formatPayments(payments []pos.PaymentSplit) string {
+	var parts []string
+	for _, p := range payments {
+		parts = append(parts, string(p.Method))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -1,10 +1,17 @@
 package ui
 
 import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/widget"
+
+	"notinha/internal/printer"
 )
 
 func (a *App) buildStatusBar() fyne.CanvasObject {
@@ -15,6 +22,8 @@ func (a *App) buildStatusBar() fyne.CanvasObject {
 		a.reconnectPrinter()
 	})
 
+	go a.pollPrinterStatus()
+
 	bar := container.New(
 		layout.NewHBoxLayout(),
 		a.statusLabel,
@@ -25,9 +34,78 @@ func (a *App) buildStatusBar() fyne.CanvasObject {
 }
 
 func (a *App) updatePrinterStatus() {
+	var text string
 	if a.printer != nil && a.printer.IsConnected() {
-		a.statusLabel.SetText("Impressora: Conectada (" + a.printer.Path() + ")")
+		text = "Impressora: Conectada (" + a.printer.Path() + ")"
+		if a.printer.IsVirtual() {
+			text = "Impressora: Virtual (" + a.printer.Path() + ")"
+		}
+		if a.printerState != "" {
+			text += " - " + a.printerState
+		}
 	} else {
-		a.statusLabel.SetText("Impressora: Desconectada")
+		text = "Impressora: Desconectada"
+	}
+
+	names := make([]string, 0, len(a.config.Printers))
+	for name := range a.config.Printers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if p := a.stations[name]; p != nil && p.IsConnected() {
+			text += " | " + name + ": Conectada"
+		} else {
+			text += " | " + name + ": Desconectada"
+		}
+	}
+	if a.operator != nil {
+		text += fmt.Sprintf(" | Operador: %s (%s)", a.operator.Name, a.operator.Role.Label())
+	}
+	if a.session != nil && a.session.IsOpen() {
+		text += fmt.Sprintf(" | Caixa #%d aberto", a.session.ID)
+	} else {
+		text += " | Caixa fechado"
+	}
+	if a.kds != nil {
+		if urls := a.kds.URLs(); len(urls) > 0 {
+			text += " | Tela da cozinha: " + urls[0]
+		}
+	}
+	if a.spooler != nil {
+		if n := a.spooler.Pending(); n > 0 {
+			text += fmt.Sprintf(" | Fila: %d pendente(s)", n)
+		}
+	}
+	a.statusLabel.SetText(text)
+}
+
+// printerStatusInterval is how often the status bar asks the printer for
+// paper, cover and drawer state.
+const printerStatusInterval = 5 * time.Second
+
+// pollPrinterStatus queries the cashier printer's real-time status in the
+// background and refreshes the status bar with the result. The printer can
+// be swapped by a reconnect meanwhile, so it is read under printerMu.
+func (a *App) pollPrinterStatus() {
+	ticker := time.NewTicker(printerStatusInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		state := ""
+		if p := a.cashierPrinter(); p != nil && p.IsConnected() {
+			st, err := p.Status()
+			switch {
+			case err == nil:
+				state = st.String()
+			case !errors.Is(err, printer.ErrStatusUnsupported):
+				state = "sem resposta"
+			}
+		}
+		fyne.Do(func() {
+			if state != a.printerState {
+				a.printerState = state
+				a.updatePrinterStatus()
+			}
+		})
 	}
 }
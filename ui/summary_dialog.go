@@ -16,7 +16,7 @@ import (
 )
 
 func (a *App) showDaySummaryDialog() {
-	dates, err := storage.ListOrderDates()
+	dates, err := storage.ListBusinessDates(a.config.BusinessDayCutoff)
 	if err != nil {
 		log.Printf("Erro ao listar datas: %v", err)
 		dialog.ShowError(fmt.Errorf("erro ao carregar historico: %w", err), a.mainWindow)
@@ -45,15 +45,23 @@ func (a *App) showDaySummaryDialog() {
 		a.printDaySummary(currentDate)
 	})
 
+	previewBtn := widget.NewButton("Visualizar", func() {
+		if currentDate == "" {
+			return
+		}
+		a.previewDaySummary(currentDate)
+	})
+
 	loadSummary := func(isoDate string) {
 		currentDate = isoDate
-		orders, err := storage.LoadDayOrders(isoDate)
+		orders, err := storage.LoadBusinessDayOrders(isoDate, a.config.BusinessDayCutoff)
 		if err != nil {
 			log.Printf("Erro ao carregar pedidos: %v", err)
 			summaryLabel.SetText("Erro ao carregar dados.")
 			return
 		}
 		s := pos.ComputeDaySummary(isoDate, orders)
+		s.NameOperators(a.operators)
 		summaryLabel.SetText(formatSummaryText(s))
 	}
 
@@ -68,7 +76,7 @@ func (a *App) showDaySummaryDialog() {
 	dateSelect.SetSelectedIndex(0)
 	loadSummary(dates[0])
 
-	content := container.NewBorder(dateSelect, printBtn, nil, nil, summaryScroll)
+	content := container.NewBorder(dateSelect, container.NewGridWithColumns(2, previewBtn, printBtn), nil, nil, summaryScroll)
 
 	d := dialog.NewCustom("Resumo do Dia", "Fechar", content, a.mainWindow)
 	d.Resize(fyne.NewSize(500, 400))
@@ -85,52 +93,88 @@ func formatSummaryText(s pos.DaySummary) string {
 
 	b.WriteString("\n--- Receita ---\n")
 	fmt.Fprintf(&b, "Total: %s\n", pos.FormatBRL(s.TotalRevenue))
+	if s.Refunds > 0 {
+		fmt.Fprintf(&b, "Estornos (%d): -%s\n", s.Refunds, pos.FormatBRL(s.RefundTotal))
+	}
 	fmt.Fprintf(&b, "Ticket medio: %s\n", pos.FormatBRL(s.AverageTicket))
+	if s.ServiceTotal > 0 {
+		fmt.Fprintf(&b, "Taxa de servico: %s\n", pos.FormatBRL(s.ServiceTotal))
+	}
 
 	b.WriteString("\n--- Por Forma de Pagamento ---\n")
 	for _, pm := range []pos.PaymentMethod{pos.PaymentDinheiro, pos.PaymentCartao, pos.PaymentPix} {
 		revenue := s.ByPayment[pm]
 		count := s.OrdersByPayment[pm]
-		if count > 0 {
+		if count > 0 || revenue != 0 {
 			fmt.Fprintf(&b, "%s: %d pedidos - %s\n", pm, count, pos.FormatBRL(revenue))
 		}
 	}
 
+	if len(s.ByOperator) > 0 {
+		b.WriteString("\n--- Por Operador ---\n")
+		for _, op := range s.ByOperator {
+			fmt.Fprintf(&b, "%s: %d pedidos - %s\n", op.Name, op.Orders, pos.FormatBRL(op.Revenue))
+			if op.Discounts > 0 {
+				fmt.Fprintf(&b, "   Descontos: -%s\n", pos.FormatBRL(op.Discounts))
+			}
+			if op.Cancelled > 0 {
+				fmt.Fprintf(&b, "   Cancelamentos: %d\n", op.Cancelled)
+			}
+			if op.Refunds > 0 {
+				fmt.Fprintf(&b, "   Estornos (%d): -%s\n", op.Refunds, pos.FormatBRL(op.RefundTotal))
+			}
+		}
+	}
+
+	if len(s.Items) > 0 {
+		b.WriteString("\n--- Itens Vendidos ---\n")
+		for _, is := range s.Items {
+			fmt.Fprintf(&b, "%dx %s - %s\n", is.Quantity, is.Name, pos.FormatBRL(is.Revenue))
+		}
+	}
+
 	return b.String()
 }
 
-func (a *App) printDaySummary(isoDate string) {
-	if !a.requirePrinterConnected() {
-		return
+func (a *App) daySummaryReceipt(isoDate string) ([]byte, error) {
+	orders, err := storage.LoadBusinessDayOrders(isoDate, a.config.BusinessDayCutoff)
+	if err != nil {
+		log.Printf("Erro ao carregar pedidos para impressao: %v", err)
+		return nil, fmt.Errorf("erro ao carregar pedidos: %w", err)
 	}
 
-	go func() {
-		orders, err := storage.LoadDayOrders(isoDate)
-		if err != nil {
-			log.Printf("Erro ao carregar pedidos para impressao: %v", err)
-			fyne.Do(func() {
-				dialog.ShowError(fmt.Errorf("erro ao carregar pedidos: %w", err), a.mainWindow)
-			})
-			return
-		}
+	summary := pos.ComputeDaySummary(isoDate, orders)
+	summary.NameOperators(a.operators)
+	data := printer.SummaryReceiptData{
+		Restaurant:   a.config.Restaurant,
+		Summary:      summary,
+		CharsPerLine: a.config.Printer.CharsPerLine,
+	}
+	return printer.BuildSummaryReceipt(data), nil
+}
 
-		summary := pos.ComputeDaySummary(isoDate, orders)
-		data := printer.SummaryReceiptData{
-			Restaurant:   a.config.Restaurant,
-			Summary:      summary,
-			CharsPerLine: a.config.Printer.CharsPerLine,
-		}
+func (a *App) previewDaySummary(isoDate string) {
+	receipt, err := a.daySummaryReceipt(isoDate)
+	if err != nil {
+		dialog.ShowError(err, a.mainWindow)
+		return
+	}
+	a.showPreviewDialog("Resumo "+pos.FormatDateBR(isoDate), receipt, a.config.Printer.CharsPerLine, func() {
+		a.printDaySummary(isoDate)
+	})
+}
 
-		receipt := printer.BuildSummaryReceipt(data)
-		if err := a.printer.Write(receipt); err != nil {
-			log.Printf("Erro ao imprimir resumo: %v", err)
-			fyne.Do(func() {
-				dialog.ShowError(fmt.Errorf("erro ao imprimir: %w", err), a.mainWindow)
-			})
-			return
-		}
-		fyne.Do(func() {
-			dialog.ShowInformation("Sucesso", "Resumo impresso!", a.mainWindow)
-		})
-	}()
+func (a *App) printDaySummary(isoDate string) {
+	receipt, err := a.daySummaryReceipt(isoDate)
+	if err != nil {
+		dialog.ShowError(err, a.mainWindow)
+		return
+	}
+
+	a.spooler.Enqueue(storage.CashierPrinter, "Resumo "+pos.FormatDateBR(isoDate), receipt)
+	if a.printer != nil && a.printer.IsConnected() {
+		dialog.ShowInformation("Sucesso", "Resumo enviado para impressao.", a.mainWindow)
+	} else {
+		dialog.ShowInformation("Aviso", "Impressora nao conectada. Resumo mantido na fila de impressao.", a.mainWindow)
+	}
 }
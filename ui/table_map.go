@@ -0,0 +1,264 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"notinha/internal/pos"
+	"notinha/internal/storage"
+)
+
+// tableMapInterval is how often the open table map updates elapsed times.
+const tableMapInterval = 30 * time.Second
+
+// showTableMapDialog shows the floor layout by area with each table's
+// status, time occupied and running total. Tapping a table opens, moves or
+// joins its orders.
+func (a *App) showTableMapDialog() {
+	if len(a.config.Tables) == 0 {
+		dialog.ShowInformation("Mesas",
+			"Nenhuma mesa cadastrada. Configure as mesas em Opcoes > Configuracoes.", a.mainWindow)
+		return
+	}
+	a.saveCurrentOrder()
+
+	var d *dialog.CustomDialog
+	tabs := container.NewAppTabs()
+	var refresh func()
+	refresh = func() {
+		states := pos.TableStates(a.config.Tables, a.openOrders)
+		selected := tabs.SelectedIndex()
+		var items []*container.TabItem
+		for _, area := range pos.TableAreas(a.config.Tables) {
+			grid := container.NewGridWrap(fyne.NewSize(150, 120))
+			for _, s := range states {
+				if s.Table.Area != area {
+					continue
+				}
+				state := s
+				grid.Add(tableCell(state, func() {
+					a.showTableActions(state, d.Hide, refresh)
+				}))
+			}
+			label := area
+			if label == "" {
+				label = "Mesas"
+			}
+			items = append(items, container.NewTabItem(label, container.NewVScroll(grid)))
+		}
+		tabs.SetItems(items)
+		if selected > 0 && selected < len(items) {
+			tabs.SelectIndex(selected)
+		}
+	}
+	refresh()
+
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(tableMapInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				fyne.Do(refresh)
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	d = dialog.NewCustom("Mapa de Mesas", "Fechar", tabs, a.mainWindow)
+	d.SetOnClosed(func() { close(stop) })
+	d.Resize(fyne.NewSize(900, 600))
+	d.Show()
+}
+
+// tableCell draws one table: number, status and, when occupied, time
+// since opening and running total. The color follows the status.
+func tableCell(s pos.TableState, onTap func()) fyne.CanvasObject {
+	btn := widget.NewButton("", onTap)
+	switch s.Status {
+	case pos.TableOcupada:
+		btn.Importance = widget.HighImportance
+	case pos.TableConta:
+		btn.Importance = widget.WarningImportance
+	}
+
+	lines := []fyne.CanvasObject{
+		widget.NewLabelWithStyle(fmt.Sprintf("Mesa %d", s.Table.Number), fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle(string(s.Status), fyne.TextAlignCenter, fyne.TextStyle{}),
+	}
+	if len(s.Orders) > 0 {
+		lines = append(lines, widget.NewLabelWithStyle(
+			fmt.Sprintf("%s - %s", formatElapsed(s.Elapsed(time.Now())), pos.FormatBRL(s.Total)),
+			fyne.TextAlignCenter, fyne.TextStyle{}))
+	}
+	return container.NewStack(btn, container.NewVBox(lines...))
+}
+
+// formatElapsed renders a duration as "35min" or "1h05".
+func formatElapsed(d time.Duration) string {
+	minutes := int(d.Minutes())
+	if minutes < 60 {
+		return fmt.Sprintf("%dmin", minutes)
+	}
+	return fmt.Sprintf("%dh%02d", minutes/60, minutes%60)
+}
+
+func (a *App) showTableActions(s pos.TableState, closeMap, refresh func()) {
+	title := fmt.Sprintf("Mesa %d", s.Table.Number)
+	if s.Status == pos.TableLivre {
+		dialog.ShowConfirm(title, fmt.Sprintf("Abrir a mesa %d?", s.Table.Number), func(ok bool) {
+			if !ok {
+				return
+			}
+			o := a.createOrder()
+			o.Table = strconv.Itoa(s.Table.Number)
+			a.switchToOrder(o)
+			closeMap()
+		}, a.mainWindow)
+		return
+	}
+
+	var d *dialog.CustomDialog
+	content := container.NewVBox()
+	for _, o := range s.Orders {
+		order := o
+		content.Add(widget.NewButton(
+			fmt.Sprintf("Abrir %s - %s", order.Label(), pos.FormatBRL(order.Total())), func() {
+				d.Hide()
+				closeMap()
+				a.switchToOrder(order)
+			}))
+	}
+	content.Add(widget.NewSeparator())
+
+	billLabel := "Pedir Conta"
+	if s.Status == pos.TableConta {
+		billLabel = "Reabrir Mesa"
+	}
+	content.Add(widget.NewButton(billLabel, func() {
+		d.Hide()
+		for _, o := range s.Orders {
+			o.BillRequested = s.Status != pos.TableConta
+			a.persistOpenOrder(o)
+		}
+		refresh()
+	}))
+	content.Add(widget.NewButton("Transferir para...", func() {
+		d.Hide()
+		a.chooseTable("Transferir "+title, s, false, func(target pos.TableState) {
+			a.transferTable(s, target, refresh)
+		})
+	}))
+	content.Add(widget.NewButton("Juntar com...", func() {
+		d.Hide()
+		a.chooseTable("Juntar "+title, s, true, func(target pos.TableState) {
+			a.mergeTables(s, target)
+			refresh()
+		})
+	}))
+
+	d = dialog.NewCustom(title, "Fechar", content, a.mainWindow)
+	d.Show()
+}
+
+// chooseTable asks for a target table other than from; occupiedOnly limits
+// the choice to tables with orders.
+func (a *App) chooseTable(title string, from pos.TableState, occupiedOnly bool, onChosen func(pos.TableState)) {
+	var targets []pos.TableState
+	var labels []string
+	for _, s := range pos.TableStates(a.config.Tables, a.openOrders) {
+		if s.Table.Number == from.Table.Number || (occupiedOnly && s.Status == pos.TableLivre) {
+			continue
+		}
+		targets = append(targets, s)
+		labels = append(labels, fmt.Sprintf("Mesa %d (%s) - %s", s.Table.Number, s.Table.Area, s.Status))
+	}
+	if len(targets) == 0 {
+		dialog.ShowInformation(title, "Nenhuma mesa disponivel.", a.mainWindow)
+		return
+	}
+
+	tableSelect := widget.NewSelect(labels, nil)
+	items := []*widget.FormItem{widget.NewFormItem("Mesa", tableSelect)}
+	dialog.ShowForm(title, "Confirmar", "Cancelar", items, func(ok bool) {
+		if !ok || tableSelect.SelectedIndex() < 0 {
+			return
+		}
+		onChosen(targets[tableSelect.SelectedIndex()])
+	}, a.mainWindow)
+}
+
+// transferTable moves the orders of one table to another. Moving onto an
+// occupied table joins the two instead, after confirmation.
+func (a *App) transferTable(from, to pos.TableState, refresh func()) {
+	if to.Status != pos.TableLivre {
+		msg := fmt.Sprintf("A mesa %d esta ocupada. Juntar os pedidos da mesa %d com ela?", to.Table.Number, from.Table.Number)
+		dialog.ShowConfirm("Transferir", msg, func(ok bool) {
+			if ok {
+				a.mergeTables(from, to)
+				refresh()
+			}
+		}, a.mainWindow)
+		return
+	}
+	for _, o := range from.Orders {
+		o.Table = strconv.Itoa(to.Table.Number)
+		a.persistOpenOrder(o)
+	}
+	refresh()
+}
+
+// mergeTables joins every order of from into the first order of into,
+// dropping the emptied orders. Tables with sub-bills already paid are not
+// joined, as those payments belong to their own order.
+func (a *App) mergeTables(from, into pos.TableState) {
+	target := into.Orders[0]
+	for _, o := range append([]*pos.Order{target}, from.Orders...) {
+		if o.HasPaidSubBills() {
+			dialog.ShowInformation("Juntar Mesas",
+				fmt.Sprintf("%s tem contas pagas. Pague as contas restantes antes de juntar as mesas.", o.Label()),
+				a.mainWindow)
+			return
+		}
+	}
+	current := a.order
+	for _, o := range from.Orders {
+		if err := target.Merge(o); err != nil {
+			dialog.ShowError(err, a.mainWindow)
+			break
+		}
+		a.removeOpenOrder(o)
+		if err := storage.DeleteOpenOrder(o.Number); err != nil {
+			log.Printf("Erro ao remover pedido aberto #%d: %v", o.Number, err)
+		}
+		if o == current {
+			current = target
+		}
+	}
+	if current != a.order {
+		a.showOrder(current)
+		return
+	}
+	a.persistOpenOrder(target)
+}
+
+// persistOpenOrder saves an open order changed outside the order panel,
+// reloading the entries when it is the one being edited.
+func (a *App) persistOpenOrder(o *pos.Order) {
+	if o == a.order {
+		a.showOrder(o)
+		return
+	}
+	if err := storage.SaveOpenOrder(o); err != nil {
+		log.Printf("Erro ao salvar pedido aberto #%d: %v", o.Number, err)
+	}
+}